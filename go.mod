@@ -6,6 +6,7 @@ require (
 	github.com/fatih/color v1.15.0
 	github.com/mattn/go-isatty v0.0.17
 	github.com/mholt/archiver/v3 v3.5.1
+	golang.org/x/sys v0.6.0
 	gopkg.in/djherbis/times.v1 v1.3.0
 )
 
@@ -20,6 +21,5 @@ require (
 	github.com/pierrec/lz4/v4 v4.1.2 // indirect
 	github.com/ulikunitz/xz v0.5.9 // indirect
 	github.com/xi2/xz v0.0.0-20171230120015-48954b6210f8 // indirect
-	golang.org/x/sys v0.6.0 // indirect
 )
 
@@ -8,9 +8,9 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
-	"time"
 
 	"errors"
+	"file-manager/internal/config"
 	"file-manager/internal/display"
 	"file-manager/internal/fileops"
 	"file-manager/internal/i18n"
@@ -24,11 +24,13 @@ type Command struct {
 	Name        string
 	Description string
 	Execute     func(args []string) error
+	// Args — способ разбора аргументов (по умолчанию кавычки снимаются, шаблоны не раскрываются)
+	Args ArgMode
 }
 
 // App представляет основное приложение файлового менеджера
 type App struct {
-	navigator          *navigation.Navigator
+	tabs               *navigation.TabManager
 	fileOperator       *fileops.FileOperator
 	searcher           *search.Searcher
 	display            *display.Display
@@ -36,10 +38,16 @@ type App struct {
 	archiver           *fileops.Archiver
 	permissionsManager *fileops.PermissionsManager
 	bookmarkManager    *navigation.BookmarkManager
+	filterPresets      *navigation.FilterPresetManager
+	config             *config.Config
+	renameHistory      *fileops.RenameHistory
 	logger             *logger.Logger
 	commands           map[string]Command
 	isRunning          bool
-	filterOptions      *navigation.FilterOptions
+	input              *bufio.Scanner
+	// globMatches — пути, полученные раскрытием шаблонов в текущей команде; это реальные
+	// имена файлов, поэтому resolvePath не разворачивает в них ~, $VAR и @закладка
+	globMatches map[string]bool
 }
 
 // NewApp создает новый экземпляр App
@@ -54,13 +62,28 @@ func NewApp() (*App, error) {
 		return nil, fmt.Errorf("не удалось инициализировать менеджер закладок: %w", err)
 	}
 
+	filterPresets, err := navigation.NewFilterPresetManager()
+	if err != nil {
+		return nil, fmt.Errorf("не удалось инициализировать сохраненные фильтры: %w", err)
+	}
+
+	cfg, err := config.NewConfig()
+	if err != nil {
+		return nil, fmt.Errorf("не удалось загрузить настройки: %w", err)
+	}
+
+	renameHistory, err := fileops.NewRenameHistory()
+	if err != nil {
+		return nil, fmt.Errorf("не удалось загрузить историю переименований: %w", err)
+	}
+
 	log, err := logger.NewLogger()
 	if err != nil {
 		return nil, fmt.Errorf("не удалось инициализировать журнал: %w", err)
 	}
 
 	app := &App{
-		navigator:          navigator,
+		tabs:               navigation.NewTabManager(navigator),
 		fileOperator:       fileops.NewFileOperator(),
 		searcher:           search.NewSearcher(),
 		display:            display.NewDisplay(),
@@ -68,13 +91,17 @@ func NewApp() (*App, error) {
 		archiver:           fileops.NewArchiver(),
 		permissionsManager: fileops.NewPermissionsManager(),
 		bookmarkManager:    bookmarkManager,
+		filterPresets:      filterPresets,
+		config:             cfg,
+		renameHistory:      renameHistory,
 		logger:             log,
 		commands:           make(map[string]Command),
 		isRunning:          false,
-		filterOptions:      navigation.NewFilterOptions(),
+		input:              bufio.NewScanner(os.Stdin),
 	}
 
 	app.registerCommands()
+	app.applyPinnedFilter()
 	return app, nil
 }
 
@@ -88,13 +115,15 @@ func (a *App) registerCommands() {
 		},
 		"ls": {
 			Name:        "ls",
-			Description: "Показать содержимое текущей директории",
+			Description: "Показать содержимое текущей директории: ls [выражение]",
 			Execute:     a.cmdListDir,
+			Args:        ArgsRaw,
 		},
 		"cd": {
 			Name:        "cd",
-			Description: "Изменить текущую директорию: cd <путь>",
+			Description: "Изменить текущую директорию: cd <путь> | cd -",
 			Execute:     a.cmdChangeDir,
+			Args:        ArgsGlob,
 		},
 		"pwd": {
 			Name:        "pwd",
@@ -113,38 +142,94 @@ func (a *App) registerCommands() {
 		},
 		"rm": {
 			Name:        "rm",
-			Description: "Удалить файл: rm <имя>",
+			Description: "Удалить в корзину: rm [-r] [--permanent] <имя>...",
 			Execute:     a.cmdRemoveFile,
+			Args:        ArgsGlob,
 		},
 		"rmdir": {
 			Name:        "rmdir",
-			Description: "Удалить директорию: rmdir <имя>",
+			Description: "Удалить директорию в корзину: rmdir <имя>...",
 			Execute:     a.cmdRemoveDir,
+			Args:        ArgsGlob,
+		},
+		"purge": {
+			Name:        "purge",
+			Description: "Удалить безвозвратно, минуя корзину: purge <имя>...",
+			Execute:     a.cmdPurge,
+			Args:        ArgsGlob,
 		},
 		"cp": {
 			Name:        "cp",
-			Description: "Копировать файл/директорию: cp <источник> <назначение>",
+			Description: "Копировать файлы/директории: cp [-a] [--preserve=<атрибуты>] [--resume] [--verify] [--jobs=N] [--buffer=<размер>] [-n|-u|-i|-b|--backup=<стиль>|--conflict=<политика>] <источник>... <назначение>",
 			Execute:     a.cmdCopy,
+			Args:        ArgsGlob,
 		},
 		"mv": {
 			Name:        "mv",
-			Description: "Переместить/переименовать файл/директорию: mv <источник> <назначение>",
+			Description: "Переместить/переименовать файлы/директории: mv [-n|-u|-i|-b|--backup=<стиль>|--conflict=<политика>] <источник>... <назначение>",
 			Execute:     a.cmdMove,
+			Args:        ArgsGlob,
+		},
+		"sync": {
+			Name:        "sync",
+			Description: "Синхронизировать директорию: sync [-n] [--delete] [--trash] [--checksum] [--exclude=<шаблон>] <источник> <назначение>",
+			Execute:     a.cmdSync,
+		},
+		"compare": {
+			Name:        "compare",
+			Description: "Сравнить директории: compare [--shallow] [--content] [--json] [--jobs=N] <директория1> <директория2>",
+			Execute:     a.cmdCompare,
+		},
+		"hash": {
+			Name:        "hash",
+			Description: "Контрольные суммы файлов: hash [--algo=md5|sha1|sha256|sha512|crc32] [--jobs=N] <файл>... | hash --check <файл сумм>",
+			Execute:     a.cmdHash,
+			Args:        ArgsGlob,
+		},
+		"manifest": {
+			Name:        "manifest",
+			Description: "Манифест дерева директорий: manifest create [--format=mtree|json] [--jobs=N] <директория> <файл> | manifest verify <директория> <файл>",
+			Execute:     a.cmdManifest,
+		},
+		"dupes": {
+			Name:        "dupes",
+			Description: "Найти одинаковые файлы: dupes [путь] [--min-size=<размер>] [--jobs=N] [--ignore] [--trash|--hardlink|--symlink] [--keep=oldest|newest|shortest] [-n] [-y]",
+			Execute:     a.cmdDupes,
+		},
+		"rename": {
+			Name:        "rename",
+			Description: "Переименовать пакет файлов: rename [-n] [-y] ['s/шаблон/замена/флаги'] [--template=<шаблон>] [--start=N] [--case=lower|upper|title] [--ext=<расширение>] <файл>... | rename --undo",
+			Execute:     a.cmdRename,
+			Args:        ArgsGlob,
 		},
 		"find": {
 			Name:        "find",
-			Description: "Найти файлы по имени: find <шаблон>",
+			Description: "Найти файлы: find [путь] [<шаблон> | критерии filter] [--maxdepth=N] [--mindepth=N] [--prune=<шаблон>] [--follow] [--xdev] [--ignore]",
 			Execute:     a.cmdFindByName,
+			Args:        ArgsRaw,
 		},
 		"grep": {
 			Name:        "grep",
-			Description: "Найти файлы по содержимому: grep <текст>",
+			Description: "Найти файлы по содержимому: grep [--ignore] <текст>",
 			Execute:     a.cmdFindByContent,
 		},
+		"tree": {
+			Name:        "tree",
+			Description: "Показать дерево директорий: tree [путь] [--depth=N] [--all] [--dirs] [--ignore]",
+			Execute:     a.cmdTree,
+			Args:        ArgsGlob,
+		},
+		"du": {
+			Name:        "du",
+			Description: "Показать размер директорий: du [путь] [--depth=N] [--ignore]",
+			Execute:     a.cmdDiskUsage,
+			Args:        ArgsGlob,
+		},
 		"info": {
 			Name:        "info",
-			Description: "Показать информацию о файле/директории: info <имя>",
+			Description: "Показать информацию о файле/директории: info [--hash[=алгоритм]] <имя>",
 			Execute:     a.cmdFileInfo,
+			Args:        ArgsGlob,
 		},
 		"exit": {
 			Name:        "exit",
@@ -156,42 +241,59 @@ func (a *App) registerCommands() {
 			Name:        "cat",
 			Description: "Просмотр содержимого текстового файла: cat <имя> [начальная_строка] [количество_строк]",
 			Execute:     a.cmdViewFile,
+			Args:        ArgsGlob,
 		},
 		"chmod": {
 			Name:        "chmod",
-			Description: "Изменить права доступа к файлу: chmod <режим> <имя>",
+			Description: "Изменить права доступа: chmod [-R] [--files=<режим>] [--dirs=<режим>] <режим> <имя>... (режим: 755, u+x,g-w, a+X)",
 			Execute:     a.cmdChangePermissions,
+			Args:        ArgsGlob,
+		},
+		"chown": {
+			Name:        "chown",
+			Description: "Изменить владельца: chown [-R] <пользователь>[:<группа>] <имя>...",
+			Execute:     a.cmdChangeOwner,
+			Args:        ArgsGlob,
 		},
 		"archive": {
 			Name:        "archive",
-			Description: "Создать архив: archive <имя_архива> <формат> <файл1> [файл2...]",
+			Description: "Создать архив: archive [--ignore] [--include=<шаблон>] [--exclude=<шаблон>] <имя_архива> <формат> <файл1> [файл2...]",
 			Execute:     a.cmdCreateArchive,
+			Args:        ArgsGlob,
 		},
 		"extract": {
 			Name:        "extract",
 			Description: "Распаковать архив: extract <архив> <директория>",
 			Execute:     a.cmdExtractArchive,
+			Args:        ArgsGlob,
 		},
 		"list-archive": {
 			Name:        "list-archive",
 			Description: "Показать содержимое архива: list-archive <архив>",
 			Execute:     a.cmdListArchive,
+			Args:        ArgsGlob,
 		},
 		"bookmark": {
 			Name:        "bookmark",
-			Description: "Управление закладками: bookmark add <имя> [путь] | list | remove <имя> | go <имя>",
+			Description: "Управление закладками: bookmark add <имя> [путь] | list [--tag=<тег>] | remove <имя> | go <имя> | rename <имя> <новое_имя> | describe <имя> <текст> | tag <имя> <тег...> | untag <имя> <тег...> | check [--remove] | export <json|gtk> [файл] | import <json|gtk> [файл]",
 			Execute:     a.cmdManageBookmarks,
 		},
 		"filter": {
 			Name:        "filter",
-			Description: "Фильтрация файлов: filter [--ext=<расширение>] [--name=<шаблон>] [--size=<мин>-<макс>] [--date=<начало>-<конец>] [--type=<f|d|h>]",
+			Description: "Фильтрация файлов: filter [выражение] [--ext=<расширение>] [--name=<шаблон>] [--iname=<шаблон>] [--size=<мин>..<макс>] [--date=<начало>..<конец>] [--type=<f|d|h>] [--exec|--world-writable|--setuid|--setgid|--symlink|--broken-symlink|--empty] [--owner=<пользователь>] [--group=<группа>] [--newer=<файл>] [--links=<диапазон>] | filter save|use|delete <имя> | filter list | filter pin|unpin [путь]",
 			Execute:     a.cmdFilter,
+			Args:        ArgsRaw,
 		},
 		"log": {
 			Name:        "log",
 			Description: "Просмотр журнала операций: log [количество]",
 			Execute:     a.cmdViewLog,
 		},
+		"config": {
+			Name:        "config",
+			Description: "Показать или изменить настройки: config [<ключ> [<значение>]] | config reset <ключ>",
+			Execute:     a.cmdConfig,
+		},
 		"colors": {
 			Name:        "colors",
 			Description: "Включить/отключить цветной вывод",
@@ -204,36 +306,40 @@ func (a *App) registerCommands() {
 		},
 		"trash-list": {
 			Name:        "trash-list",
-			Description: "Показать содержимое корзины",
+			Description: "Показать содержимое корзины: trash-list [--path=<префикс>] [--glob=<шаблон>] [--date=<диапазон>]",
 			Execute:     a.cmdTrashList,
 		},
 		"restore": {
 			Name:        "restore",
-			Description: "Восстановить файл из корзины (Linux)",
+			Description: "Восстановить из корзины (Linux): restore [--to=<директория>] [-n|-u|-i|-b|--backup=<стиль>|--conflict=<политика>] <путь|номер|имя>...",
 			Execute:     a.cmdRestoreFromTrash,
 		},
+		"tab": {
+			Name:        "tab",
+			Description: "Управление вкладками: tab new [путь] | list | switch <номер> | close [номер]",
+			Execute:     a.cmdTab,
+		},
 	}
 }
 
 // Start запускает интерактивный режим файлового менеджера
 func (a *App) Start() {
 	a.isRunning = true
-	scanner := bufio.NewScanner(os.Stdin)
 
 	fmt.Println(i18n.T("app_started"))
 
 	for a.isRunning {
-		dir, err := a.navigator.GetCurrentDirectory()
+		dir, err := a.navigator().GetCurrentDirectory()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, i18n.T("error")+"\n", err)
 			break
 		}
-		fmt.Printf("\n%s> ", dir)
-		if !scanner.Scan() {
+		fmt.Printf("\n%s> ", a.prompt(dir))
+		if !a.input.Scan() {
 			break
 		}
 
-		input := scanner.Text()
+		input := a.input.Text()
 		if input == "" {
 			continue
 		}
@@ -262,9 +368,15 @@ func (a *App) ExecuteCommand(command string) error {
 
 // processCommand обрабатывает введенную пользователем команду
 func (a *App) processCommand(input string) error {
-	parts := strings.Split(input, " ")
-	cmdName := parts[0]
-	args := parts[1:]
+	words, err := splitCommandLine(input)
+	if err != nil {
+		fmt.Printf(i18n.T("error")+"\n", err)
+		return err
+	}
+	if len(words) == 0 {
+		return nil
+	}
+	cmdName := unquoteArg(words[0])
 
 	cmd, exists := a.commands[cmdName]
 	if !exists {
@@ -272,11 +384,17 @@ func (a *App) processCommand(input string) error {
 		fmt.Println(errMsg)
 		return errors.New(errMsg)
 	}
+	args := a.commandArgs(cmd.Args, words[1:])
 
-	err := cmd.Execute(args)
-	dir, dirErr := a.navigator.GetCurrentDirectory()
+	err = cmd.Execute(args)
+	a.applyPinnedFilter()
+	dir, dirErr := a.navigator().GetCurrentDirectory()
 	if err != nil {
 		fmt.Printf(i18n.T("error")+"\n", err)
+		var queryErr *navigation.QueryError
+		if errors.As(err, &queryErr) {
+			fmt.Println(queryErr.Caret())
+		}
 		if dirErr == nil {
 			a.logger.Error(cmdName, dir, fmt.Sprintf("Выполнение команды '%s' с аргументами %v", cmdName, args), err)
 		}
@@ -289,6 +407,68 @@ func (a *App) processCommand(input string) error {
 	return err
 }
 
+// navigator возвращает навигатор активной вкладки
+func (a *App) navigator() *navigation.Navigator {
+	return a.tabs.Active().Navigator
+}
+
+// resolvePath разрешает путь из аргумента команды относительно текущей директории активной вкладки,
+// разворачивая сокращения ~, ~user, $VAR и ссылки на закладки @имя.
+// Пути, найденные по шаблону, уже разрешены и возвращаются без изменений.
+func (a *App) resolvePath(path string) (string, error) {
+	if a.globMatches[path] {
+		return path, nil
+	}
+	dir, err := a.navigator().GetCurrentDirectory()
+	if err != nil {
+		return "", err
+	}
+	return navigation.ExpandPath(path, dir, a.bookmarkManager)
+}
+
+// shownPath возвращает аргумент в виде для вывода: путь, найденный по шаблону, показывается
+// относительно текущей директории, если находится внутри нее, остальные — как введены
+func (a *App) shownPath(arg string) string {
+	if !a.globMatches[arg] {
+		return arg
+	}
+	dir, err := a.navigator().GetCurrentDirectory()
+	if err != nil {
+		return arg
+	}
+	rel, err := filepath.Rel(dir, arg)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return arg
+	}
+	return rel
+}
+
+// ask выводит вопрос и читает ответ пользователя; при окончании ввода возвращает false
+func (a *App) ask(question string) (string, bool) {
+	fmt.Print(question + " ")
+	if !a.input.Scan() {
+		fmt.Println()
+		return "", false
+	}
+	return strings.TrimSpace(a.input.Text()), true
+}
+
+// prompt формирует приглашение командной строки
+func (a *App) prompt(dir string) string {
+	prompt := dir
+	if a.tabs.Count() > 1 {
+		prompt = fmt.Sprintf("[%d/%d] %s", a.tabs.ActiveIndex()+1, a.tabs.Count(), dir)
+	}
+	// Показываем активный фильтр, чтобы скрытые им файлы не терялись незаметно
+	if tab := a.tabs.Active(); tab.Filter.IsActive() {
+		if tab.FilterLabel != "" {
+			return fmt.Sprintf("%s (%s: %s)", prompt, i18n.T("filter_prompt"), tab.FilterLabel)
+		}
+		return fmt.Sprintf("%s (%s)", prompt, i18n.T("filter_prompt"))
+	}
+	return prompt
+}
+
 // Команды файлового менеджера
 
 func (a *App) cmdHelp(_ []string) error {
@@ -305,15 +485,15 @@ func (a *App) cmdHelp(_ []string) error {
 
 	for _, cmd := range a.commands {
 		switch cmd.Name {
-		case "ls", "cd", "pwd", "bookmark":
+		case "ls", "cd", "pwd", "bookmark", "tab":
 			categories[i18n.T("category_navigation")] = append(categories[i18n.T("category_navigation")], cmd)
-		case "mkdir", "touch", "rm", "rmdir", "cp", "mv", "chmod":
+		case "mkdir", "touch", "rm", "rmdir", "purge", "cp", "mv", "rename", "sync", "chmod", "chown":
 			categories[i18n.T("category_fileops")] = append(categories[i18n.T("category_fileops")], cmd)
-		case "find", "grep", "info", "cat":
+		case "find", "grep", "info", "cat", "tree", "du", "compare", "dupes", "hash", "manifest":
 			categories[i18n.T("category_search")] = append(categories[i18n.T("category_search")], cmd)
 		case "archive", "extract", "list-archive":
 			categories[i18n.T("category_archive")] = append(categories[i18n.T("category_archive")], cmd)
-		case "filter", "colors", "log":
+		case "filter", "colors", "log", "config":
 			categories[i18n.T("category_settings")] = append(categories[i18n.T("category_settings")], cmd)
 		default:
 			categories[i18n.T("category_other")] = append(categories[i18n.T("category_other")], cmd)
@@ -332,24 +512,39 @@ func (a *App) cmdHelp(_ []string) error {
 	return nil
 }
 
-func (a *App) cmdListDir(_ []string) error {
-	entries, err := a.navigator.ListDirectory()
+func (a *App) cmdListDir(args []string) error {
+	entries, err := a.navigator().ListDirectory()
 	if err != nil {
 		return err
 	}
 
+	// Выражение из аргументов применяется поверх активного фильтра
+	if len(args) > 0 {
+		query, err := navigation.ParseQuery(strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+		var matched []os.DirEntry
+		for _, entry := range entries {
+			if query.MatchEntry(entry) {
+				matched = append(matched, entry)
+			}
+		}
+		entries = matched
+	}
+
 	// Применяем фильтр, если он активен
-	if a.filterOptions != nil {
-		dir, dirErr := a.navigator.GetCurrentDirectory()
+	if filterOptions := a.tabs.Active().Filter; filterOptions != nil {
+		dir, dirErr := a.navigator().GetCurrentDirectory()
 		if dirErr != nil {
 			return dirErr
 		}
-		entries, err = navigation.Filter(entries, dir, a.filterOptions)
+		entries, err = navigation.Filter(entries, dir, filterOptions)
 		if err != nil {
 			return fmt.Errorf("ошибка при применении фильтра: %w", err)
 		}
 	}
-	dir, dirErr := a.navigator.GetCurrentDirectory()
+	dir, dirErr := a.navigator().GetCurrentDirectory()
 	if dirErr != nil {
 		return dirErr
 	}
@@ -372,11 +567,18 @@ func (a *App) cmdChangeDir(args []string) error {
 	if len(args) != 1 {
 		return fmt.Errorf(i18n.T("args_expected_1"), len(args))
 	}
-	return a.navigator.ChangeDirectory(args[0])
+	if args[0] == "-" {
+		return a.navigator().Back()
+	}
+	path, err := a.resolvePath(args[0])
+	if err != nil {
+		return err
+	}
+	return a.navigator().ChangeDirectory(path)
 }
 
 func (a *App) cmdPrintWorkingDir(_ []string) error {
-	dir, err := a.navigator.GetCurrentDirectory()
+	dir, err := a.navigator().GetCurrentDirectory()
 	if err != nil {
 		return err
 	}
@@ -388,11 +590,10 @@ func (a *App) cmdMakeDir(args []string) error {
 	if len(args) != 1 {
 		return fmt.Errorf(i18n.T("args_expected_1"), len(args))
 	}
-	dir, err := a.navigator.GetCurrentDirectory()
+	path, err := a.resolvePath(args[0])
 	if err != nil {
 		return err
 	}
-	path := filepath.Join(dir, args[0])
 	return a.fileOperator.CreateDirectory(path)
 }
 
@@ -400,100 +601,252 @@ func (a *App) cmdCreateFile(args []string) error {
 	if len(args) != 1 {
 		return fmt.Errorf(i18n.T("args_expected_1"), len(args))
 	}
-	dir, err := a.navigator.GetCurrentDirectory()
+	path, err := a.resolvePath(args[0])
 	if err != nil {
 		return err
 	}
-	path := filepath.Join(dir, args[0])
 	return a.fileOperator.CreateFile(path)
 }
 
+// cmdRemoveFile отправляет файлы в корзину: rm [-r] [--permanent] <имя>...
+// Директории удаляются только с -r, а --permanent удаляет безвозвратно после подтверждения.
 func (a *App) cmdRemoveFile(args []string) error {
-	if len(args) != 1 {
-		return fmt.Errorf(i18n.T("args_expected_1"), len(args))
+	var recursive, permanent bool
+	var names []string
+	for _, arg := range args {
+		switch arg {
+		case "-r", "-R", "--recursive":
+			recursive = true
+		case "--permanent":
+			permanent = true
+		default:
+			names = append(names, arg)
+		}
 	}
-	dir, err := a.navigator.GetCurrentDirectory()
-	if err != nil {
-		return err
+	if len(names) == 0 {
+		return fmt.Errorf(i18n.T("args_expected_1"), len(names))
+	}
+	// Пути проверяются до удаления, чтобы директория среди файлов не оставила команду выполненной наполовину
+	paths := make([]string, 0, len(names))
+	for _, name := range names {
+		path, err := a.resolvePath(name)
+		if err != nil {
+			return err
+		}
+		if info, err := os.Lstat(path); err == nil && info.IsDir() && !recursive {
+			return fmt.Errorf(i18n.T("rm_is_dir"), name)
+		}
+		paths = append(paths, path)
+	}
+	if permanent {
+		return a.purge(names, paths)
 	}
-	path := filepath.Join(dir, args[0])
-	return a.fileOperator.DeleteFile(path)
+	for _, path := range paths {
+		if err := a.fileOperator.DeleteFile(path); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
+// cmdRemoveDir отправляет директории вместе с содержимым в корзину: rmdir <имя>...
 func (a *App) cmdRemoveDir(args []string) error {
-	if len(args) != 1 {
+	if len(args) == 0 {
 		return fmt.Errorf(i18n.T("args_expected_1"), len(args))
 	}
-	dir, err := a.navigator.GetCurrentDirectory()
-	if err != nil {
-		return err
+	// Несколько путей появляются, например, при раскрытии шаблона
+	for _, arg := range args {
+		path, err := a.resolvePath(arg)
+		if err != nil {
+			return err
+		}
+		if err := a.fileOperator.DeleteDirectory(path); err != nil {
+			return err
+		}
 	}
-	path := filepath.Join(dir, args[0])
-	return a.fileOperator.DeleteDirectory(path)
+	return nil
 }
 
-func (a *App) cmdCopy(args []string) error {
-	if len(args) != 2 {
-		return fmt.Errorf(i18n.T("args_expected_2"), len(args))
+// cmdPurge безвозвратно удаляет файлы и директории, минуя корзину: purge <имя>...
+// То же, что rm -r --permanent.
+func (a *App) cmdPurge(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf(i18n.T("args_expected_1"), len(args))
 	}
-	dir, err := a.navigator.GetCurrentDirectory()
-	if err != nil {
-		return err
+	paths := make([]string, 0, len(args))
+	for _, arg := range args {
+		path, err := a.resolvePath(arg)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, path)
+	}
+	return a.purge(args, paths)
+}
+
+// purge показывает удаляемые записи, спрашивает подтверждение и удаляет их безвозвратно.
+// Каждое удаление записывается в журнал с уровнем WARNING.
+func (a *App) purge(names, paths []string) error {
+	for _, name := range names {
+		fmt.Println("  " + name)
+	}
+	answer, ok := a.ask(fmt.Sprintf(i18n.T("rm_purge_confirm"), len(paths)))
+	if !ok || !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+		fmt.Println(i18n.T("rm_purge_cancelled"))
+		return nil
+	}
+	for _, path := range paths {
+		if err := a.fileOperator.Purge(path); err != nil {
+			return err
+		}
+		a.logger.Warning("purge", path, "Безвозвратное удаление без корзины", nil)
 	}
-	sourcePath := filepath.Join(dir, args[0])
-	destPath := filepath.Join(dir, args[1])
+	return nil
+}
 
-	info, err := os.Stat(sourcePath)
+// cmdCopy копирует файлы и директории:
+// cp [-a] [--preserve=<атрибуты>] [--resume] [--verify] [--jobs=N] [--buffer=<размер>] [флаги конфликтов] <источник>... <назначение>
+func (a *App) cmdCopy(args []string) error {
+	conflicts := a.newConflicts()
+	options := fileops.DefaultCopyOptions
+	// Значения настроек проверены при чтении, поэтому ошибок разбора здесь нет
+	options.Workers, _ = parseJobs(a.setting("jobs"))
+	options.BufferSize, _ = parseBufferSize(a.setting("buffer"))
+	args, options, err := parseCopyArgs(args, conflicts, options)
 	if err != nil {
 		return err
 	}
+	options.Conflicts = conflicts
+	return a.transfer(args, func(source, destination string) error {
+		return a.fileOperator.Copy(source, destination, options)
+	})
+}
 
-	if info.IsDir() {
-		return a.fileOperator.CopyDirectory(sourcePath, destPath)
+// parseCopyArgs отделяет флаги cp от путей и дополняет ими options. Права доступа копируются всегда,
+// -a сохраняет все атрибуты, --preserve= — перечисленные через запятую,
+// --resume продолжает прерванное копирование, --verify сверяет контрольные суммы,
+// --jobs= и --buffer= задают число одновременно копируемых файлов и размер буфера
+func parseCopyArgs(args []string, conflicts *fileops.Conflicts, options fileops.CopyOptions) ([]string, fileops.CopyOptions, error) {
+	rest := make([]string, 0, len(args))
+	for _, arg := range args {
+		if ok, err := takeConflictFlag(arg, conflicts); ok || err != nil {
+			if err != nil {
+				return nil, options, err
+			}
+			continue
+		}
+		switch {
+		case arg == "-a" || arg == "--archive":
+			options.Preserve |= fileops.PreserveAll
+		case arg == "--resume":
+			options.Resume = true
+		case arg == "--verify":
+			options.Verify = true
+		case strings.HasPrefix(arg, "--preserve="):
+			preserve, err := fileops.ParsePreserve(strings.TrimPrefix(arg, "--preserve="))
+			if err != nil {
+				return nil, options, err
+			}
+			options.Preserve |= preserve
+		case strings.HasPrefix(arg, "--jobs="):
+			workers, err := parseJobs(strings.TrimPrefix(arg, "--jobs="))
+			if err != nil {
+				return nil, options, err
+			}
+			options.Workers = workers
+		case strings.HasPrefix(arg, "--buffer="):
+			size, err := parseBufferSize(strings.TrimPrefix(arg, "--buffer="))
+			if err != nil {
+				return nil, options, err
+			}
+			options.BufferSize = size
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			return nil, options, fmt.Errorf(i18n.T("unknown_flag"), arg)
+		default:
+			rest = append(rest, arg)
+		}
 	}
-
-	return a.fileOperator.CopyFile(sourcePath, destPath)
+	return rest, options, nil
 }
 
-func (a *App) cmdMove(args []string) error {
-	if len(args) != 2 {
-		return fmt.Errorf(i18n.T("args_expected_2"), len(args))
-	}
-	dir, err := a.navigator.GetCurrentDirectory()
+// sameFile проверяет, указывают ли оба пути на один и тот же существующий файл
+func sameFile(a, b string) bool {
+	infoA, err := os.Stat(a)
 	if err != nil {
-		return err
+		return false
 	}
-	sourcePath := filepath.Join(dir, args[0])
-	destPath := filepath.Join(dir, args[1])
+	infoB, err := os.Stat(b)
+	return err == nil && os.SameFile(infoA, infoB)
+}
 
-	return a.fileOperator.MoveFile(sourcePath, destPath)
+// cmdMove перемещает файлы и директории: mv [флаги конфликтов] <источник>... <назначение>
+func (a *App) cmdMove(args []string) error {
+	conflicts := a.newConflicts()
+	paths := make([]string, 0, len(args))
+	for _, arg := range args {
+		ok, err := takeConflictFlag(arg, conflicts)
+		if err != nil {
+			return err
+		}
+		if ok {
+			continue
+		}
+		if strings.HasPrefix(arg, "-") && len(arg) > 1 {
+			return fmt.Errorf(i18n.T("unknown_flag"), arg)
+		}
+		paths = append(paths, arg)
+	}
+	return a.transfer(paths, func(source, destination string) error {
+		return a.fileOperator.Move(source, destination, conflicts)
+	})
 }
 
-func (a *App) cmdFindByName(args []string) error {
-	if len(args) != 1 {
-		return fmt.Errorf(i18n.T("args_expected_1"), len(args))
+// transfer выполняет op для каждого источника из args (последний аргумент — назначение).
+// Если назначение — существующая директория, источники помещаются в нее под своими именами;
+// несколько источников можно указать только с директорией назначения.
+func (a *App) transfer(args []string, op func(source, destination string) error) error {
+	if len(args) < 2 {
+		return fmt.Errorf(i18n.T("args_expected_2"), len(args))
 	}
-	dir, err := a.navigator.GetCurrentDirectory()
+	destPath, err := a.resolvePath(args[len(args)-1])
 	if err != nil {
 		return err
 	}
-	results, err := a.searcher.SearchByName(dir, args[0])
-	if err != nil {
-		return err
+	info, err := os.Stat(destPath)
+	intoDir := err == nil && info.IsDir()
+	if len(args) > 2 && !intoDir {
+		return fmt.Errorf(i18n.T("dest_not_dir"), destPath)
+	}
+
+	for _, arg := range args[:len(args)-1] {
+		sourcePath, err := a.resolvePath(arg)
+		if err != nil {
+			return err
+		}
+		target := destPath
+		if intoDir {
+			target = filepath.Join(destPath, filepath.Base(sourcePath))
+		}
+		if sameFile(sourcePath, target) {
+			return fmt.Errorf(i18n.T("same_file"), sourcePath, target)
+		}
+		if err := op(sourcePath, target); err != nil {
+			return err
+		}
 	}
-	fmt.Println(a.display.FormatSearchResults(results, args[0]))
 	return nil
 }
 
 func (a *App) cmdFindByContent(args []string) error {
+	args, respectIgnore := takeFlag(args, "--ignore")
 	if len(args) != 1 {
 		return fmt.Errorf(i18n.T("args_expected_1"), len(args))
 	}
-	dir, err := a.navigator.GetCurrentDirectory()
+	dir, err := a.navigator().GetCurrentDirectory()
 	if err != nil {
 		return err
 	}
-	results, err := a.searcher.SearchByContent(dir, args[0])
+	results, err := a.searcher.WithIgnore(respectIgnore).SearchByContent(dir, args[0])
 	if err != nil {
 		return err
 	}
@@ -502,18 +855,42 @@ func (a *App) cmdFindByContent(args []string) error {
 }
 
 func (a *App) cmdFileInfo(args []string) error {
-	if len(args) != 1 {
-		return fmt.Errorf(i18n.T("args_expected_1"), len(args))
+	// --hash[=алгоритм] добавляет контрольную сумму файла
+	algorithm := ""
+	var paths []string
+	for _, arg := range args {
+		name, value, found := strings.Cut(arg, "=")
+		switch {
+		case name == "--hash" && !found:
+			algorithm = fileops.DefaultHashAlgorithm
+		case name == "--hash":
+			var err error
+			if algorithm, err = fileops.ParseHashAlgorithm(value); err != nil {
+				return err
+			}
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			return fmt.Errorf(i18n.T("unknown_flag"), arg)
+		default:
+			paths = append(paths, arg)
+		}
+	}
+	if len(paths) != 1 {
+		return fmt.Errorf(i18n.T("args_expected_1"), len(paths))
 	}
-	dir, err := a.navigator.GetCurrentDirectory()
+	path, err := a.resolvePath(paths[0])
 	if err != nil {
 		return err
 	}
-	path := filepath.Join(dir, args[0])
 	fileInfo, err := a.display.GetFileInfo(path)
 	if err != nil {
 		return err
 	}
+	if algorithm != "" && !fileInfo.IsDir {
+		if fileInfo.Hash, err = fileops.FileHash(path, algorithm); err != nil {
+			return fmt.Errorf(i18n.T("hash_open_error"), paths[0], err)
+		}
+		fileInfo.HashAlgorithm = algorithm
+	}
 	fmt.Println(a.display.FormatFileInfo(fileInfo))
 	return nil
 }
@@ -529,11 +906,10 @@ func (a *App) cmdViewFile(args []string) error {
 	if len(args) < 1 || len(args) > 3 {
 		return fmt.Errorf(i18n.T("args_expected_1_to_3"), len(args))
 	}
-	dir, err := a.navigator.GetCurrentDirectory()
+	path, err := a.resolvePath(args[0])
 	if err != nil {
 		return err
 	}
-	path := filepath.Join(dir, args[0])
 	startLine := 0
 	maxLines := 20
 	if len(args) >= 2 {
@@ -560,47 +936,134 @@ func (a *App) cmdViewFile(args []string) error {
 	return nil
 }
 
+// cmdChangePermissions изменяет права доступа:
+// chmod [-R] [--files=<режим>] [--dirs=<режим>] [<режим>] <имя>...
+// Режим — восьмеричный или символический (u+x,g-w,o=r, a+X). С --files или --dirs все
+// остальные аргументы — пути, а записи другого типа не меняются.
 func (a *App) cmdChangePermissions(args []string) error {
-	if len(args) != 2 {
-		return fmt.Errorf(i18n.T("args_expected_2"), len(args))
+	var options fileops.ChmodOptions
+	var words []string
+	for _, arg := range args {
+		name, value, _ := strings.Cut(arg, "=")
+		var err error
+		switch {
+		case arg == "-R" || arg == "--recursive":
+			options.Recursive = true
+		case name == "--files":
+			options.FileMode, err = fileops.ParseMode(value)
+		case name == "--dirs":
+			options.DirMode, err = fileops.ParseMode(value)
+		case strings.HasPrefix(arg, "-") && len(arg) > 1 && len(words) == 0 && strings.Trim(arg[1:], "rwxXst") != "":
+			// -w и подобные — символический режим, как в chmod
+			err = fmt.Errorf(i18n.T("unknown_flag"), arg)
+		default:
+			words = append(words, arg)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if options.FileMode == nil && options.DirMode == nil {
+		if len(words) < 2 {
+			return fmt.Errorf(i18n.T("args_expected_min_2"), len(words))
+		}
+		mode, err := fileops.ParseMode(words[0])
+		if err != nil {
+			return err
+		}
+		options.Mode, words = mode, words[1:]
+	}
+	if len(words) == 0 {
+		return errors.New(i18n.T("permissions_no_paths"))
+	}
+	for _, word := range words {
+		path, err := a.resolvePath(word)
+		if err != nil {
+			return err
+		}
+		if err := a.permissionsManager.ChangePermissionsTree(path, options); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cmdChangeOwner изменяет владельца: chown [-R] <пользователь>[:<группа>] <имя>...
+// Пользователь и группа задаются именами или числовыми идентификаторами.
+func (a *App) cmdChangeOwner(args []string) error {
+	recursive := false
+	var words []string
+	for _, arg := range args {
+		switch {
+		case arg == "-R" || arg == "--recursive":
+			recursive = true
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			return fmt.Errorf(i18n.T("unknown_flag"), arg)
+		default:
+			words = append(words, arg)
+		}
 	}
-	mode := args[0]
-	dir, err := a.navigator.GetCurrentDirectory()
+	if len(words) < 2 {
+		return fmt.Errorf(i18n.T("args_expected_min_2"), len(words))
+	}
+	uid, gid, err := fileops.ParseOwner(words[0])
 	if err != nil {
 		return err
 	}
-	path := filepath.Join(dir, args[1])
-	return a.permissionsManager.ChangePermissions(path, mode)
+	for _, word := range words[1:] {
+		path, err := a.resolvePath(word)
+		if err != nil {
+			return err
+		}
+		if err := a.permissionsManager.ChangeOwnerTree(path, uid, gid, recursive); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (a *App) cmdCreateArchive(args []string) error {
+	args, respectIgnore := takeFlag(args, "--ignore")
+	args, include, err := takePatterns(args, "--include")
+	if err != nil {
+		return err
+	}
+	args, exclude, err := takePatterns(args, "--exclude")
+	if err != nil {
+		return err
+	}
 	if len(args) < 3 {
 		return fmt.Errorf(i18n.T("args_expected_min_3"), len(args))
 	}
 	archiveName := args[0]
 	format := args[1]
 	sources := []string{}
-	dir, err := a.navigator.GetCurrentDirectory()
+	for _, src := range args[2:] {
+		source, err := a.resolvePath(src)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, source)
+	}
+	destination, err := a.resolvePath(archiveName)
 	if err != nil {
 		return err
 	}
-	for _, src := range args[2:] {
-		sources = append(sources, filepath.Join(dir, src))
-	}
-	destination := filepath.Join(dir, archiveName)
-	return a.archiver.ArchiveFiles(sources, destination, format)
+	return a.archiver.WithIgnore(respectIgnore).WithPatterns(include, exclude).ArchiveFiles(sources, destination, format)
 }
 
 func (a *App) cmdExtractArchive(args []string) error {
 	if len(args) != 2 {
 		return fmt.Errorf(i18n.T("args_expected_2"), len(args))
 	}
-	dir, err := a.navigator.GetCurrentDirectory()
+	source, err := a.resolvePath(args[0])
+	if err != nil {
+		return err
+	}
+	destination, err := a.resolvePath(args[1])
 	if err != nil {
 		return err
 	}
-	source := filepath.Join(dir, args[0])
-	destination := filepath.Join(dir, args[1])
 	return a.archiver.ExtractArchive(source, destination)
 }
 
@@ -608,11 +1071,10 @@ func (a *App) cmdListArchive(args []string) error {
 	if len(args) != 1 {
 		return fmt.Errorf(i18n.T("error"), fmt.Sprintf(i18n.T("archive_args"), 1, len(args)))
 	}
-	dir, err := a.navigator.GetCurrentDirectory()
+	source, err := a.resolvePath(args[0])
 	if err != nil {
 		return err
 	}
-	source := filepath.Join(dir, args[0])
 	contents, err := a.archiver.ListArchiveContents(source)
 	if err != nil {
 		return err
@@ -624,144 +1086,6 @@ func (a *App) cmdListArchive(args []string) error {
 	return nil
 }
 
-func (a *App) cmdManageBookmarks(args []string) error {
-	if len(args) < 1 {
-		return fmt.Errorf(i18n.T("error"), i18n.T("bookmark_args"))
-	}
-	dir, err := a.navigator.GetCurrentDirectory()
-	if err != nil {
-		return err
-	}
-	switch args[0] {
-	case "add":
-		if len(args) < 2 {
-			return fmt.Errorf(i18n.T("error"), i18n.T("bookmark_add_args"))
-		}
-		name := args[1]
-		path := dir
-		if len(args) >= 3 {
-			path = args[2]
-			if !filepath.IsAbs(path) {
-				path = filepath.Join(dir, path)
-			}
-		}
-		return a.bookmarkManager.AddBookmark(name, path)
-	case "list":
-		bookmarks := a.bookmarkManager.ListBookmarks()
-		fmt.Println(i18n.T("bookmark_list"))
-		for i, bookmark := range bookmarks {
-			fmt.Printf("%d. %s -> %s\n", i+1, bookmark.Name, bookmark.Path)
-		}
-		return nil
-	case "remove":
-		if len(args) < 2 {
-			return fmt.Errorf(i18n.T("error"), i18n.T("bookmark_remove_args"))
-		}
-		return a.bookmarkManager.RemoveBookmark(args[1])
-	case "go":
-		if len(args) < 2 {
-			return fmt.Errorf(i18n.T("error"), i18n.T("bookmark_go_args"))
-		}
-		path, err := a.bookmarkManager.GetBookmarkPath(args[1])
-		if err != nil {
-			return err
-		}
-		return a.navigator.ChangeDirectory(path)
-	default:
-		return fmt.Errorf(i18n.T("error"), fmt.Sprintf(i18n.T("bookmark_unknown"), args[0]))
-	}
-}
-
-func (a *App) cmdFilter(args []string) error {
-	if len(args) == 0 {
-		a.filterOptions = navigation.NewFilterOptions()
-		fmt.Println(i18n.T("filter_reset"))
-		return nil
-	}
-	newOptions := navigation.NewFilterOptions()
-	for _, arg := range args {
-		if strings.HasPrefix(arg, "--ext=") {
-			ext := strings.TrimPrefix(arg, "--ext=")
-			if ext != "" {
-				extensions := strings.Split(ext, ",")
-				newOptions.Extensions = extensions
-			}
-		} else if strings.HasPrefix(arg, "--name=") {
-			pattern := strings.TrimPrefix(arg, "--name=")
-			newOptions.NamePattern = pattern
-		} else if strings.HasPrefix(arg, "--size=") {
-			sizeRange := strings.TrimPrefix(arg, "--size=")
-			parts := strings.Split(sizeRange, "-")
-
-			if len(parts) == 2 {
-				// Минимальный размер
-				if parts[0] != "" {
-					minSize, err := strconv.ParseInt(parts[0], 10, 64)
-					if err != nil {
-						return fmt.Errorf("некорректный минимальный размер: %w", err)
-					}
-					newOptions.MinSize = minSize
-				}
-
-				// Максимальный размер
-				if parts[1] != "" {
-					maxSize, err := strconv.ParseInt(parts[1], 10, 64)
-					if err != nil {
-						return fmt.Errorf("некорректный максимальный размер: %w", err)
-					}
-					newOptions.MaxSize = maxSize
-				}
-			}
-		} else if strings.HasPrefix(arg, "--date=") {
-			dateRange := strings.TrimPrefix(arg, "--date=")
-			parts := strings.Split(dateRange, "-")
-
-			if len(parts) == 2 {
-				// Дата начала
-				if parts[0] != "" {
-					startDate, err := time.Parse("2006-01-02", parts[0])
-					if err != nil {
-						return fmt.Errorf("некорректная дата начала (формат YYYY-MM-DD): %w", err)
-					}
-					newOptions.ModifiedAfter = startDate
-				}
-
-				// Дата окончания
-				if parts[1] != "" {
-					endDate, err := time.Parse("2006-01-02", parts[1])
-					if err != nil {
-						return fmt.Errorf("некорректная дата окончания (формат YYYY-MM-DD): %w", err)
-					}
-					// Устанавливаем конец дня
-					endDate = endDate.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
-					newOptions.ModifiedBefore = endDate
-				}
-			}
-		} else if strings.HasPrefix(arg, "--type=") {
-			types := strings.TrimPrefix(arg, "--type=")
-
-			// По умолчанию ничего не показываем
-			newOptions.ShowDirs = false
-			newOptions.ShowFiles = false
-			newOptions.ShowHidden = false
-
-			for _, t := range types {
-				switch t {
-				case 'd':
-					newOptions.ShowDirs = true
-				case 'f':
-					newOptions.ShowFiles = true
-				case 'h':
-					newOptions.ShowHidden = true
-				}
-			}
-		}
-	}
-	a.filterOptions = newOptions
-	fmt.Println(i18n.T("filter_applied"))
-	return a.cmdListDir([]string{})
-}
-
 func (a *App) cmdViewLog(args []string) error {
 	maxEntries := 10
 
@@ -803,31 +1127,3 @@ func (a *App) cmdEmptyTrash(_ []string) error {
 	fmt.Println(i18n.T("trash_empty"))
 	return nil
 }
-
-func (a *App) cmdTrashList(_ []string) error {
-	files, err := a.fileOperator.SoftDeleter.ListTrash()
-	if err != nil {
-		return fmt.Errorf(i18n.T("error"), err)
-	}
-	if len(files) == 0 {
-		fmt.Println(i18n.T("trash_empty_already"))
-		return nil
-	}
-	fmt.Println(i18n.T("trash_contents"))
-	for i, f := range files {
-		fmt.Printf("%d. %s\n", i+1, f)
-	}
-	return nil
-}
-
-func (a *App) cmdRestoreFromTrash(args []string) error {
-	if len(args) != 1 {
-		return fmt.Errorf(i18n.T("args_expected_1"), len(args))
-	}
-	err := a.fileOperator.SoftDeleter.RestoreFromTrash(args[0])
-	if err != nil {
-		return fmt.Errorf(i18n.T("error"), err)
-	}
-	fmt.Println(i18n.T("file_restored"))
-	return nil
-}
@@ -1,11 +1,24 @@
 package app
 
 import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
+
+	"file-manager/internal/config"
+	"file-manager/internal/fileops"
+	"file-manager/internal/navigation"
+	"file-manager/internal/sysinfo"
 )
 
 // TestApp проверяет основные функции приложения
@@ -174,6 +187,44 @@ func TestApp(t *testing.T) {
 		}
 	})
 
+	// Тест на проверку недействительных закладок с интерактивным ответом
+	t.Run("BookmarkCheckCommand", func(t *testing.T) {
+		savedManager := app.bookmarkManager
+		savedInput := app.input
+		defer func() {
+			app.bookmarkManager = savedManager
+			app.input = savedInput
+		}()
+		app.bookmarkManager = &navigation.BookmarkManager{BookmarksFile: filepath.Join(tempDir, "bookmarks.json")}
+
+		staleDir := filepath.Join(tempDir, "stale")
+		if err := os.Mkdir(staleDir, 0755); err != nil {
+			t.Fatalf("не удалось создать директорию: %v", err)
+		}
+		if err := app.cmdManageBookmarks([]string{"add", "stale", staleDir}); err != nil {
+			t.Fatalf("не удалось добавить закладку: %v", err)
+		}
+		if err := app.cmdManageBookmarks([]string{"tag", "stale", "tmp"}); err != nil {
+			t.Fatalf("не удалось добавить тег: %v", err)
+		}
+		if err := os.Remove(staleDir); err != nil {
+			t.Fatalf("не удалось удалить директорию: %v", err)
+		}
+
+		app.input = bufio.NewScanner(strings.NewReader("r\n"))
+		output := captureOutput(func() {
+			if err := app.cmdManageBookmarks([]string{"check"}); err != nil {
+				t.Errorf("ошибка при проверке закладок: %v", err)
+			}
+		})
+		if !strings.Contains(output, "stale") {
+			t.Errorf("недействительная закладка не показана: %s", output)
+		}
+		if len(app.bookmarkManager.ListBookmarks()) != 0 {
+			t.Error("недействительная закладка не была удалена")
+		}
+	})
+
 	// Тест на переключение цветов
 	t.Run("ToggleColors", func(t *testing.T) {
 		// Запоминаем начальное состояние
@@ -263,6 +314,207 @@ func TestApp(t *testing.T) {
 		if err := app.cmdChangePermissions([]string{"0644"}); err == nil {
 			t.Error("ожидалась ошибка при недостаточном количестве аргументов")
 		}
+		if err := app.processCommand("chmod u+x,go-r " + fileName); err != nil {
+			t.Errorf("ошибка символического режима: %v", err)
+		}
+		if info, _ := os.Stat(filePath); info.Mode().Perm() != 0700 {
+			t.Errorf("неверные права после u+x,go-r: %v", info.Mode().Perm())
+		}
+
+		treeDir := filepath.Join(tempDir, "chmod_tree")
+		if err := os.MkdirAll(filepath.Join(treeDir, "sub"), 0700); err != nil {
+			t.Fatalf("не удалось создать директорию: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(treeDir, "sub", "f.txt"), nil, 0600); err != nil {
+			t.Fatalf("не удалось создать файл: %v", err)
+		}
+		if err := app.processCommand("chmod -R --files=644 --dirs=755 chmod_tree"); err != nil {
+			t.Errorf("ошибка chmod -R: %v", err)
+		}
+		dirInfo, _ := os.Stat(filepath.Join(treeDir, "sub"))
+		fileInfo, _ := os.Stat(filepath.Join(treeDir, "sub", "f.txt"))
+		if dirInfo.Mode().Perm() != 0755 || fileInfo.Mode().Perm() != 0644 {
+			t.Errorf("неверные права: директория %v, файл %v", dirInfo.Mode().Perm(), fileInfo.Mode().Perm())
+		}
+		if err := app.processCommand("chmod u+q " + fileName); err == nil {
+			t.Error("ожидалась ошибка для неверного режима")
+		}
+	})
+
+	t.Run("RemoveCommands", func(t *testing.T) {
+		// Корзина создается во временном домашнем каталоге
+		t.Setenv("HOME", filepath.Join(tempDir, "rm_home"))
+		savedInput := app.input
+		defer func() { app.input = savedInput }()
+		rmDir := filepath.Join(tempDir, "rm_test")
+		for _, name := range []string{"keep/a.txt", "trash_me/b.txt", "purge_me/c.txt", "gone/d.txt"} {
+			path := filepath.Join(rmDir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatalf("не удалось создать директорию: %v", err)
+			}
+			if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+				t.Fatalf("не удалось создать файл: %v", err)
+			}
+		}
+		if err := app.cmdChangeDir([]string{rmDir}); err != nil {
+			t.Fatalf("ошибка при смене директории: %v", err)
+		}
+		trashed := func(name string) bool {
+			_, err := os.Stat(filepath.Join(tempDir, "rm_home", ".local", "share", "Trash", "files", name))
+			return err == nil
+		}
+
+		if err := app.processCommand("rm keep"); err == nil {
+			t.Error("ожидалась ошибка при удалении директории без -r")
+		}
+		if err := app.processCommand("rm -r trash_me"); err != nil {
+			t.Fatalf("ошибка rm -r: %v", err)
+		}
+		if err := app.processCommand("rmdir keep"); err != nil {
+			t.Fatalf("ошибка rmdir: %v", err)
+		}
+		if !trashed("trash_me") || !trashed("keep") {
+			t.Error("директории не попали в корзину")
+		}
+
+		app.input = bufio.NewScanner(strings.NewReader("n\n"))
+		output := captureOutput(func() {
+			if err := app.processCommand("rm -r --permanent purge_me"); err != nil {
+				t.Errorf("ошибка rm --permanent: %v", err)
+			}
+		})
+		if !strings.Contains(output, "purge_me") {
+			t.Errorf("удаляемая запись не показана перед подтверждением: %s", output)
+		}
+		if _, err := os.Stat(filepath.Join(rmDir, "purge_me")); err != nil {
+			t.Error("директория удалена без подтверждения")
+		}
+
+		app.input = bufio.NewScanner(strings.NewReader("y\ny\n"))
+		_ = captureOutput(func() {
+			if err := app.processCommand("rm -r --permanent purge_me"); err != nil {
+				t.Errorf("ошибка rm --permanent: %v", err)
+			}
+			if err := app.processCommand("purge gone"); err != nil {
+				t.Errorf("ошибка purge: %v", err)
+			}
+		})
+		for _, name := range []string{"purge_me", "gone"} {
+			if _, err := os.Stat(filepath.Join(rmDir, name)); !os.IsNotExist(err) {
+				t.Errorf("%s не удалена", name)
+			}
+			if trashed(name) {
+				t.Errorf("%s попала в корзину при безвозвратном удалении", name)
+			}
+		}
+	})
+
+	t.Run("TrashCommands", func(t *testing.T) {
+		if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+			t.Skip("восстановление поддерживается только корзиной FreeDesktop")
+		}
+		t.Setenv("XDG_DATA_HOME", filepath.Join(tempDir, "trash_data"))
+		workDir := filepath.Join(tempDir, "trash_work")
+		for _, name := range []string{"notes.txt", "app.log", "logs/old.log"} {
+			path := filepath.Join(workDir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatalf("не удалось создать директорию: %v", err)
+			}
+			if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+				t.Fatalf("не удалось создать файл: %v", err)
+			}
+		}
+		if err := app.cmdChangeDir([]string{workDir}); err != nil {
+			t.Fatalf("ошибка при смене директории: %v", err)
+		}
+		if err := app.processCommand("rm -r notes.txt app.log logs"); err != nil {
+			t.Fatalf("ошибка удаления: %v", err)
+		}
+
+		output := captureOutput(func() {
+			if err := app.processCommand("trash-list"); err != nil {
+				t.Errorf("ошибка trash-list: %v", err)
+			}
+		})
+		for _, name := range []string{"notes.txt", "app.log", "logs" + string(filepath.Separator)} {
+			if !strings.Contains(output, filepath.Join(workDir, name)) {
+				t.Errorf("в списке нет исходного пути %s:\n%s", name, output)
+			}
+		}
+		output = captureOutput(func() {
+			if err := app.processCommand("trash-list --glob=*.log --path=" + workDir + " --date=1d"); err != nil {
+				t.Errorf("ошибка trash-list: %v", err)
+			}
+		})
+		if !strings.Contains(output, "app.log") || strings.Contains(output, "notes.txt") {
+			t.Errorf("фильтр по шаблону не применен:\n%s", output)
+		}
+		if err := app.processCommand("trash-list --bad"); err == nil {
+			t.Error("ожидалась ошибка для неизвестного флага")
+		}
+
+		// Номер из полного списка, занятое место получает свободное имя
+		if err := os.WriteFile(filepath.Join(workDir, "notes.txt"), []byte("new"), 0644); err != nil {
+			t.Fatalf("не удалось создать файл: %v", err)
+		}
+		entries, err := app.fileOperator.SoftDeleter.ListTrash()
+		if err != nil {
+			t.Fatalf("ошибка чтения корзины: %v", err)
+		}
+		number := 0
+		for i, entry := range entries {
+			if entry.Name == "notes.txt" {
+				number = i + 1
+			}
+		}
+		if err := app.processCommand("restore " + strconv.Itoa(number)); err != nil {
+			t.Fatalf("ошибка восстановления по номеру: %v", err)
+		}
+		if content, err := os.ReadFile(filepath.Join(workDir, "notes (1).txt")); err != nil || string(content) != "notes.txt" {
+			t.Errorf("запись должна восстановиться под свободным именем: %v", err)
+		}
+
+		// Исходный путь и восстановление в другую директорию
+		if err := app.processCommand("restore logs"); err != nil {
+			t.Fatalf("ошибка восстановления по пути: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(workDir, "logs", "old.log")); err != nil {
+			t.Errorf("директория не восстановлена: %v", err)
+		}
+		if err := os.Mkdir(filepath.Join(workDir, "restored"), 0755); err != nil {
+			t.Fatalf("не удалось создать директорию: %v", err)
+		}
+		if err := app.processCommand("restore --to restored app.log"); err != nil {
+			t.Fatalf("ошибка восстановления в директорию: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(workDir, "restored", "app.log")); err != nil {
+			t.Errorf("файл не восстановлен в указанную директорию: %v", err)
+		}
+		if err := app.processCommand("restore app.log"); err == nil {
+			t.Error("ожидалась ошибка для записи, которой нет в корзине")
+		}
+	})
+
+	t.Run("ChangeOwnerCommand", func(t *testing.T) {
+		if err := app.cmdChangeDir([]string{tempDir}); err != nil {
+			t.Fatalf("ошибка при смене директории: %v", err)
+		}
+		if err := app.processCommand("chown no-such-user-fm perm_test.txt"); err == nil {
+			t.Error("ожидалась ошибка для неизвестного пользователя")
+		}
+		if err := app.processCommand("chown root"); err == nil {
+			t.Error("ожидалась ошибка: не указан файл")
+		}
+		if os.Geteuid() != 0 {
+			t.Skip("смена владельца требует прав root")
+		}
+		if err := app.processCommand("chown -R 4321:4321 chmod_tree"); err != nil {
+			t.Fatalf("ошибка chown -R: %v", err)
+		}
+		info, _ := os.Stat(filepath.Join(tempDir, "chmod_tree", "sub", "f.txt"))
+		if st, ok := sysinfo.Stat(info); !ok || st.UID != 4321 || st.GID != 4321 {
+			t.Errorf("владелец должен измениться рекурсивно: %+v", st)
+		}
 	})
 
 	// Тест на архивирование и распаковку (archive, extract, list-archive)
@@ -306,17 +558,935 @@ func TestApp(t *testing.T) {
 		}
 	})
 
+	// Тест на разрешение путей с сокращениями в аргументах команд
+	t.Run("PathShorthands", func(t *testing.T) {
+		srcDir := filepath.Join(tempDir, "shorthand_src")
+		if err := os.Mkdir(srcDir, 0755); err != nil {
+			t.Fatalf("не удалось создать директорию: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(srcDir, "a.txt"), []byte("a"), 0644); err != nil {
+			t.Fatalf("не удалось создать файл: %v", err)
+		}
+		if err := app.bookmarkManager.AddBookmark("shorthand", srcDir); err != nil {
+			t.Fatalf("не удалось добавить закладку: %v", err)
+		}
+		defer func() { _ = app.bookmarkManager.RemoveBookmark("shorthand") }()
+
+		if err := app.cmdChangeDir([]string{tempDir}); err != nil {
+			t.Fatalf("ошибка при смене директории: %v", err)
+		}
+
+		// Абсолютный путь назначения не должен склеиваться с текущей директорией
+		destAbs := filepath.Join(tempDir, "shorthand_abs.txt")
+		if err := app.cmdCopy([]string{"@shorthand/a.txt", destAbs}); err != nil {
+			t.Fatalf("ошибка при копировании: %v", err)
+		}
+		if _, err := os.Stat(destAbs); err != nil {
+			t.Errorf("файл не скопирован по абсолютному пути: %v", err)
+		}
+
+		t.Setenv("FM_APP_TEST_DIR", srcDir)
+		if err := app.cmdChangeDir([]string{"$FM_APP_TEST_DIR"}); err != nil {
+			t.Fatalf("ошибка при переходе по переменной окружения: %v", err)
+		}
+		if app.navigator().CurrentDir != srcDir {
+			t.Errorf("неверная текущая директория: %s", app.navigator().CurrentDir)
+		}
+	})
+
+	// Тест на работу с вкладками (tab)
+	t.Run("TabCommands", func(t *testing.T) {
+		if err := app.cmdChangeDir([]string{tempDir}); err != nil {
+			t.Fatalf("ошибка при смене директории: %v", err)
+		}
+		if err := app.cmdTab([]string{"new", "test_dir"}); err != nil {
+			t.Fatalf("ошибка при открытии вкладки: %v", err)
+		}
+		dir, err := app.navigator().GetCurrentDirectory()
+		if err != nil {
+			t.Fatalf("ошибка при получении директории: %v", err)
+		}
+		if dir != filepath.Join(tempDir, "test_dir") {
+			t.Errorf("неверная директория новой вкладки: %s", dir)
+		}
+
+		if err := app.cmdTab([]string{"switch", "1"}); err != nil {
+			t.Errorf("ошибка при переключении вкладки: %v", err)
+		}
+		if app.navigator().CurrentDir != tempDir {
+			t.Errorf("неверная директория первой вкладки: %s", app.navigator().CurrentDir)
+		}
+
+		if err := app.cmdTab([]string{"close", "2"}); err != nil {
+			t.Errorf("ошибка при закрытии вкладки: %v", err)
+		}
+		if err := app.cmdTab([]string{"close"}); err == nil {
+			t.Error("ожидалась ошибка при закрытии последней вкладки")
+		}
+	})
+
 	// Тест на фильтрацию (filter)
 	t.Run("FilterCommand", func(t *testing.T) {
 		err := app.cmdFilter([]string{"--ext=txt"})
 		if err != nil {
 			t.Errorf("ошибка при применении фильтра: %v", err)
 		}
+		err = app.cmdFilter([]string{"--size=>1.5K", "--date=2024-01-01..2024-02-01"})
+		if err != nil {
+			t.Errorf("ошибка при применении фильтра по размеру и дате: %v", err)
+		}
+		options := app.tabs.Active().Filter
+		if options.MinSize != 1536+1 || options.MaxSize != -1 {
+			t.Errorf("неверный диапазон размеров: %d..%d", options.MinSize, options.MaxSize)
+		}
+		if options.ModifiedAfter.Format("2006-01-02") != "2024-01-01" || options.ModifiedBefore.Format("2006-01-02") != "2024-02-01" {
+			t.Errorf("неверный диапазон дат: %v..%v", options.ModifiedAfter, options.ModifiedBefore)
+		}
+		if err := app.cmdFilter([]string{"--date=2024-02-01..2024-01-01"}); err == nil {
+			t.Error("ожидалась ошибка для перевернутого диапазона дат")
+		}
+		if err := app.cmdFilter([]string{"--size=10X"}); err == nil {
+			t.Error("ожидалась ошибка для некорректного размера")
+		}
+		err = app.cmdFilter([]string{"--exec", "--empty", "--links=2..", "--newer=" + tempDir})
+		if err != nil {
+			t.Errorf("ошибка при применении фильтра по метаданным: %v", err)
+		}
+		options = app.tabs.Active().Filter
+		if !options.Executable || !options.Empty || options.MinLinks != 2 || options.NewerThan != tempDir {
+			t.Errorf("критерии по метаданным разобраны неверно: %s", options)
+		}
+		for _, bad := range []string{"--bogus", "--exec=yes", "--owner=", "--owner=no-such-user-for-filter-test", "--links=1.5", "--newer=no-such-file"} {
+			if err := app.cmdFilter([]string{bad}); err == nil {
+				t.Errorf("ожидалась ошибка для флага %s", bad)
+			}
+		}
 		err = app.cmdFilter([]string{})
 		if err != nil {
 			t.Errorf("ошибка при сбросе фильтра: %v", err)
 		}
 	})
+
+	t.Run("FilterPresets", func(t *testing.T) {
+		savedPresets := app.filterPresets
+		defer func() { app.filterPresets = savedPresets }()
+		app.filterPresets = &navigation.FilterPresetManager{FiltersFile: filepath.Join(tempDir, "filters.json")}
+
+		repo := filepath.Join(tempDir, "repo")
+		if err := os.MkdirAll(filepath.Join(repo, "pkg"), 0755); err != nil {
+			t.Fatalf("не удалось создать директорию: %v", err)
+		}
+		if err := app.cmdChangeDir([]string{tempDir}); err != nil {
+			t.Fatalf("не удалось изменить директорию: %v", err)
+		}
+
+		if err := app.cmdFilter([]string{"save", "txt"}); err == nil {
+			t.Error("ожидалась ошибка при сохранении неактивного фильтра")
+		}
+		captureOutput(func() {
+			if err := app.cmdFilter([]string{"--ext=txt"}); err != nil {
+				t.Errorf("ошибка при применении фильтра: %v", err)
+			}
+			if err := app.cmdFilter([]string{"save", "txt"}); err != nil {
+				t.Errorf("ошибка при сохранении фильтра: %v", err)
+			}
+		})
+		if !strings.HasSuffix(app.prompt(tempDir), ": txt)") {
+			t.Errorf("приглашение должно показывать активный фильтр: %q", app.prompt(tempDir))
+		}
+
+		_ = app.cmdFilter([]string{})
+		if app.prompt(tempDir) != tempDir {
+			t.Errorf("приглашение без фильтра не должно меняться: %q", app.prompt(tempDir))
+		}
+		captureOutput(func() {
+			if err := app.cmdFilter([]string{"use", "txt"}); err != nil {
+				t.Errorf("ошибка при применении сохраненного фильтра: %v", err)
+			}
+		})
+		if got := app.tabs.Active().Filter.Extensions; len(got) != 1 || got[0] != "txt" {
+			t.Errorf("сохраненный фильтр применен неверно: %v", got)
+		}
+
+		// Закрепленный фильтр применяется при входе в дерево и снимается при выходе
+		captureOutput(func() {
+			if err := app.processCommand("filter pin " + repo); err != nil {
+				t.Errorf("ошибка при закреплении фильтра: %v", err)
+			}
+			_ = app.processCommand("filter")
+			_ = app.processCommand("cd " + filepath.Join(repo, "pkg"))
+		})
+		if !app.tabs.Active().Filter.IsActive() {
+			t.Error("при переходе в закрепленное дерево фильтр должен примениться")
+		}
+		captureOutput(func() { _ = app.processCommand("cd " + tempDir) })
+		if app.tabs.Active().Filter.IsActive() {
+			t.Error("при выходе из закрепленного дерева фильтр должен сниматься")
+		}
+
+		if err := app.cmdFilter([]string{"unpin", repo}); err != nil {
+			t.Errorf("ошибка при снятии закрепления: %v", err)
+		}
+		if err := app.cmdFilter([]string{"delete", "txt"}); err != nil {
+			t.Errorf("ошибка при удалении фильтра: %v", err)
+		}
+	})
+
+	t.Run("FindCommand", func(t *testing.T) {
+		findDir := filepath.Join(tempDir, "find")
+		for _, name := range []string{"a/b/deep.go", "a/top.go", "a/.hidden.go", "vendor/lib.go", "notes.md"} {
+			path := filepath.Join(findDir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatalf("не удалось создать директорию: %v", err)
+			}
+			if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+				t.Fatalf("не удалось создать файл %s: %v", name, err)
+			}
+		}
+		if err := app.cmdChangeDir([]string{tempDir}); err != nil {
+			t.Fatalf("не удалось изменить директорию: %v", err)
+		}
+
+		output := captureOutput(func() {
+			if err := app.cmdFindByName([]string{findDir, "--ext=go", "--prune=vendor", "--maxdepth=2"}); err != nil {
+				t.Errorf("ошибка при выполнении find: %v", err)
+			}
+		})
+		for _, name := range []string{"top.go", ".hidden.go"} {
+			if !strings.Contains(output, name) {
+				t.Errorf("find должен найти %s:\n%s", name, output)
+			}
+		}
+		for _, name := range []string{"deep.go", "lib.go", "notes.md"} {
+			if strings.Contains(output, name) {
+				t.Errorf("find не должен находить %s:\n%s", name, output)
+			}
+		}
+
+		// Прежний вызов с одним шаблоном продолжает работать
+		output = captureOutput(func() {
+			if err := app.cmdFindByName([]string{"*.md"}); err != nil {
+				t.Errorf("ошибка при выполнении find: %v", err)
+			}
+		})
+		if !strings.Contains(output, "notes.md") {
+			t.Errorf("find по шаблону должен найти notes.md:\n%s", output)
+		}
+
+		if err := app.cmdFindByName([]string{"--maxdepth=-1"}); err == nil {
+			t.Error("ожидалась ошибка для отрицательной глубины")
+		}
+	})
+
+	t.Run("FilterExpressions", func(t *testing.T) {
+		exprDir := filepath.Join(tempDir, "expr")
+		if err := os.MkdirAll(filepath.Join(exprDir, "sub"), 0755); err != nil {
+			t.Fatalf("не удалось создать директорию: %v", err)
+		}
+		for name, size := range map[string]int{"main.go": 10, "main_test.go": 10, "README.md": 2048, "sub/big.go": 4096} {
+			if err := os.WriteFile(filepath.Join(exprDir, name), make([]byte, size), 0644); err != nil {
+				t.Fatalf("не удалось создать файл %s: %v", name, err)
+			}
+		}
+		if err := app.cmdChangeDir([]string{exprDir}); err != nil {
+			t.Fatalf("не удалось изменить директорию: %v", err)
+		}
+
+		output := captureOutput(func() {
+			if err := app.cmdListDir(strings.Split(`ext in (go,md) and not name ~ "_test"`, " ")); err != nil {
+				t.Errorf("ошибка при выполнении ls с выражением: %v", err)
+			}
+		})
+		if !strings.Contains(output, "main.go") || !strings.Contains(output, "README.md") || strings.Contains(output, "main_test.go") {
+			t.Errorf("ls с выражением вернул неверный результат:\n%s", output)
+		}
+
+		output = captureOutput(func() {
+			if err := app.cmdFindByName([]string{"ext", "=", "go", "and", "size", ">", "1K"}); err != nil {
+				t.Errorf("ошибка при выполнении find с выражением: %v", err)
+			}
+		})
+		if !strings.Contains(output, "big.go") || strings.Contains(output, "main.go") {
+			t.Errorf("find с выражением вернул неверный результат:\n%s", output)
+		}
+
+		if err := app.cmdFilter([]string{"size", ">", "1K"}); err != nil {
+			t.Errorf("ошибка при применении фильтра-выражения: %v", err)
+		}
+		if app.tabs.Active().Filter.Expression != "size > 1K" {
+			t.Errorf("выражение фильтра не сохранено: %q", app.tabs.Active().Filter.Expression)
+		}
+		_ = app.cmdFilter([]string{})
+
+		output = captureOutput(func() {
+			err := app.processCommand("filter size >> 1K")
+			var queryErr *navigation.QueryError
+			if !errors.As(err, &queryErr) {
+				t.Errorf("ожидалась ошибка разбора выражения, получено %v", err)
+			}
+		})
+		if !strings.Contains(output, "size >> 1K\n      ^") {
+			t.Errorf("не выведен указатель на место ошибки:\n%s", output)
+		}
+	})
+	t.Run("IgnoreFiles", func(t *testing.T) {
+		// Глобальный ignore-файл берется из временного домашнего каталога
+		t.Setenv("HOME", tempDir)
+		repoDir := filepath.Join(tempDir, "repo")
+		files := map[string]string{
+			".gitignore":              "node_modules/\n*.log\n",
+			"main.go":                 "needle",
+			"debug.log":               "needle",
+			"node_modules/dep/dep.js": "needle",
+			".git/config":             "needle",
+		}
+		for name, content := range files {
+			path := filepath.Join(repoDir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatalf("не удалось создать директорию: %v", err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("не удалось создать файл %s: %v", name, err)
+			}
+		}
+		if err := app.cmdChangeDir([]string{repoDir}); err != nil {
+			t.Fatalf("не удалось изменить директорию: %v", err)
+		}
+
+		output := captureOutput(func() {
+			if err := app.cmdFindByContent([]string{"--ignore", "needle"}); err != nil {
+				t.Errorf("ошибка при выполнении grep: %v", err)
+			}
+		})
+		if !strings.Contains(output, "main.go") || strings.Contains(output, "dep.js") ||
+			strings.Contains(output, "debug.log") || strings.Contains(output, "config") {
+			t.Errorf("grep --ignore должен пропускать исключенные файлы:\n%s", output)
+		}
+		output = captureOutput(func() {
+			if err := app.cmdFindByContent([]string{"needle"}); err != nil {
+				t.Errorf("ошибка при выполнении grep: %v", err)
+			}
+		})
+		if !strings.Contains(output, "dep.js") {
+			t.Errorf("без --ignore grep должен обходить все файлы:\n%s", output)
+		}
+
+		output = captureOutput(func() {
+			if err := app.cmdFindByName([]string{"--ignore", "*.js"}); err != nil {
+				t.Errorf("ошибка при выполнении find: %v", err)
+			}
+		})
+		if strings.Contains(output, "dep.js") {
+			t.Errorf("find --ignore не должен находить исключенные файлы:\n%s", output)
+		}
+
+		output = captureOutput(func() {
+			if err := app.cmdTree([]string{"--ignore", "--all"}); err != nil {
+				t.Errorf("ошибка при выполнении tree: %v", err)
+			}
+		})
+		if !strings.Contains(output, "main.go") || !strings.Contains(output, ".gitignore") || strings.Contains(output, "node_modules") {
+			t.Errorf("tree --ignore вернул неверный результат:\n%s", output)
+		}
+
+		output = captureOutput(func() {
+			if err := app.cmdDiskUsage([]string{"--ignore", "--depth=0"}); err != nil {
+				t.Errorf("ошибка при выполнении du: %v", err)
+			}
+		})
+		if !strings.Contains(output, repoDir) || strings.Contains(output, "node_modules") {
+			t.Errorf("du --depth=0 должен вывести только корень:\n%s", output)
+		}
+
+		archivePath := filepath.Join(tempDir, "repo.zip")
+		if err := app.cmdCreateArchive([]string{"--ignore", archivePath, "zip", repoDir}); err != nil {
+			t.Fatalf("ошибка при создании архива: %v", err)
+		}
+		contents, err := app.archiver.ListArchiveContents(archivePath)
+		if err != nil {
+			t.Fatalf("не удалось прочитать архив: %v", err)
+		}
+		if got := strings.Join(contents, ","); got != ".gitignore,main.go" {
+			t.Errorf("архив должен содержать только неисключенные файлы, получено %s", got)
+		}
+
+		if err := app.cmdTree([]string{"--unknown"}); err == nil {
+			t.Error("ожидалась ошибка для неизвестного флага tree")
+		}
+	})
+
+	t.Run("GlobPatterns", func(t *testing.T) {
+		globDir := filepath.Join(tempDir, "glob")
+		for _, name := range []string{"README.md", "main.go", "main_test.go", "docs/guide.md", "vendor/lib.go"} {
+			path := filepath.Join(globDir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatalf("не удалось создать директорию: %v", err)
+			}
+			if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+				t.Fatalf("не удалось создать файл %s: %v", name, err)
+			}
+		}
+		if err := app.cmdChangeDir([]string{globDir}); err != nil {
+			t.Fatalf("не удалось изменить директорию: %v", err)
+		}
+
+		output := captureOutput(func() {
+			if err := app.cmdFindByName([]string{"--iname=readme.*"}); err != nil {
+				t.Errorf("ошибка при выполнении find: %v", err)
+			}
+		})
+		if !strings.Contains(output, "README.md") {
+			t.Errorf("find --iname должен находить имя без учета регистра:\n%s", output)
+		}
+
+		output = captureOutput(func() {
+			if err := app.processCommand(`find --name=*.{go,md} --prune=vendor`); err != nil {
+				t.Errorf("ошибка при выполнении find: %v", err)
+			}
+		})
+		for _, name := range []string{"README.md", "main.go", "guide.md"} {
+			if !strings.Contains(output, name) {
+				t.Errorf("find с альтернативами должен найти %s:\n%s", name, output)
+			}
+		}
+		if strings.Contains(output, "lib.go") {
+			t.Errorf("find не должен заходить в vendor:\n%s", output)
+		}
+
+		archivePath := filepath.Join(tempDir, "glob.zip")
+		err := app.cmdCreateArchive([]string{"--include=*.{go,md}", "--exclude=*_test.go", "--exclude=vendor", archivePath, "zip", globDir})
+		if err != nil {
+			t.Fatalf("ошибка при создании архива: %v", err)
+		}
+		contents, err := app.archiver.ListArchiveContents(archivePath)
+		if err != nil {
+			t.Fatalf("не удалось прочитать архив: %v", err)
+		}
+		if got := strings.Join(contents, ","); got != "README.md,docs/guide.md,main.go" {
+			t.Errorf("архив должен содержать только отобранные файлы, получено %s", got)
+		}
+
+		if err := app.cmdCreateArchive([]string{`--include=*\`, archivePath, "zip", globDir}); err == nil {
+			t.Error("ожидалась ошибка для некорректного шаблона")
+		}
+	})
+
+	t.Run("CopyPreserve", func(t *testing.T) {
+		copyDir := filepath.Join(tempDir, "preserve")
+		if err := os.MkdirAll(copyDir, 0755); err != nil {
+			t.Fatalf("не удалось создать директорию: %v", err)
+		}
+		source := filepath.Join(copyDir, "old.txt")
+		if err := os.WriteFile(source, []byte("old"), 0600); err != nil {
+			t.Fatalf("не удалось создать файл: %v", err)
+		}
+		old := time.Date(2019, 5, 6, 7, 8, 9, 0, time.UTC)
+		if err := os.Chtimes(source, old, old); err != nil {
+			t.Fatalf("не удалось изменить время: %v", err)
+		}
+		if err := app.cmdChangeDir([]string{copyDir}); err != nil {
+			t.Fatalf("не удалось изменить директорию: %v", err)
+		}
+
+		if err := app.processCommand("cp --preserve=times old.txt times.txt"); err != nil {
+			t.Fatalf("ошибка cp --preserve: %v", err)
+		}
+		if err := app.processCommand("cp -a old.txt archive.txt"); err != nil {
+			t.Fatalf("ошибка cp -a: %v", err)
+		}
+		for _, name := range []string{"times.txt", "archive.txt"} {
+			info, err := os.Stat(filepath.Join(copyDir, name))
+			if err != nil {
+				t.Fatalf("копия %s не создана: %v", name, err)
+			}
+			if !info.ModTime().Equal(old) || info.Mode().Perm() != 0600 {
+				t.Errorf("%s: время или права не сохранены: %v %v", name, info.ModTime(), info.Mode())
+			}
+		}
+
+		if err := app.cmdCopy([]string{"--preserve=color", "old.txt", "bad.txt"}); err == nil {
+			t.Error("ожидалась ошибка для неизвестного атрибута")
+		}
+		if err := app.cmdCopy([]string{"-x", "old.txt", "bad.txt"}); err == nil {
+			t.Error("ожидалась ошибка для неизвестного флага")
+		}
+	})
+
+	t.Run("CopyConflicts", func(t *testing.T) {
+		savedConfig, savedInput := app.config, app.input
+		defer func() { app.config, app.input = savedConfig, savedInput }()
+		app.config = &config.Config{ConfigFile: filepath.Join(tempDir, "config.json")}
+
+		conflictDir := filepath.Join(tempDir, "conflicts")
+		for _, name := range []string{"a.txt", "b.txt", "out/a.txt", "out/b.txt"} {
+			path := filepath.Join(conflictDir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatalf("не удалось создать директорию: %v", err)
+			}
+			if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+				t.Fatalf("не удалось создать файл %s: %v", name, err)
+			}
+		}
+		if err := app.cmdChangeDir([]string{conflictDir}); err != nil {
+			t.Fatalf("не удалось изменить директорию: %v", err)
+		}
+		content := func(name string) string {
+			data, _ := os.ReadFile(filepath.Join(conflictDir, name))
+			return string(data)
+		}
+
+		if err := app.processCommand("cp -n a.txt b.txt out"); err != nil {
+			t.Fatalf("ошибка cp -n: %v", err)
+		}
+		if content("out/a.txt") != "out/a.txt" {
+			t.Error("cp -n не должен заменять существующие файлы")
+		}
+
+		if err := app.cmdConfig([]string{"conflict", "backup"}); err != nil {
+			t.Fatalf("ошибка config: %v", err)
+		}
+		if err := app.cmdConfig([]string{"backup", "numbered"}); err != nil {
+			t.Fatalf("ошибка config: %v", err)
+		}
+		if err := app.processCommand("cp a.txt out"); err != nil {
+			t.Fatalf("ошибка cp: %v", err)
+		}
+		if content("out/a.txt") != "a.txt" || content("out/a.txt.~1~") != "out/a.txt" {
+			t.Error("политика из настроек должна создавать нумерованную резервную копию")
+		}
+
+		app.input = bufio.NewScanner(strings.NewReader("x\nR\n"))
+		captureOutput(func() {
+			if err := app.processCommand("cp -i a.txt b.txt out"); err != nil {
+				t.Errorf("ошибка cp -i: %v", err)
+			}
+		})
+		if content("out/a (1).txt") != "a.txt" || content("out/b (1).txt") != "b.txt" {
+			t.Error("ответ R должен переименовывать все конфликтующие файлы")
+		}
+
+		if err := app.processCommand("mv --conflict=skip b.txt out"); err != nil {
+			t.Fatalf("ошибка mv: %v", err)
+		}
+		if content("b.txt") != "b.txt" {
+			t.Error("mv с политикой skip не должен перемещать файл")
+		}
+		if err := app.cmdMove([]string{"a.txt", "b.txt", "a.txt"}); err == nil {
+			t.Error("ожидалась ошибка: несколько источников без директории назначения")
+		}
+		if err := app.cmdCopy([]string{"a.txt", "."}); err == nil {
+			t.Error("ожидалась ошибка при копировании файла в самого себя")
+		}
+
+		if err := app.cmdConfig([]string{"conflict", "merge"}); err == nil {
+			t.Error("ожидалась ошибка для недопустимого значения")
+		}
+		if err := app.cmdConfig([]string{"colour", "on"}); err == nil {
+			t.Error("ожидалась ошибка для неизвестной настройки")
+		}
+		if err := app.cmdConfig([]string{"reset", "conflict"}); err != nil || app.setting("conflict") != "overwrite" {
+			t.Errorf("сброс настройки не вернул значение по умолчанию: %v", err)
+		}
+		output := captureOutput(func() {
+			if err := app.cmdConfig(nil); err != nil {
+				t.Errorf("ошибка config: %v", err)
+			}
+		})
+		if !strings.Contains(output, "numbered") || !strings.Contains(output, "overwrite") {
+			t.Errorf("список настроек неполон:\n%s", output)
+		}
+	})
+
+	t.Run("Rename", func(t *testing.T) {
+		savedHistory, savedInput := app.renameHistory, app.input
+		defer func() { app.renameHistory, app.input = savedHistory, savedInput }()
+		app.renameHistory = &fileops.RenameHistory{HistoryFile: filepath.Join(tempDir, "rename-history.json")}
+
+		renameDir := filepath.Join(tempDir, "rename")
+		if err := os.MkdirAll(renameDir, 0755); err != nil {
+			t.Fatalf("не удалось создать директорию: %v", err)
+		}
+		for _, name := range []string{"IMG_0001.jpg", "IMG_0002.jpg", "notes.TXT"} {
+			if err := os.WriteFile(filepath.Join(renameDir, name), []byte(name), 0644); err != nil {
+				t.Fatalf("не удалось создать файл: %v", err)
+			}
+		}
+		if err := app.cmdChangeDir([]string{renameDir}); err != nil {
+			t.Fatalf("не удалось изменить директорию: %v", err)
+		}
+		exists := func(name string) bool {
+			_, err := os.Stat(filepath.Join(renameDir, name))
+			return err == nil
+		}
+
+		output := captureOutput(func() {
+			if err := app.processCommand(`rename -n 's/IMG_(\d+)/photo-$1/' *.jpg`); err != nil {
+				t.Errorf("ошибка rename -n: %v", err)
+			}
+		})
+		if !strings.Contains(output, "IMG_0001.jpg → photo-0001.jpg") || !exists("IMG_0001.jpg") {
+			t.Errorf("-n должен только показывать изменения:\n%s", output)
+		}
+
+		app.input = bufio.NewScanner(strings.NewReader("n\n"))
+		captureOutput(func() {
+			if err := app.processCommand(`rename 's/IMG_(\d+)/photo-$1/' *.jpg`); err != nil {
+				t.Errorf("ошибка rename: %v", err)
+			}
+		})
+		if !exists("IMG_0001.jpg") {
+			t.Error("без подтверждения файлы не должны переименовываться")
+		}
+
+		app.input = bufio.NewScanner(strings.NewReader("y\n"))
+		captureOutput(func() {
+			if err := app.processCommand(`rename 's/IMG_(\d+)/photo-$1/' *.jpg`); err != nil {
+				t.Errorf("ошибка rename: %v", err)
+			}
+		})
+		if !exists("photo-0001.jpg") || !exists("photo-0002.jpg") {
+			t.Error("файлы должны переименовываться после подтверждения")
+		}
+
+		captureOutput(func() {
+			if err := app.processCommand("rename -y --case=lower --template={n:2}-{name}{ext} --start=5 notes.TXT"); err != nil {
+				t.Errorf("ошибка rename: %v", err)
+			}
+		})
+		if !exists("05-notes.txt") {
+			t.Error("шаблон и смена регистра должны применяться по порядку")
+		}
+
+		if err := app.processCommand("rename -y --ext=png photo-0001.jpg photo-0002.jpg photo-0001.jpg"); err == nil {
+			t.Error("ожидалась ошибка: два файла получают одно имя")
+		}
+
+		captureOutput(func() {
+			for i := 0; i < 2; i++ {
+				if err := app.processCommand("rename --undo -y"); err != nil {
+					t.Errorf("ошибка rename --undo: %v", err)
+				}
+			}
+		})
+		if !exists("IMG_0001.jpg") || !exists("IMG_0002.jpg") || !exists("notes.TXT") {
+			t.Error("отмена должна возвращать прежние имена")
+		}
+		if err := app.processCommand("rename --undo -y"); err == nil {
+			t.Error("ожидалась ошибка: нечего отменять")
+		}
+		if err := app.processCommand("rename *.jpg"); err == nil {
+			t.Error("ожидалась ошибка: не задано правило")
+		}
+	})
+
+	t.Run("Sync", func(t *testing.T) {
+		syncDir := filepath.Join(tempDir, "sync")
+		for _, name := range []string{"src/a.txt", "src/sub/b.txt", "src/debug.log", "dst/old.txt"} {
+			path := filepath.Join(syncDir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatalf("не удалось создать директорию: %v", err)
+			}
+			if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+				t.Fatalf("не удалось создать файл: %v", err)
+			}
+		}
+		if err := app.cmdChangeDir([]string{syncDir}); err != nil {
+			t.Fatalf("не удалось изменить директорию: %v", err)
+		}
+		exists := func(name string) bool {
+			_, err := os.Stat(filepath.Join(syncDir, name))
+			return err == nil
+		}
+
+		output := captureOutput(func() {
+			if err := app.processCommand("sync -n --delete --exclude=*.log src dst"); err != nil {
+				t.Errorf("ошибка sync -n: %v", err)
+			}
+		})
+		if !strings.Contains(output, "+ "+filepath.Join("sub", "b.txt")) || !strings.Contains(output, "- old.txt") || exists("dst/a.txt") {
+			t.Errorf("-n должен только показывать план:\n%s", output)
+		}
+
+		captureOutput(func() {
+			if err := app.processCommand("sync --delete --exclude=*.log src dst"); err != nil {
+				t.Errorf("ошибка sync: %v", err)
+			}
+		})
+		if !exists("dst/sub/b.txt") || exists("dst/old.txt") || exists("dst/debug.log") {
+			t.Error("назначение должно совпадать с источником без исключенных файлов")
+		}
+		if err := app.processCommand("sync src"); err == nil {
+			t.Error("ожидалась ошибка: не указано назначение")
+		}
+		if err := app.processCommand("sync --mirror src dst"); err == nil {
+			t.Error("ожидалась ошибка для неизвестного флага")
+		}
+	})
+
+	t.Run("Compare", func(t *testing.T) {
+		compareDir := filepath.Join(tempDir, "compare")
+		for _, name := range []string{"a/same.txt", "b/same.txt", "a/left.txt", "b/right.txt"} {
+			path := filepath.Join(compareDir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatalf("не удалось создать директорию: %v", err)
+			}
+			if err := os.WriteFile(path, []byte(filepath.Base(name)), 0644); err != nil {
+				t.Fatalf("не удалось создать файл: %v", err)
+			}
+		}
+		if err := app.cmdChangeDir([]string{compareDir}); err != nil {
+			t.Fatalf("не удалось изменить директорию: %v", err)
+		}
+
+		output := captureOutput(func() {
+			if err := app.processCommand("compare --content a b"); err != nil {
+				t.Errorf("ошибка compare: %v", err)
+			}
+		})
+		if !strings.Contains(output, "< left.txt") || !strings.Contains(output, "> right.txt") || strings.Contains(output, "same.txt") {
+			t.Errorf("неверный отчет:\n%s", output)
+		}
+
+		output = captureOutput(func() {
+			if err := app.processCommand("compare --json --jobs=2 a b"); err != nil {
+				t.Errorf("ошибка compare --json: %v", err)
+			}
+		})
+		var result fileops.CompareResult
+		if err := json.Unmarshal([]byte(output), &result); err != nil {
+			t.Fatalf("вывод должен быть JSON: %v\n%s", err, output)
+		}
+		if result.Summary.LeftOnly != 1 || result.Summary.RightOnly != 1 || len(result.Entries) != 2 {
+			t.Errorf("неверный результат: %+v", result)
+		}
+		if err := app.processCommand("compare a"); err == nil {
+			t.Error("ожидалась ошибка: указана одна директория")
+		}
+		if err := app.processCommand("compare --jobs=0 a b"); err == nil {
+			t.Error("ожидалась ошибка для неверного числа потоков")
+		}
+	})
+
+	t.Run("Dupes", func(t *testing.T) {
+		dupesDir := filepath.Join(tempDir, "dupes")
+		for _, name := range []string{"one/a.txt", "two/b.txt", "c.txt"} {
+			path := filepath.Join(dupesDir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatalf("не удалось создать директорию: %v", err)
+			}
+			if err := os.WriteFile(path, []byte("same content"), 0644); err != nil {
+				t.Fatalf("не удалось создать файл: %v", err)
+			}
+		}
+		if err := app.cmdChangeDir([]string{dupesDir}); err != nil {
+			t.Fatalf("не удалось изменить директорию: %v", err)
+		}
+
+		output := captureOutput(func() {
+			if err := app.processCommand("dupes --hardlink --keep=shortest -n"); err != nil {
+				t.Errorf("ошибка dupes -n: %v", err)
+			}
+		})
+		if !strings.Contains(output, "* "+filepath.Join(dupesDir, "c.txt")) || !strings.Contains(output, filepath.Join(dupesDir, "two", "b.txt")) {
+			t.Errorf("неверный список групп:\n%s", output)
+		}
+
+		captureOutput(func() {
+			if err := app.processCommand("dupes --hardlink --keep=shortest -y ."); err != nil {
+				t.Errorf("ошибка dupes --hardlink: %v", err)
+			}
+		})
+		kept, _ := os.Stat(filepath.Join(dupesDir, "c.txt"))
+		for _, name := range []string{"one/a.txt", "two/b.txt"} {
+			if info, err := os.Stat(filepath.Join(dupesDir, name)); err != nil || !os.SameFile(kept, info) {
+				t.Errorf("%s должен стать жесткой ссылкой на c.txt", name)
+			}
+		}
+		if err := app.processCommand("dupes --trash --symlink"); err == nil {
+			t.Error("ожидалась ошибка: указано два действия")
+		}
+		if err := app.processCommand("dupes --keep=largest"); err == nil {
+			t.Error("ожидалась ошибка для неизвестного правила")
+		}
+	})
+
+	t.Run("Hash", func(t *testing.T) {
+		hashDir := filepath.Join(tempDir, "hash")
+		for _, name := range []string{"a.txt", "sub/b.txt"} {
+			path := filepath.Join(hashDir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatalf("не удалось создать директорию: %v", err)
+			}
+			if err := os.WriteFile(path, []byte("abc"), 0644); err != nil {
+				t.Fatalf("не удалось создать файл: %v", err)
+			}
+		}
+		if err := app.cmdChangeDir([]string{hashDir}); err != nil {
+			t.Fatalf("не удалось изменить директорию: %v", err)
+		}
+
+		sums := captureOutput(func() {
+			if err := app.processCommand("hash --jobs=2 a.txt sub/*.txt"); err != nil {
+				t.Errorf("ошибка hash: %v", err)
+			}
+		})
+		sha := "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+		if sums != sha+"  a.txt\n"+sha+"  "+filepath.Join("sub", "b.txt")+"\n" {
+			t.Errorf("вывод должен быть в формате sha256sum:\n%s", sums)
+		}
+		// Найденные по шаблону файлы выводятся относительно текущей директории, как у sha256sum
+		globbed := captureOutput(func() {
+			if err := app.processCommand("hash *.txt"); err != nil {
+				t.Errorf("ошибка hash: %v", err)
+			}
+		})
+		if globbed != sha+"  a.txt\n" {
+			t.Errorf("путь по шаблону должен выводиться относительно текущей директории:\n%s", globbed)
+		}
+		output := captureOutput(func() {
+			if err := app.processCommand("hash --algo=md5 a.txt"); err != nil {
+				t.Errorf("ошибка hash --algo: %v", err)
+			}
+		})
+		if !strings.HasPrefix(output, "900150983cd24fb0d6963f7d28e17f72  a.txt") {
+			t.Errorf("неверная сумма md5: %s", output)
+		}
+
+		if err := os.WriteFile(filepath.Join(hashDir, "SUMS"), []byte(sums), 0644); err != nil {
+			t.Fatalf("не удалось записать файл сумм: %v", err)
+		}
+		captureOutput(func() {
+			if err := app.processCommand("hash --check SUMS"); err != nil {
+				t.Errorf("файлы должны совпадать: %v", err)
+			}
+		})
+		if err := os.WriteFile(filepath.Join(hashDir, "sub", "b.txt"), []byte("abd"), 0644); err != nil {
+			t.Fatalf("не удалось изменить файл: %v", err)
+		}
+		if err := os.Remove(filepath.Join(hashDir, "a.txt")); err != nil {
+			t.Fatalf("не удалось удалить файл: %v", err)
+		}
+		var checkErr error
+		output = captureOutput(func() { checkErr = app.processCommand("hash -c SUMS") })
+		if checkErr == nil || !strings.Contains(output, "a.txt: MISSING") || !strings.Contains(output, "b.txt: FAILED") {
+			t.Errorf("ожидались MISSING и FAILED (%v):\n%s", checkErr, output)
+		}
+
+		output = captureOutput(func() {
+			if err := app.processCommand("info --hash sub/b.txt"); err != nil {
+				t.Errorf("ошибка info --hash: %v", err)
+			}
+		})
+		abd := sha256.Sum256([]byte("abd"))
+		if !strings.Contains(output, "SHA256: "+hex.EncodeToString(abd[:])) {
+			t.Errorf("в info должна быть контрольная сумма:\n%s", output)
+		}
+		if err := app.processCommand("hash --algo=sha3 SUMS"); err == nil {
+			t.Error("ожидалась ошибка для неизвестного алгоритма")
+		}
+	})
+
+	t.Run("Manifest", func(t *testing.T) {
+		manifestDir := filepath.Join(tempDir, "manifest")
+		for _, name := range []string{"site/index.html", "site/css/app.css"} {
+			path := filepath.Join(manifestDir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatalf("не удалось создать директорию: %v", err)
+			}
+			if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+				t.Fatalf("не удалось создать файл: %v", err)
+			}
+		}
+		if err := app.cmdChangeDir([]string{manifestDir}); err != nil {
+			t.Fatalf("не удалось изменить директорию: %v", err)
+		}
+
+		captureOutput(func() {
+			if err := app.processCommand("manifest create site site.mtree"); err != nil {
+				t.Errorf("ошибка manifest create: %v", err)
+			}
+			if err := app.processCommand("manifest verify site site.mtree"); err != nil {
+				t.Errorf("дерево должно совпадать: %v", err)
+			}
+			// Манифест внутри дерева в манифест не попадает
+			if err := app.processCommand("manifest create --jobs=2 site site/MANIFEST.json"); err != nil {
+				t.Errorf("ошибка manifest create: %v", err)
+			}
+			if err := app.processCommand("manifest verify site site/MANIFEST.json"); err != nil {
+				t.Errorf("дерево должно совпадать: %v", err)
+			}
+		})
+		data, _ := os.ReadFile(filepath.Join(manifestDir, "site", "MANIFEST.json"))
+		if !strings.HasPrefix(string(data), "{") || strings.Contains(string(data), "MANIFEST") {
+			t.Errorf("ожидался JSON без самого манифеста:\n%s", data)
+		}
+
+		if err := os.WriteFile(filepath.Join(manifestDir, "site", "index.html"), []byte("hacked"), 0644); err != nil {
+			t.Fatalf("не удалось изменить файл: %v", err)
+		}
+		var verifyErr error
+		output := captureOutput(func() { verifyErr = app.processCommand("manifest verify site site.mtree") })
+		if verifyErr == nil || !strings.Contains(output, "~ index.html (size, content") {
+			t.Errorf("ожидалось расхождение содержимого (%v):\n%s", verifyErr, output)
+		}
+		if err := app.processCommand("manifest create --format=xml site x"); err == nil {
+			t.Error("ожидалась ошибка для неизвестного формата")
+		}
+		if err := app.processCommand("manifest check site site.mtree"); err == nil {
+			t.Error("ожидалась ошибка для неизвестной подкоманды")
+		}
+	})
+
+	t.Run("CopyJobs", func(t *testing.T) {
+		savedConfig := app.config
+		defer func() { app.config = savedConfig }()
+		app.config = &config.Config{ConfigFile: filepath.Join(tempDir, "jobs-config.json")}
+
+		treeDir := filepath.Join(tempDir, "jobs", "tree")
+		for i := 0; i < 40; i++ {
+			path := filepath.Join(treeDir, "d"+strconv.Itoa(i%4), "f"+strconv.Itoa(i))
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatalf("не удалось создать директорию: %v", err)
+			}
+			if err := os.WriteFile(path, []byte(path), 0644); err != nil {
+				t.Fatalf("не удалось создать файл: %v", err)
+			}
+		}
+		if err := app.cmdChangeDir([]string{filepath.Dir(treeDir)}); err != nil {
+			t.Fatalf("не удалось изменить директорию: %v", err)
+		}
+
+		if err := app.cmdConfig([]string{"jobs", "3"}); err != nil {
+			t.Fatalf("ошибка config: %v", err)
+		}
+		if err := app.cmdConfig([]string{"buffer", "64K"}); err != nil {
+			t.Fatalf("ошибка config: %v", err)
+		}
+		if err := app.processCommand("cp tree copy1"); err != nil {
+			t.Fatalf("ошибка cp: %v", err)
+		}
+		if err := app.processCommand("cp --jobs=1 --buffer=4K tree copy2"); err != nil {
+			t.Fatalf("ошибка cp --jobs: %v", err)
+		}
+		for _, name := range []string{"copy1", "copy2"} {
+			data, err := os.ReadFile(filepath.Join(filepath.Dir(treeDir), name, "d3", "f39"))
+			if err != nil || string(data) != filepath.Join(treeDir, "d3", "f39") {
+				t.Errorf("дерево %s скопировано не полностью: %v", name, err)
+			}
+		}
+
+		for _, args := range []string{"cp --jobs=0 tree copy3", "cp --jobs=many tree copy3", "cp --buffer=0 tree copy3"} {
+			if err := app.processCommand(args); err == nil {
+				t.Errorf("ожидалась ошибка для %q", args)
+			}
+		}
+		if err := app.cmdConfig([]string{"jobs", "auto"}); err != nil {
+			t.Errorf("значение auto должно приниматься: %v", err)
+		}
+		if err := app.cmdConfig([]string{"buffer", "2GiB"}); err == nil {
+			t.Error("ожидалась ошибка для слишком большого буфера")
+		}
+	})
 }
 
 // captureOutput захватывает вывод в stdout во время выполнения функции
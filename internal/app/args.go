@@ -0,0 +1,169 @@
+package app
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"file-manager/internal/glob"
+	"file-manager/internal/i18n"
+)
+
+// ArgMode определяет, как processCommand разбирает аргументы команды
+type ArgMode int
+
+const (
+	// ArgsPlain — кавычки и экранирование снимаются (по умолчанию)
+	ArgsPlain ArgMode = iota
+	// ArgsGlob — как ArgsPlain, а аргументы с шаблонами вне кавычек раскрываются в список путей
+	ArgsGlob
+	// ArgsRaw — аргументы передаются как введены, с кавычками; для команд, разбирающих выражения фильтра
+	ArgsRaw
+)
+
+// escapable — символы, которые \ экранирует вне кавычек. Обратная косая черта перед другими
+// символами остается как есть, чтобы не ломать пути Windows вида C:\Users.
+const escapable = " \t\"'\\*?[]{}"
+
+// splitCommandLine делит строку команды на слова по пробелам с учетом кавычек и экранирования.
+// Слова возвращаются как введены, вместе с кавычками.
+func splitCommandLine(input string) ([]string, error) {
+	var words []string
+	var current strings.Builder
+	inWord := false
+	var quote byte
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' && i+1 < len(input) && (input[i+1] == '"' || input[i+1] == '\\') {
+				current.WriteByte(c)
+				i++
+				c = input[i]
+			} else if c == quote {
+				quote = 0
+			}
+			current.WriteByte(c)
+		case c == ' ' || c == '\t':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			inWord = true
+			if c == '"' || c == '\'' {
+				quote = c
+			} else if c == '\\' && i+1 < len(input) && strings.IndexByte(escapable, input[i+1]) >= 0 {
+				current.WriteByte(c)
+				i++
+				c = input[i]
+			}
+			current.WriteByte(c)
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New(i18n.T("args_unclosed_quote"))
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+	return words, nil
+}
+
+// commandWord — слово команды после снятия кавычек
+type commandWord struct {
+	// value — текст слова без кавычек и экранирования
+	value string
+	// pattern — шаблон glob, в котором символы из кавычек экранированы
+	pattern string
+	// hasMeta — слово содержит символы шаблона вне кавычек
+	hasMeta bool
+}
+
+// parseWord снимает кавычки и экранирование со слова, введенного пользователем
+func parseWord(raw string) commandWord {
+	var value, pattern strings.Builder
+	var word commandWord
+	var quote byte
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+			continue
+		case quote != 0 && c == quote:
+			quote = 0
+			continue
+		case c == '\\' && i+1 < len(raw) && (quote == 0 && strings.IndexByte(escapable, raw[i+1]) >= 0 ||
+			quote == '"' && (raw[i+1] == '"' || raw[i+1] == '\\')):
+			i++
+			value.WriteByte(raw[i])
+			pattern.WriteString(glob.QuoteMeta(raw[i : i+1]))
+			continue
+		}
+		value.WriteByte(c)
+		switch {
+		case quote == 0 && strings.IndexByte("*?[{", c) >= 0:
+			word.hasMeta = true
+			pattern.WriteByte(c)
+		case quote == 0 && strings.IndexByte("]},", c) >= 0:
+			// Закрывающие скобки и запятая имеют смысл только внутри шаблона
+			pattern.WriteByte(c)
+		default:
+			pattern.WriteString(glob.QuoteMeta(raw[i : i+1]))
+		}
+	}
+	word.value, word.pattern = value.String(), pattern.String()
+	return word
+}
+
+// unquoteArg снимает кавычки и экранирование с аргумента
+func unquoteArg(raw string) string {
+	return parseWord(raw).value
+}
+
+// commandArgs готовит аргументы команды согласно ее режиму разбора
+func (a *App) commandArgs(mode ArgMode, words []string) []string {
+	args := make([]string, 0, len(words))
+	a.globMatches = make(map[string]bool)
+	for _, raw := range words {
+		if mode == ArgsRaw {
+			args = append(args, raw)
+			continue
+		}
+		word := parseWord(raw)
+		if mode != ArgsGlob || !word.hasMeta || strings.HasPrefix(word.value, "-") {
+			args = append(args, word.value)
+			continue
+		}
+		args = append(args, a.expandArg(word)...)
+	}
+	return args
+}
+
+// expandArg раскрывает шаблон в аргументе в список абсолютных путей. Начальная часть без шаблонов
+// разрешается как обычный путь (с ~, $VAR и @закладка), а найденные имена запоминаются
+// в globMatches и дальше не разворачиваются. Как и в оболочке,
+// шаблон без совпадений передается команде без изменений.
+func (a *App) expandArg(word commandWord) []string {
+	prefix, rest := glob.Split(word.pattern)
+	base, err := a.navigator().GetCurrentDirectory()
+	if err != nil {
+		return []string{word.value}
+	}
+	if prefix != "" {
+		if base, err = a.resolvePath(glob.Unescape(prefix)); err != nil {
+			return []string{word.value}
+		}
+	}
+	matches, err := glob.Expand(rest, base)
+	if err != nil || len(matches) == 0 {
+		return []string{word.value}
+	}
+	for i, match := range matches {
+		matches[i] = filepath.Join(base, filepath.FromSlash(match))
+		a.globMatches[matches[i]] = true
+	}
+	return matches
+}
@@ -0,0 +1,190 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"file-manager/internal/i18n"
+	"file-manager/internal/navigation"
+)
+
+func (a *App) cmdManageBookmarks(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf(i18n.T("error"), i18n.T("bookmark_args"))
+	}
+	dir, err := a.navigator().GetCurrentDirectory()
+	if err != nil {
+		return err
+	}
+	switch args[0] {
+	case "add":
+		if len(args) < 2 {
+			return fmt.Errorf(i18n.T("error"), i18n.T("bookmark_add_args"))
+		}
+		name := args[1]
+		path := dir
+		if len(args) >= 3 {
+			path, err = a.resolvePath(args[2])
+			if err != nil {
+				return err
+			}
+		}
+		return a.bookmarkManager.AddBookmark(name, path)
+	case "list":
+		bookmarks := a.bookmarkManager.ListBookmarks()
+		if len(args) >= 2 && strings.HasPrefix(args[1], "--tag=") {
+			bookmarks = a.bookmarkManager.ListBookmarksByTag(strings.TrimPrefix(args[1], "--tag="))
+		}
+		fmt.Println(i18n.T("bookmark_list"))
+		for i, bookmark := range bookmarks {
+			fmt.Printf("%d. %s\n", i+1, formatBookmark(bookmark))
+		}
+		return nil
+	case "remove":
+		if len(args) < 2 {
+			return fmt.Errorf(i18n.T("error"), i18n.T("bookmark_remove_args"))
+		}
+		return a.bookmarkManager.RemoveBookmark(args[1])
+	case "go":
+		if len(args) < 2 {
+			return fmt.Errorf(i18n.T("error"), i18n.T("bookmark_go_args"))
+		}
+		path, err := a.bookmarkManager.GetBookmarkPath(args[1])
+		if err != nil {
+			return err
+		}
+		if err := a.navigator().ChangeDirectory(path); err != nil {
+			return err
+		}
+		return a.bookmarkManager.RecordUse(args[1])
+	case "rename":
+		if len(args) != 3 {
+			return fmt.Errorf(i18n.T("error"), i18n.T("bookmark_rename_args"))
+		}
+		return a.bookmarkManager.RenameBookmark(args[1], args[2])
+	case "describe":
+		if len(args) < 2 {
+			return fmt.Errorf(i18n.T("error"), i18n.T("bookmark_describe_args"))
+		}
+		return a.bookmarkManager.SetDescription(args[1], strings.Join(args[2:], " "))
+	case "tag", "untag":
+		if len(args) < 3 {
+			return fmt.Errorf(i18n.T("error"), i18n.T("bookmark_tag_args"))
+		}
+		if args[0] == "untag" {
+			return a.bookmarkManager.UntagBookmark(args[1], args[2:]...)
+		}
+		return a.bookmarkManager.TagBookmark(args[1], args[2:]...)
+	case "check":
+		return a.checkBookmarks(len(args) >= 2 && args[1] == "--remove")
+	case "export", "import":
+		return a.exchangeBookmarks(args)
+	default:
+		return fmt.Errorf(i18n.T("error"), fmt.Sprintf(i18n.T("bookmark_unknown"), args[0]))
+	}
+}
+
+// formatBookmark форматирует закладку для вывода списка
+func formatBookmark(bookmark navigation.Bookmark) string {
+	result := fmt.Sprintf("%s -> %s", bookmark.Name, bookmark.Path)
+	if len(bookmark.Tags) > 0 {
+		result += fmt.Sprintf(" [%s]", strings.Join(bookmark.Tags, ", "))
+	}
+	if bookmark.UseCount > 0 {
+		result += fmt.Sprintf(" ("+i18n.T("bookmark_uses")+")", bookmark.UseCount)
+	}
+	if bookmark.Description != "" {
+		result += " — " + bookmark.Description
+	}
+	return result
+}
+
+// checkBookmarks находит закладки на несуществующие директории и предлагает исправить или удалить их
+func (a *App) checkBookmarks(removeAll bool) error {
+	broken := a.bookmarkManager.CheckBookmarks()
+	if len(broken) == 0 {
+		fmt.Println(i18n.T("bookmark_check_ok"))
+		return nil
+	}
+
+	fmt.Printf(i18n.T("bookmark_check_found")+"\n", len(broken))
+	for _, bookmark := range broken {
+		fmt.Printf("  %s -> %s\n", bookmark.Name, bookmark.Path)
+		if removeAll {
+			if err := a.bookmarkManager.RemoveBookmark(bookmark.Name); err != nil {
+				return err
+			}
+			continue
+		}
+
+		answer, ok := a.ask(i18n.T("bookmark_check_prompt"))
+		if !ok {
+			return nil
+		}
+		switch strings.ToLower(answer) {
+		case "r":
+			if err := a.bookmarkManager.RemoveBookmark(bookmark.Name); err != nil {
+				return err
+			}
+		case "f":
+			newPath, ok := a.ask(i18n.T("bookmark_check_new_path"))
+			if !ok {
+				return nil
+			}
+			path, err := a.resolvePath(newPath)
+			if err != nil {
+				return err
+			}
+			if err := a.bookmarkManager.UpdateBookmarkPath(bookmark.Name, path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// exchangeBookmarks выполняет экспорт или импорт закладок в формате JSON или GTK
+func (a *App) exchangeBookmarks(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf(i18n.T("error"), i18n.T("bookmark_exchange_args"))
+	}
+	format := args[1]
+
+	var path string
+	var err error
+	switch {
+	case len(args) >= 3:
+		path, err = a.resolvePath(args[2])
+	case format == "gtk":
+		path, err = navigation.DefaultGTKBookmarksFile()
+	default:
+		return fmt.Errorf(i18n.T("error"), i18n.T("bookmark_exchange_args"))
+	}
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case args[0] == "export" && format == "json":
+		err = a.bookmarkManager.ExportJSON(path)
+	case args[0] == "export" && format == "gtk":
+		err = a.bookmarkManager.ExportGTK(path)
+	case args[0] == "import" && (format == "json" || format == "gtk"):
+		var added int
+		if format == "json" {
+			added, err = a.bookmarkManager.ImportJSON(path)
+		} else {
+			added, err = a.bookmarkManager.ImportGTK(path)
+		}
+		if err == nil {
+			fmt.Printf(i18n.T("bookmark_imported")+"\n", added, path)
+		}
+		return err
+	default:
+		return fmt.Errorf(i18n.T("error"), fmt.Sprintf(i18n.T("bookmark_unknown_format"), format))
+	}
+	if err == nil {
+		fmt.Printf(i18n.T("bookmark_exported")+"\n", path)
+	}
+	return err
+}
@@ -351,3 +351,128 @@ func TestCdCommand(t *testing.T) {
 		t.Fatalf("ошибка при возврате в исходную директорию: %v", err)
 	}
 }
+
+// TestSplitCommandLine проверяет разбор строки команды с кавычками и экранированием
+func TestSplitCommandLine(t *testing.T) {
+	tests := []struct {
+		input string
+		words []string
+		args  []string
+	}{
+		{"cd  /tmp ", []string{"cd", "/tmp"}, []string{"cd", "/tmp"}},
+		{`cd "My Documents"`, []string{"cd", `"My Documents"`}, []string{"cd", "My Documents"}},
+		{`rm 'a "b"' c\ d`, []string{"rm", `'a "b"'`, `c\ d`}, []string{"rm", `a "b"`, "c d"}},
+		{`grep "say \"hi\""`, []string{"grep", `"say \"hi\""`}, []string{"grep", `say "hi"`}},
+		{`cd C:\Users`, []string{"cd", `C:\Users`}, []string{"cd", `C:\Users`}},
+		{`filter --name="a b"`, []string{"filter", `--name="a b"`}, []string{"filter", "--name=a b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			words, err := splitCommandLine(tt.input)
+			if err != nil {
+				t.Fatalf("ошибка разбора: %v", err)
+			}
+			if !equalStringSlices(words, tt.words) {
+				t.Errorf("получены слова %q, ожидались %q", words, tt.words)
+			}
+			var args []string
+			for _, word := range words {
+				args = append(args, unquoteArg(word))
+			}
+			if !equalStringSlices(args, tt.args) {
+				t.Errorf("получены аргументы %q, ожидались %q", args, tt.args)
+			}
+		})
+	}
+
+	if _, err := splitCommandLine(`cd "unclosed`); err == nil {
+		t.Error("ожидалась ошибка для незакрытой кавычки")
+	}
+}
+
+// TestCommandArgs проверяет раскрытие шаблонов в аргументах команд
+func TestCommandArgs(t *testing.T) {
+	app, err := NewApp()
+	if err != nil {
+		t.Fatalf("не удалось создать экземпляр приложения: %v", err)
+	}
+	tempDir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt", "c.md", "*.txt", "sub/d.txt"} {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("не удалось создать директорию: %v", err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("не удалось создать файл %s: %v", name, err)
+		}
+	}
+	if err := app.cmdChangeDir([]string{tempDir}); err != nil {
+		t.Fatalf("не удалось изменить директорию: %v", err)
+	}
+
+	tests := []struct {
+		mode  ArgMode
+		words []string
+		want  []string
+	}{
+		{ArgsGlob, []string{"*.txt"}, []string{filepath.Join(tempDir, "*.txt"), filepath.Join(tempDir, "a.txt"), filepath.Join(tempDir, "b.txt")}},
+		{ArgsGlob, []string{`"*.txt"`}, []string{"*.txt"}},
+		{ArgsGlob, []string{`\*.txt`}, []string{"*.txt"}},
+		{ArgsGlob, []string{"*.{md,rs}", "*.rs"}, []string{filepath.Join(tempDir, "c.md"), "*.rs"}},
+		{ArgsGlob, []string{tempDir + "/sub/*.txt"}, []string{filepath.Join(tempDir, "sub", "d.txt")}},
+		{ArgsGlob, []string{"--name=*.txt"}, []string{"--name=*.txt"}},
+		{ArgsPlain, []string{"*.txt", `"x y"`}, []string{"*.txt", "x y"}},
+		{ArgsRaw, []string{`name`, `=`, `"*.txt"`}, []string{`name`, `=`, `"*.txt"`}},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.words, " "), func(t *testing.T) {
+			if got := app.commandArgs(tt.mode, tt.words); !equalStringSlices(got, tt.want) {
+				t.Errorf("получены аргументы %q, ожидались %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("Команда с раскрытием шаблона", func(t *testing.T) {
+		// Корзина создается во временном домашнем каталоге
+		t.Setenv("HOME", tempDir)
+		captureOutput(func() {
+			if err := app.processCommand("rm *.md sub/*.txt"); err != nil {
+				t.Errorf("ошибка при выполнении rm: %v", err)
+			}
+		})
+		for _, name := range []string{"c.md", "sub/d.txt"} {
+			if _, err := os.Stat(filepath.Join(tempDir, name)); !os.IsNotExist(err) {
+				t.Errorf("файл %s должен быть удален по шаблону", name)
+			}
+		}
+	})
+
+	t.Run("Найденные имена не разворачиваются как сокращения", func(t *testing.T) {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		keep := filepath.Join(home, "keep.txt")
+		if err := os.WriteFile(keep, nil, 0644); err != nil {
+			t.Fatalf("не удалось создать файл: %v", err)
+		}
+		dir := filepath.Join(tempDir, "names")
+		if err := os.MkdirAll(filepath.Join(dir, "$HOME"), 0755); err != nil {
+			t.Fatalf("не удалось создать директорию: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "@x"), nil, 0644); err != nil {
+			t.Fatalf("не удалось создать файл: %v", err)
+		}
+		captureOutput(func() {
+			if err := app.processCommand("rm -r names/*"); err != nil {
+				t.Errorf("ошибка при выполнении rm: %v", err)
+			}
+		})
+		if _, err := os.Stat(keep); err != nil {
+			t.Errorf("домашняя директория не должна затрагиваться: %v", err)
+		}
+		for _, name := range []string{"$HOME", "@x"} {
+			if _, err := os.Lstat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+				t.Errorf("%s должен быть удален по шаблону", name)
+			}
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"file-manager/internal/fileops"
+	"file-manager/internal/i18n"
+)
+
+// cmdCompare сравнивает две директории:
+// compare [--shallow] [--content] [--json] [--jobs=N] <директория1> <директория2>
+func (a *App) cmdCompare(args []string) error {
+	var options fileops.CompareOptions
+	asJSON := false
+	paths := make([]string, 0, 2)
+	for _, arg := range args {
+		switch {
+		case arg == "--shallow":
+			options.Shallow = true
+		case arg == "-c" || arg == "--content":
+			options.Content = true
+		case arg == "--json":
+			asJSON = true
+		case strings.HasPrefix(arg, "--jobs="):
+			workers, err := parseJobs(strings.TrimPrefix(arg, "--jobs="))
+			if err != nil {
+				return err
+			}
+			options.Workers = workers
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			return fmt.Errorf(i18n.T("unknown_flag"), arg)
+		default:
+			path, err := a.resolvePath(arg)
+			if err != nil {
+				return err
+			}
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) != 2 {
+		return fmt.Errorf(i18n.T("args_expected_2"), len(paths))
+	}
+
+	result, err := fileops.Compare(paths[0], paths[1], options)
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	fmt.Print(a.display.FormatComparison(result))
+	return nil
+}
@@ -0,0 +1,156 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"file-manager/internal/fileops"
+	"file-manager/internal/i18n"
+	"file-manager/internal/navigation"
+)
+
+// setting описывает настройку, доступную через команду config
+type setting struct {
+	Key     string
+	Default string
+	// Validate проверяет значение перед сохранением
+	Validate func(value string) error
+}
+
+// settings — известные настройки; описание каждой хранится в переводах под ключом config_<имя>
+var settings = []setting{
+	{
+		Key:     "conflict",
+		Default: fileops.ConflictOverwrite.String(),
+		Validate: func(value string) error {
+			_, err := fileops.ParseConflictPolicy(value)
+			return err
+		},
+	},
+	{
+		Key:     "backup",
+		Default: fileops.BackupSimple.String(),
+		Validate: func(value string) error {
+			_, err := fileops.ParseBackupStyle(value)
+			return err
+		},
+	},
+	{
+		Key:     "jobs",
+		Default: "auto",
+		Validate: func(value string) error {
+			_, err := parseJobs(value)
+			return err
+		},
+	},
+	{
+		Key:     "buffer",
+		Default: "1MiB",
+		Validate: func(value string) error {
+			_, err := parseBufferSize(value)
+			return err
+		},
+	},
+}
+
+// maxCopyJobs и maxCopyBuffer ограничивают параметры копирования
+const (
+	maxCopyJobs   = 256
+	maxCopyBuffer = 1 << 30
+)
+
+// parseJobs разбирает число одновременно копируемых файлов; auto — выбор по числу ядер (0)
+func parseJobs(value string) (int, error) {
+	if strings.EqualFold(value, "auto") {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > maxCopyJobs {
+		return 0, fmt.Errorf(i18n.T("config_invalid_jobs"), value, maxCopyJobs)
+	}
+	return n, nil
+}
+
+// parseBufferSize разбирает размер буфера копирования, например 256K или 4MiB
+func parseBufferSize(value string) (int, error) {
+	size, err := navigation.ParseSize(value)
+	if err != nil {
+		return 0, err
+	}
+	if size < 1 || size > maxCopyBuffer {
+		return 0, fmt.Errorf(i18n.T("config_invalid_buffer"), value)
+	}
+	return int(size), nil
+}
+
+// findSetting возвращает описание настройки по ключу
+func findSetting(key string) (setting, bool) {
+	for _, s := range settings {
+		if s.Key == key {
+			return s, true
+		}
+	}
+	return setting{}, false
+}
+
+// setting возвращает значение настройки или ее значение по умолчанию
+func (a *App) setting(key string) string {
+	if value, ok := a.config.Get(key); ok {
+		if s, known := findSetting(key); !known || s.Validate(value) == nil {
+			return value
+		}
+	}
+	s, _ := findSetting(key)
+	return s.Default
+}
+
+// cmdConfig показывает и изменяет настройки:
+// config | config <ключ> | config <ключ> <значение> | config reset <ключ>
+func (a *App) cmdConfig(args []string) error {
+	switch {
+	case len(args) == 0:
+		for _, s := range settings {
+			a.printSetting(s)
+		}
+		return nil
+	case len(args) == 2 && args[0] == "reset":
+		if _, ok := findSetting(args[1]); !ok {
+			return fmt.Errorf(i18n.T("config_unknown_key"), args[1])
+		}
+		if err := a.config.Unset(args[1]); err != nil {
+			return err
+		}
+		fmt.Printf(i18n.T("config_reset")+"\n", args[1], a.setting(args[1]))
+		return nil
+	case len(args) > 2:
+		return fmt.Errorf(i18n.T("args_expected_max_2"), len(args))
+	}
+
+	s, ok := findSetting(args[0])
+	if !ok {
+		return fmt.Errorf(i18n.T("config_unknown_key"), args[0])
+	}
+	if len(args) == 1 {
+		a.printSetting(s)
+		return nil
+	}
+	value := strings.ToLower(strings.TrimSpace(args[1]))
+	if err := s.Validate(value); err != nil {
+		return err
+	}
+	if err := a.config.Set(s.Key, value); err != nil {
+		return err
+	}
+	fmt.Printf(i18n.T("config_set")+"\n", s.Key, value)
+	return nil
+}
+
+// printSetting выводит настройку с ее описанием
+func (a *App) printSetting(s setting) {
+	value := a.setting(s.Key)
+	if _, ok := a.config.Get(s.Key); !ok {
+		value += " (" + i18n.T("config_default") + ")"
+	}
+	fmt.Printf("  %-10s = %-24s %s\n", s.Key, value, i18n.T("config_"+s.Key))
+}
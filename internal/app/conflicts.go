@@ -0,0 +1,69 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"file-manager/internal/fileops"
+	"file-manager/internal/i18n"
+)
+
+// conflictAnswers — буквы ответов на вопрос о конфликте; заглавная буква применяет ответ ко всем файлам
+var conflictAnswers = map[string]fileops.ConflictPolicy{
+	"o": fileops.ConflictOverwrite,
+	"s": fileops.ConflictSkip,
+	"n": fileops.ConflictKeepNewer,
+	"r": fileops.ConflictRename,
+	"b": fileops.ConflictBackup,
+}
+
+// newConflicts создает правила разрешения конфликтов для одной команды по настройкам conflict и backup
+func (a *App) newConflicts() *fileops.Conflicts {
+	conflicts := &fileops.Conflicts{Ask: a.askConflict}
+	// Значения настроек проверены при чтении, поэтому ошибок разбора здесь нет
+	conflicts.Policy, _ = fileops.ParseConflictPolicy(a.setting("conflict"))
+	conflicts.Backup, _ = fileops.ParseBackupStyle(a.setting("backup"))
+	return conflicts
+}
+
+// takeConflictFlag разбирает флаг политики конфликтов cp и mv. Возвращает false, если arg им не является:
+// -n (--no-clobber) — пропускать, -u (--update) — оставлять более новый, -i (--interactive) — спрашивать,
+// -b и --backup[=<стиль>] — сохранять резервную копию, --conflict=<политика> — любая политика
+func takeConflictFlag(arg string, conflicts *fileops.Conflicts) (bool, error) {
+	switch arg {
+	case "-n", "--no-clobber":
+		conflicts.Policy = fileops.ConflictSkip
+	case "-u", "--update":
+		conflicts.Policy = fileops.ConflictKeepNewer
+	case "-i", "--interactive":
+		conflicts.Policy = fileops.ConflictAsk
+	case "-b", "--backup":
+		conflicts.Policy = fileops.ConflictBackup
+	default:
+		var err error
+		if value, ok := strings.CutPrefix(arg, "--conflict="); ok {
+			conflicts.Policy, err = fileops.ParseConflictPolicy(value)
+		} else if value, ok := strings.CutPrefix(arg, "--backup="); ok {
+			conflicts.Policy = fileops.ConflictBackup
+			conflicts.Backup, err = fileops.ParseBackupStyle(value)
+		} else {
+			return false, nil
+		}
+		return true, err
+	}
+	return true, nil
+}
+
+// askConflict спрашивает пользователя, что делать с существующим файлом назначения.
+// При окончании ввода оставшиеся конфликты пропускаются.
+func (a *App) askConflict(_, destination string) (fileops.ConflictAnswer, error) {
+	for {
+		answer, ok := a.ask(fmt.Sprintf(i18n.T("conflict_prompt"), destination))
+		if !ok {
+			return fileops.ConflictAnswer{Policy: fileops.ConflictSkip, All: true}, nil
+		}
+		if policy, known := conflictAnswers[strings.ToLower(answer)]; known && len(answer) == 1 {
+			return fileops.ConflictAnswer{Policy: policy, All: answer != strings.ToLower(answer)}, nil
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"file-manager/internal/fileops"
+	"file-manager/internal/i18n"
+	"file-manager/internal/ignore"
+	"file-manager/internal/navigation"
+)
+
+// cmdDupes ищет одинаковые файлы и при необходимости убирает лишние копии:
+// dupes [путь] [--min-size=<размер>] [--jobs=N] [--ignore] [--trash|--hardlink|--symlink] [--keep=oldest|newest|shortest] [-n] [-y]
+// Без действия выводятся только группы. С действием оставляемая копия отмечается «*»,
+// а перед выполнением запрашивается подтверждение; -n только показывает группы, -y не спрашивает.
+func (a *App) cmdDupes(args []string) error {
+	var options fileops.DupeOptions
+	var paths []string
+	action, keep := fileops.DupeAction(-1), fileops.DupeKeepOldest
+	dryRun, yes, respectIgnore := false, false, false
+	for _, arg := range args {
+		name, value, _ := strings.Cut(arg, "=")
+		var err error
+		switch {
+		case name == "--min-size":
+			options.MinSize, err = navigation.ParseSize(value)
+		case name == "--jobs":
+			options.Workers, err = parseJobs(value)
+		case name == "--keep":
+			keep, err = fileops.ParseDupeKeep(value)
+		case arg == "--ignore":
+			respectIgnore = true
+		case arg == "--trash" || arg == "--hardlink" || arg == "--symlink":
+			if action >= 0 {
+				return errors.New(i18n.T("dupes_one_action"))
+			}
+			action = map[string]fileops.DupeAction{
+				"--trash": fileops.DupeTrash, "--hardlink": fileops.DupeHardlink, "--symlink": fileops.DupeSymlink,
+			}[arg]
+		case arg == "-n" || arg == "--dry-run":
+			dryRun = true
+		case arg == "-y" || arg == "--yes":
+			yes = true
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			err = fmt.Errorf(i18n.T("unknown_flag"), arg)
+		default:
+			paths = append(paths, arg)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	root, err := a.commandRoot(paths)
+	if err != nil {
+		return err
+	}
+	if respectIgnore {
+		options.Rules = ignore.NewMatcher(root)
+	}
+	groups, err := a.fileOperator.FindDupes(root, options)
+	if err != nil {
+		return err
+	}
+	if action < 0 {
+		fmt.Print(a.display.FormatDupes(groups, ""))
+		return nil
+	}
+	fmt.Print(a.display.FormatDupes(groups, keep))
+	if len(groups) == 0 || dryRun {
+		return nil
+	}
+	if !yes {
+		extra := 0
+		for _, group := range groups {
+			extra += len(group.Files) - 1
+		}
+		answer, ok := a.ask(fmt.Sprintf(i18n.T("dupes_confirm"), extra))
+		if !ok || !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+			return nil
+		}
+	}
+	summary, err := a.fileOperator.Dedupe(groups, action, keep)
+	if err != nil {
+		return err
+	}
+	fmt.Print(a.display.FormatDedupe(summary))
+	return nil
+}
@@ -0,0 +1,265 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"file-manager/internal/i18n"
+	"file-manager/internal/navigation"
+	"file-manager/internal/sysinfo"
+)
+
+func (a *App) cmdFilter(args []string) error {
+	tab := a.tabs.Active()
+	if len(args) == 0 {
+		tab.SetFilter(navigation.NewFilterOptions(), "")
+		fmt.Println(i18n.T("filter_reset"))
+		return nil
+	}
+
+	switch args[0] {
+	case "save", "use", "delete", "pin", "unpin":
+		for i := 1; i < len(args); i++ {
+			args[i] = unquoteArg(args[i])
+		}
+	}
+
+	switch args[0] {
+	case "save":
+		if len(args) != 2 {
+			return fmt.Errorf(i18n.T("error"), i18n.T("filter_name_args"))
+		}
+		if !tab.Filter.IsActive() {
+			return errors.New(i18n.T("filter_not_active"))
+		}
+		if err := a.filterPresets.SavePreset(args[1], tab.Filter); err != nil {
+			return err
+		}
+		tab.FilterLabel = args[1]
+		fmt.Printf(i18n.T("filter_saved")+"\n", args[1])
+		return nil
+	case "use":
+		if len(args) != 2 {
+			return fmt.Errorf(i18n.T("error"), i18n.T("filter_name_args"))
+		}
+		options, err := a.filterPresets.GetPreset(args[1])
+		if err != nil {
+			return err
+		}
+		tab.SetFilter(options, args[1])
+		fmt.Println(i18n.T("filter_applied"))
+		return a.cmdListDir([]string{})
+	case "delete":
+		if len(args) != 2 {
+			return fmt.Errorf(i18n.T("error"), i18n.T("filter_name_args"))
+		}
+		return a.filterPresets.RemovePreset(args[1])
+	case "list":
+		a.listFilters()
+		return nil
+	case "pin", "unpin":
+		return a.pinFilter(args)
+	}
+
+	options, err := a.parseFilterArgs(args)
+	if err != nil {
+		return err
+	}
+	tab.SetFilter(options, "")
+	fmt.Println(i18n.T("filter_applied"))
+	return a.cmdListDir([]string{})
+}
+
+// parseFilterArgs разбирает флаги фильтра (--ext, --name, --iname, --size, --date, --type,
+// критерии по метаданным) и выражение фильтра из остальных аргументов
+func (a *App) parseFilterArgs(args []string) (*navigation.FilterOptions, error) {
+	newOptions := navigation.NewFilterOptions()
+	// Аргументы без префикса -- составляют выражение фильтра
+	var exprParts []string
+	for _, arg := range args {
+		// Команды фильтра получают аргументы с кавычками: выражение разбирается целиком,
+		// а с флагов кавычки снимаются здесь
+		if !strings.HasPrefix(arg, "--") {
+			exprParts = append(exprParts, arg)
+			continue
+		}
+		arg = unquoteArg(arg)
+		if strings.HasPrefix(arg, "--ext=") {
+			ext := strings.TrimPrefix(arg, "--ext=")
+			if ext != "" {
+				extensions := strings.Split(ext, ",")
+				newOptions.Extensions = extensions
+			}
+		} else if strings.HasPrefix(arg, "--name=") {
+			pattern := strings.TrimPrefix(arg, "--name=")
+			newOptions.NamePattern = pattern
+			newOptions.NameIgnoreCase = false
+		} else if strings.HasPrefix(arg, "--iname=") {
+			newOptions.NamePattern = strings.TrimPrefix(arg, "--iname=")
+			newOptions.NameIgnoreCase = true
+		} else if strings.HasPrefix(arg, "--size=") {
+			minSize, maxSize, err := navigation.ParseSizeRange(strings.TrimPrefix(arg, "--size="))
+			if err != nil {
+				return nil, err
+			}
+			newOptions.MinSize, newOptions.MaxSize = minSize, maxSize
+		} else if strings.HasPrefix(arg, "--date=") {
+			after, before, err := navigation.ParseDateRange(strings.TrimPrefix(arg, "--date="), time.Now())
+			if err != nil {
+				return nil, err
+			}
+			newOptions.ModifiedAfter, newOptions.ModifiedBefore = after, before
+		} else if strings.HasPrefix(arg, "--type=") {
+			types := strings.TrimPrefix(arg, "--type=")
+
+			// По умолчанию ничего не показываем
+			newOptions.ShowDirs = false
+			newOptions.ShowFiles = false
+			newOptions.ShowHidden = false
+
+			for _, t := range types {
+				switch t {
+				case 'd':
+					newOptions.ShowDirs = true
+				case 'f':
+					newOptions.ShowFiles = true
+				case 'h':
+					newOptions.ShowHidden = true
+				}
+			}
+		} else if err := a.parseMetadataFlag(arg, newOptions); err != nil {
+			return nil, err
+		}
+	}
+	if len(exprParts) > 0 {
+		newOptions.Expression = strings.Join(exprParts, " ")
+		if _, err := navigation.ParseQuery(newOptions.Expression); err != nil {
+			return nil, err
+		}
+	}
+	return newOptions, nil
+}
+
+// parseMetadataFlag разбирает флаг критерия по метаданным файла
+func (a *App) parseMetadataFlag(arg string, options *navigation.FilterOptions) error {
+	name, value, hasValue := strings.Cut(arg, "=")
+	valueFlag := name == "--owner" || name == "--group" || name == "--newer" || name == "--links"
+	if valueFlag && (!hasValue || value == "") || !valueFlag && hasValue {
+		return fmt.Errorf(i18n.T("filter_unknown_flag"), arg)
+	}
+	switch name {
+	case "--exec":
+		options.Executable = true
+	case "--world-writable":
+		options.WorldWritable = true
+	case "--setuid":
+		options.Setuid = true
+	case "--setgid":
+		options.Setgid = true
+	case "--symlink":
+		options.Symlink = true
+	case "--broken-symlink":
+		options.BrokenSymlink = true
+	case "--empty":
+		options.Empty = true
+	case "--owner":
+		if _, err := sysinfo.LookupUser(value); err != nil {
+			return err
+		}
+		options.Owner = value
+	case "--group":
+		if _, err := sysinfo.LookupGroup(value); err != nil {
+			return err
+		}
+		options.Group = value
+	case "--newer":
+		path, err := a.resolvePath(value)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf(i18n.T("filter_newer_reference"), value, err)
+		}
+		options.NewerThan = path
+	case "--links":
+		minLinks, maxLinks, err := navigation.ParseCountRange(value)
+		if err != nil {
+			return err
+		}
+		options.MinLinks, options.MaxLinks = minLinks, maxLinks
+	default:
+		return fmt.Errorf(i18n.T("filter_unknown_flag"), arg)
+	}
+	return nil
+}
+
+// listFilters выводит активный фильтр, сохраненные фильтры и закрепления
+func (a *App) listFilters() {
+	tab := a.tabs.Active()
+	if tab.Filter.IsActive() {
+		fmt.Printf(i18n.T("filter_current")+"\n", tab.Filter)
+	} else {
+		fmt.Println(i18n.T("filter_current_none"))
+	}
+
+	fmt.Println(i18n.T("filter_presets"))
+	for i, preset := range a.filterPresets.Presets {
+		fmt.Printf("%d. %s: %s\n", i+1, preset.Name, &preset.Options)
+	}
+
+	if len(a.filterPresets.Pins) > 0 {
+		fmt.Println(i18n.T("filter_pins"))
+		for i, pin := range a.filterPresets.Pins {
+			fmt.Printf("%d. %s: %s\n", i+1, pin.Dir, &pin.Options)
+		}
+	}
+}
+
+// pinFilter закрепляет активный фильтр за деревом директорий или снимает закрепление
+func (a *App) pinFilter(args []string) error {
+	dir, err := a.navigator().GetCurrentDirectory()
+	if err != nil {
+		return err
+	}
+	if len(args) >= 2 {
+		if dir, err = a.resolvePath(args[1]); err != nil {
+			return err
+		}
+	}
+
+	tab := a.tabs.Active()
+	if args[0] == "unpin" {
+		if err := a.filterPresets.Unpin(dir); err != nil {
+			return err
+		}
+		a.applyPinnedFilter()
+		fmt.Printf(i18n.T("filter_unpinned")+"\n", dir)
+		return nil
+	}
+
+	if !tab.Filter.IsActive() {
+		return errors.New(i18n.T("filter_not_active"))
+	}
+	pin, err := a.filterPresets.Pin(dir, tab.Filter)
+	if err != nil {
+		return err
+	}
+	cwd, err := a.navigator().GetCurrentDirectory()
+	if err == nil && a.filterPresets.PinFor(cwd) == pin {
+		tab.UsePin(pin)
+	}
+	fmt.Printf(i18n.T("filter_pinned")+"\n", pin.Dir)
+	return nil
+}
+
+// applyPinnedFilter применяет или снимает закрепленный фильтр после смены директории активной вкладки
+func (a *App) applyPinnedFilter() {
+	dir, err := a.navigator().GetCurrentDirectory()
+	if err != nil {
+		return
+	}
+	a.tabs.Active().ApplyPin(a.filterPresets.PinFor(dir))
+}
@@ -0,0 +1,148 @@
+package app
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"file-manager/internal/glob"
+	"file-manager/internal/i18n"
+	"file-manager/internal/search"
+)
+
+// cmdFindByName ищет записи в дереве директорий по тем же критериям, что и filter:
+// find [путь] [<шаблон> | критерии] [--maxdepth=N] [--mindepth=N] [--prune=<шаблон>] [--follow] [--xdev] [--ignore]
+func (a *App) cmdFindByName(args []string) error {
+	root, err := a.navigator().GetCurrentDirectory()
+	if err != nil {
+		return err
+	}
+	args, respectIgnore := takeFlag(args, "--ignore")
+	// Первый аргумент — путь поиска, если это существующая директория
+	if len(args) > 0 && !strings.HasPrefix(args[0], "--") {
+		if path, err := a.resolvePath(unquoteArg(args[0])); err == nil {
+			if info, err := os.Stat(path); err == nil && info.IsDir() {
+				root = path
+				args = args[1:]
+			}
+		}
+	}
+
+	findOptions, filterArgs, err := parseFindArgs(args)
+	if err != nil {
+		return err
+	}
+
+	// Один аргумент без операторов — шаблон имени, как в прежней версии find
+	if len(filterArgs) == 1 && !strings.HasPrefix(filterArgs[0], "--") && !strings.ContainsAny(filterArgs[0], "=<>~()") {
+		filterArgs[0] = "--name=" + filterArgs[0]
+	}
+	options, err := a.parseFilterArgs(filterArgs)
+	if err != nil {
+		return err
+	}
+	// В отличие от ls, find по умолчанию находит и скрытые записи
+	if !hasFlag(filterArgs, "--type") {
+		options.ShowHidden = true
+	}
+	matcher, err := options.Matcher()
+	if err != nil {
+		return err
+	}
+
+	results, err := a.searcher.WithIgnore(respectIgnore).Find(root, findOptions, func(path string, entry fs.DirEntry) bool {
+		return matcher.Match(entry, filepath.Dir(path))
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println(a.display.FormatSearchResults(results, strings.Join(args, " ")))
+	return nil
+}
+
+// parseFindArgs отделяет параметры обхода дерева от критериев фильтра
+func parseFindArgs(args []string) (search.FindOptions, []string, error) {
+	options := search.DefaultFindOptions()
+	var rest []string
+	for _, arg := range args {
+		name, value, _ := strings.Cut(unquoteArg(arg), "=")
+		var err error
+		switch name {
+		case "--maxdepth":
+			options.MaxDepth, err = parseDepth(arg, value)
+		case "--mindepth":
+			options.MinDepth, err = parseDepth(arg, value)
+		case "--prune":
+			if value == "" {
+				return options, nil, fmt.Errorf(i18n.T("filter_unknown_flag"), arg)
+			}
+			options.Prune = append(options.Prune, value)
+		case "--follow":
+			options.Follow = true
+		case "--xdev":
+			options.SameFilesystem = true
+		default:
+			rest = append(rest, arg)
+		}
+		if err != nil {
+			return options, nil, err
+		}
+	}
+	return options, rest, nil
+}
+
+// parseDepth разбирает неотрицательную глубину обхода
+func parseDepth(arg, value string) (int, error) {
+	depth, err := strconv.Atoi(value)
+	if err != nil || depth < 0 {
+		return 0, fmt.Errorf(i18n.T("find_invalid_depth"), arg)
+	}
+	return depth, nil
+}
+
+// hasFlag проверяет, передан ли флаг (с значением или без)
+func hasFlag(args []string, flag string) bool {
+	for _, arg := range args {
+		if arg == flag || strings.HasPrefix(arg, flag+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+// takeFlag удаляет из аргументов флаг без значения и сообщает, был ли он передан
+func takeFlag(args []string, flag string) ([]string, bool) {
+	rest := make([]string, 0, len(args))
+	found := false
+	for _, arg := range args {
+		if arg == flag {
+			found = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return rest, found
+}
+
+// takePatterns удаляет из аргументов флаги вида flag=<шаблон> (флаг можно указать несколько раз)
+// и возвращает скомпилированные шаблоны
+func takePatterns(args []string, flag string) ([]string, []*glob.Pattern, error) {
+	rest := make([]string, 0, len(args))
+	var patterns []*glob.Pattern
+	for _, arg := range args {
+		value, ok := strings.CutPrefix(arg, flag+"=")
+		if !ok {
+			rest = append(rest, arg)
+			continue
+		}
+		pattern, err := glob.Compile(value)
+		if err != nil {
+			return nil, nil, err
+		}
+		patterns = append(patterns, pattern)
+	}
+	return rest, patterns, nil
+}
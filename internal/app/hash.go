@@ -0,0 +1,117 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"file-manager/internal/fileops"
+	"file-manager/internal/i18n"
+)
+
+// cmdHash выводит контрольные суммы файлов в формате sha256sum или проверяет файлы по ним:
+// hash [--algo=md5|sha1|sha256|sha512|crc32] [--jobs=N] <файл>...
+// hash --check <файл сумм> — относительные пути отсчитываются от текущей директории, как в sha256sum
+func (a *App) cmdHash(args []string) error {
+	algorithm, check := "", ""
+	workers := 0
+	var paths []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, _ := strings.Cut(arg, "=")
+		var err error
+		switch {
+		case name == "--algo" || name == "-a":
+			algorithm, err = fileops.ParseHashAlgorithm(value)
+		case name == "--jobs":
+			workers, err = parseJobs(value)
+		case arg == "--check" || arg == "-c":
+			if i+1 >= len(args) {
+				return errors.New(i18n.T("hash_check_no_file"))
+			}
+			i++
+			check = args[i]
+		case name == "--check":
+			check = value
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			err = fmt.Errorf(i18n.T("unknown_flag"), arg)
+		default:
+			paths = append(paths, arg)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if check != "" {
+		if len(paths) > 0 {
+			return errors.New(i18n.T("hash_check_args"))
+		}
+		return a.checkHashes(check, algorithm, workers)
+	}
+	if len(paths) == 0 {
+		return errors.New(i18n.T("hash_no_files"))
+	}
+	if algorithm == "" {
+		algorithm = fileops.DefaultHashAlgorithm
+	}
+
+	resolved := make([]string, len(paths))
+	for i, path := range paths {
+		var err error
+		if resolved[i], err = a.resolvePath(path); err != nil {
+			return err
+		}
+	}
+	failed := 0
+	for i, result := range fileops.HashFiles(resolved, algorithm, workers) {
+		if result.Err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", a.shownPath(paths[i]), result.Err)
+			failed++
+			continue
+		}
+		// Путь выводится как введен (найденный по шаблону — относительно текущей директории),
+		// чтобы файл сумм можно было проверить из той же директории
+		fmt.Println(fileops.FormatChecksumLine(result.Sum, a.shownPath(paths[i])))
+	}
+	if failed > 0 {
+		return fmt.Errorf(i18n.T("hash_read_failed"), failed)
+	}
+	return nil
+}
+
+// checkHashes проверяет файлы по файлу контрольных сумм
+func (a *App) checkHashes(sumsPath, algorithm string, workers int) error {
+	path, err := a.resolvePath(sumsPath)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf(i18n.T("hash_open_error"), sumsPath, err)
+	}
+	defer func() { _ = file.Close() }()
+	entries, err := fileops.ParseChecksums(file, algorithm)
+	if err != nil {
+		return fmt.Errorf("%s: %w", sumsPath, err)
+	}
+	dir, err := a.navigator().GetCurrentDirectory()
+	if err != nil {
+		return err
+	}
+	checks := fileops.VerifyChecksums(entries, dir, workers)
+	fmt.Print(a.display.FormatChecksumChecks(checks))
+	var failed, missing int
+	for _, check := range checks {
+		switch check.Status {
+		case fileops.ChecksumFailed:
+			failed++
+		case fileops.ChecksumMissing:
+			missing++
+		}
+	}
+	if failed > 0 || missing > 0 {
+		return fmt.Errorf(i18n.T("hash_check_failed"), failed, missing, len(checks))
+	}
+	return nil
+}
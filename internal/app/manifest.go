@@ -0,0 +1,103 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"file-manager/internal/fileops"
+	"file-manager/internal/i18n"
+)
+
+// cmdManifest создает манифест дерева директорий или проверяет дерево по нему:
+// manifest create [--format=mtree|json] [--jobs=N] <директория> <файл>
+// manifest verify [--jobs=N] <директория> <файл>
+// Формат по умолчанию определяется по расширению файла: .json — JSON, иначе mtree.
+func (a *App) cmdManifest(args []string) error {
+	if len(args) == 0 || args[0] != "create" && args[0] != "verify" {
+		return errors.New(i18n.T("manifest_usage"))
+	}
+	var options fileops.ManifestOptions
+	format := ""
+	var paths []string
+	for _, arg := range args[1:] {
+		name, value, _ := strings.Cut(arg, "=")
+		var err error
+		switch {
+		case name == "--format" && args[0] == "create":
+			if value != fileops.ManifestMTree && value != fileops.ManifestJSON {
+				err = fmt.Errorf(i18n.T("manifest_bad_format"), value)
+			}
+			format = value
+		case name == "--jobs":
+			options.Workers, err = parseJobs(value)
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			err = fmt.Errorf(i18n.T("unknown_flag"), arg)
+		default:
+			var path string
+			if path, err = a.resolvePath(arg); err == nil {
+				paths = append(paths, path)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if len(paths) != 2 {
+		return fmt.Errorf(i18n.T("args_expected_2"), len(paths))
+	}
+	dir, manifestPath := paths[0], paths[1]
+	// Файл манифеста внутри проверяемого дерева в манифест не попадает
+	options.Exclude = []string{manifestPath}
+
+	if args[0] == "verify" {
+		return a.verifyManifest(dir, manifestPath, options)
+	}
+	if format == "" {
+		format = fileops.ManifestMTree
+		if strings.EqualFold(filepath.Ext(manifestPath), ".json") {
+			format = fileops.ManifestJSON
+		}
+	}
+	manifest, err := fileops.CreateManifest(dir, options)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(manifestPath)
+	if err != nil {
+		return fmt.Errorf(i18n.T("manifest_write_error"), manifestPath, err)
+	}
+	err = manifest.Write(file, format)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf(i18n.T("manifest_write_error"), manifestPath, err)
+	}
+	fmt.Printf(i18n.T("manifest_created")+"\n", manifestPath, len(manifest.Entries))
+	return nil
+}
+
+// verifyManifest сравнивает дерево с манифестом и выводит расхождения
+func (a *App) verifyManifest(dir, manifestPath string, options fileops.ManifestOptions) error {
+	file, err := os.Open(manifestPath)
+	if err != nil {
+		return fmt.Errorf(i18n.T("hash_open_error"), manifestPath, err)
+	}
+	manifest, err := fileops.ReadManifest(file)
+	_ = file.Close()
+	if err != nil {
+		return fmt.Errorf("%s: %w", manifestPath, err)
+	}
+	changes, err := fileops.VerifyManifest(dir, manifest, options)
+	if err != nil {
+		return err
+	}
+	fmt.Print(a.display.FormatManifestChanges(changes))
+	if len(changes) > 0 {
+		return fmt.Errorf(i18n.T("manifest_verify_failed"), len(changes))
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"file-manager/internal/fileops"
+	"file-manager/internal/i18n"
+)
+
+// cmdRename переименовывает пакет файлов по правилам:
+// rename [-n] [-y] ['s/шаблон/замена/флаги'] [--template=<шаблон>] [--start=N] [--case=<регистр>] [--ext=<расширение>] <файл>...
+// rename --undo отменяет последний пакет. Перед выполнением показывается список изменений
+// и запрашивается подтверждение; -n только показывает список, -y не спрашивает.
+func (a *App) cmdRename(args []string) error {
+	var rules []fileops.RenameRule
+	var paths []string
+	dryRun, yes, undo := false, false, false
+	start := 1
+	// Шаблон разбирается после всех флагов, чтобы --start действовал независимо от порядка
+	templateAt, template := -1, ""
+	for _, arg := range args {
+		var rule fileops.RenameRule
+		var err error
+		switch {
+		case arg == "-n" || arg == "--dry-run":
+			dryRun = true
+		case arg == "-y" || arg == "--yes":
+			yes = true
+		case arg == "--undo":
+			undo = true
+		case strings.HasPrefix(arg, "--template="):
+			templateAt, template = len(rules), strings.TrimPrefix(arg, "--template=")
+			rules = append(rules, nil)
+		case strings.HasPrefix(arg, "--start="):
+			start, err = strconv.Atoi(strings.TrimPrefix(arg, "--start="))
+			if err != nil || start < 0 {
+				return fmt.Errorf(i18n.T("rename_bad_start"), strings.TrimPrefix(arg, "--start="))
+			}
+		case strings.HasPrefix(arg, "--case="):
+			rule, err = fileops.ParseCase(strings.TrimPrefix(arg, "--case="))
+		case strings.HasPrefix(arg, "--ext="):
+			rule = fileops.ExtensionRule(strings.TrimPrefix(arg, "--ext="))
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			return fmt.Errorf(i18n.T("unknown_flag"), arg)
+		case len(paths) == 0 && fileops.IsSubstitution(arg):
+			rule, err = fileops.ParseSubstitution(arg)
+		default:
+			path, err := a.resolvePath(arg)
+			if err != nil {
+				return err
+			}
+			paths = append(paths, path)
+		}
+		if err != nil {
+			return err
+		}
+		if rule != nil {
+			rules = append(rules, rule)
+		}
+	}
+	if templateAt >= 0 {
+		rule, err := fileops.ParseTemplate(template, start)
+		if err != nil {
+			return err
+		}
+		rules[templateAt] = rule
+	}
+
+	if undo {
+		if len(rules) > 0 || len(paths) > 0 {
+			return errors.New(i18n.T("rename_undo_args"))
+		}
+		return a.undoRename(dryRun, yes)
+	}
+	if len(rules) == 0 {
+		return errors.New(i18n.T("rename_no_rules"))
+	}
+	if len(paths) == 0 {
+		return errors.New(i18n.T("rename_no_files"))
+	}
+	plan, err := fileops.PlanRename(paths, rules)
+	if err != nil {
+		return err
+	}
+	if !a.confirmRename(plan, dryRun, yes) {
+		return nil
+	}
+	if err := a.fileOperator.Rename(plan); err != nil {
+		return err
+	}
+	fmt.Printf(i18n.T("rename_done")+"\n", len(plan.Items))
+	return a.renameHistory.Push(plan)
+}
+
+// undoRename отменяет последний пакет переименований
+func (a *App) undoRename(dryRun, yes bool) error {
+	last, err := a.renameHistory.Last()
+	if err != nil {
+		return err
+	}
+	plan, err := last.Undo()
+	if err != nil {
+		return err
+	}
+	if !a.confirmRename(plan, dryRun, yes) {
+		return nil
+	}
+	if err := a.fileOperator.Rename(plan); err != nil {
+		return err
+	}
+	fmt.Printf(i18n.T("rename_undone")+"\n", len(plan.Items))
+	return a.renameHistory.Pop()
+}
+
+// confirmRename выводит список изменений и спрашивает, выполнять ли их
+func (a *App) confirmRename(plan *fileops.RenamePlan, dryRun, yes bool) bool {
+	if len(plan.Items) == 0 {
+		fmt.Println(i18n.T("rename_nothing"))
+		return false
+	}
+	dir, _ := a.navigator().GetCurrentDirectory()
+	for _, item := range plan.Items {
+		from := item.From
+		if rel, err := filepath.Rel(dir, item.From); err == nil && !strings.HasPrefix(rel, "..") {
+			from = rel
+		}
+		fmt.Printf("  %s → %s\n", from, filepath.Base(item.To))
+	}
+	if plan.Cycles > 0 {
+		fmt.Printf(i18n.T("rename_cycles")+"\n", plan.Cycles)
+	}
+	if dryRun {
+		return false
+	}
+	if yes {
+		return true
+	}
+	answer, ok := a.ask(fmt.Sprintf(i18n.T("rename_confirm"), len(plan.Items)))
+	return ok && (strings.EqualFold(answer, "y") || strings.EqualFold(answer, "yes"))
+}
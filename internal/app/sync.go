@@ -0,0 +1,59 @@
+package app
+
+import (
+	"fmt"
+
+	"file-manager/internal/fileops"
+	"file-manager/internal/i18n"
+)
+
+// cmdSync делает назначение копией источника, передавая только различия:
+// sync [-n] [--delete] [--trash] [--checksum] [--exclude=<шаблон>] <источник> <назначение>
+func (a *App) cmdSync(args []string) error {
+	args, exclude, err := takePatterns(args, "--exclude")
+	if err != nil {
+		return err
+	}
+	options := fileops.SyncOptions{Exclude: exclude}
+	dryRun := false
+	paths := make([]string, 0, 2)
+	for _, arg := range args {
+		switch arg {
+		case "-n", "--dry-run":
+			dryRun = true
+		case "--delete":
+			options.Delete = true
+		case "--trash":
+			options.Trash = true
+		case "-c", "--checksum":
+			options.Checksum = true
+		default:
+			if len(arg) > 1 && arg[0] == '-' {
+				return fmt.Errorf(i18n.T("unknown_flag"), arg)
+			}
+			path, err := a.resolvePath(arg)
+			if err != nil {
+				return err
+			}
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) != 2 {
+		return fmt.Errorf(i18n.T("args_expected_2"), len(paths))
+	}
+
+	plan, err := fileops.PlanSync(paths[0], paths[1], options)
+	if err != nil {
+		return err
+	}
+	if len(plan.Items) == 0 {
+		fmt.Println(i18n.T("sync_up_to_date"))
+		return nil
+	}
+	fmt.Print(a.display.FormatSyncPlan(plan))
+	if dryRun {
+		fmt.Println(i18n.T("sync_dry_run"))
+		return nil
+	}
+	return a.fileOperator.Sync(plan, options)
+}
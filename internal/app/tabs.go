@@ -0,0 +1,72 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+
+	"file-manager/internal/i18n"
+)
+
+func (a *App) cmdTab(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf(i18n.T("error"), i18n.T("tab_args"))
+	}
+	switch args[0] {
+	case "new":
+		dir, err := a.navigator().GetCurrentDirectory()
+		if err != nil {
+			return err
+		}
+		if len(args) >= 2 {
+			dir, err = a.resolvePath(args[1])
+			if err != nil {
+				return err
+			}
+		}
+		if _, err := a.tabs.NewTab(dir); err != nil {
+			return err
+		}
+		fmt.Printf(i18n.T("tab_opened")+"\n", a.tabs.ActiveIndex()+1, dir)
+		return nil
+	case "list":
+		fmt.Println(i18n.T("tab_list"))
+		for i, tab := range a.tabs.Tabs() {
+			marker := " "
+			if i == a.tabs.ActiveIndex() {
+				marker = "*"
+			}
+			fmt.Printf("%s %d. %s\n", marker, i+1, tab.Navigator.CurrentDir)
+		}
+		return nil
+	case "switch":
+		if len(args) < 2 {
+			return fmt.Errorf(i18n.T("error"), i18n.T("tab_index_args"))
+		}
+		index, err := parseTabIndex(args[1])
+		if err != nil {
+			return err
+		}
+		return a.tabs.Switch(index)
+	case "close":
+		index := a.tabs.ActiveIndex()
+		if len(args) >= 2 {
+			var err error
+			index, err = parseTabIndex(args[1])
+			if err != nil {
+				return err
+			}
+		}
+		return a.tabs.Close(index)
+	default:
+		return fmt.Errorf(i18n.T("error"), fmt.Sprintf(i18n.T("tab_unknown"), args[0]))
+	}
+}
+
+// parseTabIndex преобразует номер вкладки (с единицы) в индекс (с нуля)
+func parseTabIndex(arg string) (int, error) {
+	number, err := strconv.Atoi(arg)
+	if err != nil {
+		return 0, fmt.Errorf(i18n.T("tab_invalid_index"), arg)
+	}
+	return number - 1, nil
+}
@@ -0,0 +1,156 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"file-manager/internal/fileops"
+	"file-manager/internal/glob"
+	"file-manager/internal/i18n"
+	"file-manager/internal/navigation"
+)
+
+// cmdTrashList выводит записи корзины с исходным путем, временем удаления и размером:
+// trash-list [--path=<префикс>] [--glob=<шаблон>] [--date=<диапазон>]
+// Номера записей не зависят от фильтра, поэтому их можно передать restore.
+func (a *App) cmdTrashList(args []string) error {
+	var filter fileops.TrashFilter
+	for _, arg := range args {
+		name, value, _ := strings.Cut(arg, "=")
+		var err error
+		switch name {
+		case "--path":
+			filter.Prefix, err = a.resolvePath(value)
+		case "--glob":
+			filter.Pattern, err = glob.Compile(value)
+		case "--date":
+			filter.After, filter.Before, err = navigation.ParseDateRange(value, time.Now())
+		default:
+			return fmt.Errorf(i18n.T("unknown_flag"), arg)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	entries, err := a.fileOperator.SoftDeleter.ListTrash()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println(i18n.T("trash_empty_already"))
+		return nil
+	}
+	var matched []fileops.TrashEntry
+	var numbers []int
+	for i, entry := range entries {
+		if filter.Match(entry) {
+			matched = append(matched, entry)
+			numbers = append(numbers, i+1)
+		}
+	}
+	if len(matched) == 0 {
+		fmt.Println(i18n.T("trash_no_matches"))
+		return nil
+	}
+	fmt.Println(i18n.T("trash_contents"))
+	fmt.Print(a.display.FormatTrash(matched, numbers))
+	return nil
+}
+
+// cmdRestoreFromTrash возвращает записи из корзины на исходное место или в другую директорию:
+// restore [--to=<директория>] [-n|-u|-i|-b|--backup=<стиль>|--conflict=<политика>] <путь|номер|имя>...
+// Если место занято, по умолчанию запись восстанавливается под свободным именем (rename-new).
+func (a *App) cmdRestoreFromTrash(args []string) error {
+	conflicts := a.newConflicts()
+	conflicts.Policy = fileops.ConflictRename
+	var targetDir string
+	var names []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--to" && i+1 < len(args) {
+			i++
+			targetDir = args[i]
+			continue
+		}
+		if value, ok := strings.CutPrefix(arg, "--to="); ok {
+			targetDir = value
+			continue
+		}
+		isFlag, err := takeConflictFlag(arg, conflicts)
+		if err != nil {
+			return err
+		}
+		if !isFlag {
+			names = append(names, arg)
+		}
+	}
+	if len(names) == 0 {
+		return fmt.Errorf(i18n.T("args_expected_1"), len(names))
+	}
+	if targetDir != "" {
+		dir, err := a.resolvePath(targetDir)
+		if err != nil {
+			return err
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return fmt.Errorf(i18n.T("dest_not_dir"), targetDir)
+		}
+		targetDir = dir
+	}
+
+	entries, err := a.fileOperator.SoftDeleter.ListTrash()
+	if err != nil {
+		return err
+	}
+	// Все записи находятся до восстановления, чтобы номера не сдвигались
+	selected := make([]fileops.TrashEntry, 0, len(names))
+	for _, name := range names {
+		entry, err := a.findTrashEntry(entries, name)
+		if err != nil {
+			return err
+		}
+		selected = append(selected, entry)
+	}
+	for _, entry := range selected {
+		destination := ""
+		if targetDir != "" {
+			destination = filepath.Join(targetDir, filepath.Base(entry.DisplayPath()))
+		}
+		restored, err := a.fileOperator.SoftDeleter.RestoreFromTrash(entry, destination, conflicts)
+		if err != nil {
+			return err
+		}
+		if restored == "" {
+			fmt.Printf(i18n.T("trash_restore_skipped")+"\n", entry.DisplayPath())
+			continue
+		}
+		fmt.Printf(i18n.T("file_restored")+"\n", restored)
+	}
+	return nil
+}
+
+// findTrashEntry находит запись корзины по номеру из trash-list, исходному пути или имени в корзине.
+// Если по одному пути удалено несколько записей, выбирается удаленная последней.
+func (a *App) findTrashEntry(entries []fileops.TrashEntry, arg string) (fileops.TrashEntry, error) {
+	if n, err := strconv.Atoi(arg); err == nil && n >= 1 && n <= len(entries) {
+		return entries[n-1], nil
+	}
+	if path, err := a.resolvePath(arg); err == nil {
+		// Записи упорядочены по времени удаления
+		for i := len(entries) - 1; i >= 0; i-- {
+			if entries[i].OriginalPath == path {
+				return entries[i], nil
+			}
+		}
+	}
+	for _, entry := range entries {
+		if entry.Name == arg {
+			return entry, nil
+		}
+	}
+	return fileops.TrashEntry{}, fmt.Errorf(i18n.T("softdelete_not_in_trash"), arg)
+}
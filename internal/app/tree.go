@@ -0,0 +1,104 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"file-manager/internal/display"
+	"file-manager/internal/i18n"
+	"file-manager/internal/ignore"
+)
+
+// cmdTree выводит дерево директорий: tree [путь] [--depth=N] [--all] [--dirs] [--ignore]
+func (a *App) cmdTree(args []string) error {
+	options := display.TreeOptions{MaxDepth: -1}
+	var respectIgnore bool
+	var paths []string
+	for _, arg := range args {
+		name, value, _ := strings.Cut(arg, "=")
+		var err error
+		switch {
+		case name == "--depth":
+			options.MaxDepth, err = parseDepth(arg, value)
+		case arg == "--all":
+			options.ShowHidden = true
+		case arg == "--dirs":
+			options.DirsOnly = true
+		case arg == "--ignore":
+			respectIgnore = true
+		case strings.HasPrefix(arg, "--"):
+			err = fmt.Errorf(i18n.T("unknown_flag"), arg)
+		default:
+			paths = append(paths, arg)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	root, err := a.commandRoot(paths)
+	if err != nil {
+		return err
+	}
+	if respectIgnore {
+		options.Ignore = ignore.NewMatcher(root)
+	}
+	tree, err := a.display.FormatTree(root, options)
+	if err != nil {
+		return err
+	}
+	fmt.Print(tree)
+	return nil
+}
+
+// cmdDiskUsage выводит размеры директорий: du [путь] [--depth=N] [--ignore].
+// По умолчанию показываются корень и его непосредственные поддиректории.
+func (a *App) cmdDiskUsage(args []string) error {
+	maxDepth := 1
+	var respectIgnore bool
+	var paths []string
+	for _, arg := range args {
+		name, value, _ := strings.Cut(arg, "=")
+		var err error
+		switch {
+		case name == "--depth":
+			maxDepth, err = parseDepth(arg, value)
+		case arg == "--ignore":
+			respectIgnore = true
+		case strings.HasPrefix(arg, "--"):
+			err = fmt.Errorf(i18n.T("unknown_flag"), arg)
+		default:
+			paths = append(paths, arg)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	root, err := a.commandRoot(paths)
+	if err != nil {
+		return err
+	}
+	var rules *ignore.Matcher
+	if respectIgnore {
+		rules = ignore.NewMatcher(root)
+	}
+	usage, err := a.fileOperator.DiskUsage(root, maxDepth, rules)
+	if err != nil {
+		return err
+	}
+	fmt.Print(a.display.FormatDiskUsage(usage))
+	return nil
+}
+
+// commandRoot возвращает директорию, с которой работает команда: переданный путь или текущую директорию
+func (a *App) commandRoot(paths []string) (string, error) {
+	switch len(paths) {
+	case 0:
+		return a.navigator().GetCurrentDirectory()
+	case 1:
+		return a.resolvePath(paths[0])
+	default:
+		return "", fmt.Errorf(i18n.T("args_expected_max_1"), len(paths))
+	}
+}
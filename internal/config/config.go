@@ -0,0 +1,107 @@
+// Package config хранит пользовательские настройки файлового менеджера в ~/.filemanager/config.json.
+// Пакет не знает смысла настроек: значения по умолчанию и проверку задает приложение.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"file-manager/internal/i18n"
+)
+
+// Config — набор настроек вида ключ = значение
+type Config struct {
+	Values     map[string]string
+	ConfigFile string
+}
+
+// NewConfig загружает настройки из ~/.filemanager/config.json
+func NewConfig() (*Config, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("bm_home"), err)
+	}
+
+	configDir := filepath.Join(homeDir, ".filemanager")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return nil, fmt.Errorf(i18n.T("bm_dir"), err)
+	}
+
+	c := &Config{
+		Values:     map[string]string{},
+		ConfigFile: filepath.Join(configDir, "config.json"),
+	}
+	if err := c.LoadConfig(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Get возвращает значение настройки и признак того, что она задана
+func (c *Config) Get(key string) (string, bool) {
+	value, ok := c.Values[key]
+	return value, ok
+}
+
+// Set задает значение настройки и сохраняет файл
+func (c *Config) Set(key, value string) error {
+	if c.Values == nil {
+		c.Values = map[string]string{}
+	}
+	c.Values[key] = value
+	return c.SaveConfig()
+}
+
+// Unset удаляет настройку, возвращая ей значение по умолчанию, и сохраняет файл
+func (c *Config) Unset(key string) error {
+	if _, ok := c.Values[key]; !ok {
+		return nil
+	}
+	delete(c.Values, key)
+	return c.SaveConfig()
+}
+
+// Keys возвращает заданные ключи в алфавитном порядке
+func (c *Config) Keys() []string {
+	keys := make([]string, 0, len(c.Values))
+	for key := range c.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SaveConfig сохраняет настройки в файл
+func (c *Config) SaveConfig() error {
+	data, err := json.MarshalIndent(c.Values, "", "  ")
+	if err != nil {
+		return fmt.Errorf(i18n.T("config_marshal"), err)
+	}
+	if err := os.WriteFile(c.ConfigFile, data, 0644); err != nil {
+		return fmt.Errorf(i18n.T("config_write"), err)
+	}
+	return nil
+}
+
+// LoadConfig загружает настройки из файла (отсутствующий файл не является ошибкой)
+func (c *Config) LoadConfig() error {
+	data, err := os.ReadFile(c.ConfigFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf(i18n.T("config_read"), err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	values := map[string]string{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf(i18n.T("config_unmarshal"), err)
+	}
+	c.Values = values
+	return nil
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.json")
+	c := &Config{ConfigFile: file}
+
+	t.Run("Отсутствующий файл", func(t *testing.T) {
+		if err := c.LoadConfig(); err != nil {
+			t.Fatalf("отсутствующий файл не должен быть ошибкой: %v", err)
+		}
+		if _, ok := c.Get("conflict"); ok {
+			t.Error("настройка не должна быть задана")
+		}
+	})
+
+	t.Run("Сохранение и загрузка", func(t *testing.T) {
+		if err := c.Set("conflict", "skip"); err != nil {
+			t.Fatalf("ошибка сохранения: %v", err)
+		}
+		if err := c.Set("backup", "numbered"); err != nil {
+			t.Fatalf("ошибка сохранения: %v", err)
+		}
+		loaded := &Config{ConfigFile: file}
+		if err := loaded.LoadConfig(); err != nil {
+			t.Fatalf("ошибка загрузки: %v", err)
+		}
+		if value, ok := loaded.Get("conflict"); !ok || value != "skip" {
+			t.Errorf("ожидалось conflict = skip, получено %q", value)
+		}
+		if got := strings.Join(loaded.Keys(), ","); got != "backup,conflict" {
+			t.Errorf("неверный список ключей: %s", got)
+		}
+	})
+
+	t.Run("Удаление", func(t *testing.T) {
+		if err := c.Unset("backup"); err != nil {
+			t.Fatalf("ошибка удаления: %v", err)
+		}
+		if err := c.Unset("missing"); err != nil {
+			t.Errorf("удаление незаданной настройки не должно быть ошибкой: %v", err)
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("не удалось прочитать файл: %v", err)
+		}
+		if strings.Contains(string(data), "backup") {
+			t.Errorf("удаленная настройка осталась в файле:\n%s", data)
+		}
+	})
+
+	t.Run("Поврежденный файл", func(t *testing.T) {
+		if err := os.WriteFile(file, []byte("{"), 0644); err != nil {
+			t.Fatalf("не удалось записать файл: %v", err)
+		}
+		if err := (&Config{ConfigFile: file}).LoadConfig(); err == nil {
+			t.Error("ожидалась ошибка для поврежденного файла")
+		}
+	})
+}
@@ -22,6 +22,9 @@ type FileInfo struct {
 	LastModified time.Time
 	CreatedAt    time.Time
 	IsExecutable bool
+	// Hash — контрольная сумма по алгоритму HashAlgorithm; выводится, если задана
+	Hash          string
+	HashAlgorithm string
 }
 
 // Display предоставляет функции для отображения информации о файлах
@@ -103,6 +106,9 @@ func (d *Display) FormatFileInfo(fileInfo *FileInfo) string {
 	if fileInfo.IsExecutable {
 		sb.WriteString(i18n.T("executable") + "\n")
 	}
+	if fileInfo.Hash != "" {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", strings.ToUpper(fileInfo.HashAlgorithm), fileInfo.Hash))
+	}
 
 	return sb.String()
 }
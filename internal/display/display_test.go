@@ -1,12 +1,14 @@
 package display
 
 import (
+	"file-manager/internal/fileops"
 	"file-manager/internal/i18n"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestDisplay(t *testing.T) {
@@ -158,6 +160,32 @@ func TestDisplay(t *testing.T) {
 		}
 	})
 
+	t.Run("FormatTree", func(t *testing.T) {
+		plain := &Display{UseColors: false}
+		tree, err := plain.FormatTree(tempDir, TreeOptions{MaxDepth: -1})
+		if err != nil {
+			t.Fatalf("ошибка при построении дерева: %v", err)
+		}
+		if !strings.Contains(tree, "├── archive.zip") || !strings.Contains(tree, "└── test.txt") {
+			t.Errorf("дерево не содержит ожидаемых ветвей:\n%s", tree)
+		}
+		if !strings.Contains(tree, fmt.Sprintf(i18n.T("tree_summary"), 1, 4)) {
+			t.Errorf("дерево не содержит итог:\n%s", tree)
+		}
+
+		tree, err = plain.FormatTree(tempDir, TreeOptions{MaxDepth: -1, DirsOnly: true})
+		if err != nil {
+			t.Fatalf("ошибка при построении дерева: %v", err)
+		}
+		if strings.Contains(tree, "test.txt") || !strings.Contains(tree, "└── subdir") {
+			t.Errorf("с DirsOnly должны выводиться только директории:\n%s", tree)
+		}
+
+		if _, err := plain.FormatTree(filepath.Join(tempDir, "test.txt"), TreeOptions{MaxDepth: -1}); err == nil {
+			t.Error("ожидалась ошибка для файла вместо директории")
+		}
+	})
+
 	// Тест на переключение цветов
 	t.Run("ToggleColors", func(t *testing.T) {
 		// Вместо теста на переключение цветов, просто убедимся, что функция работает
@@ -273,3 +301,148 @@ func TestIsDirectory(t *testing.T) {
 		t.Error("ожидалась ошибка для несуществующего пути")
 	}
 }
+
+func TestFormatSyncPlan(t *testing.T) {
+	plan := &fileops.SyncPlan{
+		Destination: "/backup",
+		Items: []fileops.SyncItem{
+			{Action: fileops.SyncCreate, Path: "docs", IsDir: true},
+			{Action: fileops.SyncCreate, Path: filepath.Join("docs", "a.txt"), Size: 2048},
+			{Action: fileops.SyncUpdate, Path: "b.txt", Size: 10},
+			{Action: fileops.SyncDelete, Path: "old.txt"},
+		},
+	}
+	d := NewDisplay()
+	d.UseColors = false
+	output := d.FormatSyncPlan(plan)
+	for _, want := range []string{
+		"+ docs" + string(filepath.Separator) + "\n",
+		"+ " + filepath.Join("docs", "a.txt"),
+		"~ b.txt",
+		"- old.txt",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("в выводе нет %q:\n%s", want, output)
+		}
+	}
+	if summary := plan.Summary(); summary.Created != 2 || summary.Updated != 1 || summary.Deleted != 1 || summary.Bytes != 2058 {
+		t.Errorf("неверный итог: %+v", summary)
+	}
+}
+
+func TestFormatComparison(t *testing.T) {
+	d := NewDisplay()
+	d.UseColors = false
+	result := &fileops.CompareResult{
+		Entries: []fileops.CompareEntry{
+			{Path: "backup", Status: fileops.CompareLeftOnly, LeftType: "dir"},
+			{Path: "new.txt", Status: fileops.CompareRightOnly, RightType: "file"},
+			{Path: "a.txt", Status: fileops.CompareDiffers, Reasons: []string{"size", "mtime"}},
+			{Path: "kind", Status: fileops.CompareTypeMismatch, LeftType: "file", RightType: "dir"},
+		},
+		Summary: fileops.CompareSummary{LeftOnly: 1, RightOnly: 1, Differs: 1, TypeMismatch: 1},
+	}
+	output := d.FormatComparison(result)
+	for _, want := range []string{
+		"< backup" + string(filepath.Separator) + "\n",
+		"> new.txt\n",
+		"~ a.txt (size, mtime)",
+		"! kind (file / dir)",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("в выводе нет %q:\n%s", want, output)
+		}
+	}
+	if output := d.FormatComparison(&fileops.CompareResult{}); !strings.Contains(output, "compare_equal") {
+		t.Errorf("для одинаковых директорий ожидалось сообщение о совпадении: %q", output)
+	}
+}
+
+func TestFormatDupes(t *testing.T) {
+	d := NewDisplay()
+	d.UseColors = false
+	now := time.Now()
+	groups := []fileops.DupeGroup{{
+		Size: 2048,
+		Files: []fileops.DupeFile{
+			{Path: "/data/a.bin", ModTime: now},
+			{Path: "/data/copy/a.bin", ModTime: now.Add(-time.Hour)},
+			{Path: "/b.bin", ModTime: now.Add(time.Hour)},
+		},
+	}}
+	output := d.FormatDupes(groups, fileops.DupeKeepOldest)
+	for _, want := range []string{"  * /data/copy/a.bin\n", "    /data/a.bin\n", "    /b.bin\n"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("в выводе нет %q:\n%s", want, output)
+		}
+	}
+	if output := d.FormatDupes(groups, ""); strings.Contains(output, "*") {
+		t.Errorf("без правила копия не отмечается:\n%s", output)
+	}
+	if groups[0].Wasted() != 4096 {
+		t.Errorf("неверное лишнее место: %d", groups[0].Wasted())
+	}
+	if output := d.FormatDupes(nil, ""); !strings.Contains(output, "dupes_none") {
+		t.Errorf("ожидалось сообщение об отсутствии дубликатов: %q", output)
+	}
+}
+
+func TestFormatChecksumChecks(t *testing.T) {
+	d := NewDisplay()
+	d.UseColors = false
+	output := d.FormatChecksumChecks([]fileops.ChecksumCheck{
+		{Path: "a.txt", Status: fileops.ChecksumOK},
+		{Path: "b.txt", Status: fileops.ChecksumFailed},
+		{Path: "c.txt", Status: fileops.ChecksumMissing},
+	})
+	if output != "a.txt: OK\nb.txt: FAILED\nc.txt: MISSING\n" {
+		t.Errorf("неверный вывод:\n%s", output)
+	}
+	if output := d.FormatChecksumChecks([]fileops.ChecksumCheck{{Path: "a.txt", Status: fileops.ChecksumOK}}); !strings.Contains(output, "hash_check_ok") {
+		t.Errorf("если все файлы совпали, выводится итог: %q", output)
+	}
+}
+
+func TestFormatManifestChanges(t *testing.T) {
+	d := NewDisplay()
+	d.UseColors = false
+	uid, gid, root := uint32(1000), uint32(1000), uint32(0)
+	changes := []fileops.ManifestChange{
+		{Path: "a.txt", Kind: fileops.ManifestAdded},
+		{Path: "b.txt", Kind: fileops.ManifestRemoved},
+		{Path: "c.txt", Kind: fileops.ManifestModified, Reasons: []string{"size", "content"}},
+		{
+			Path: "run.sh", Kind: fileops.ManifestPermissions, Reasons: []string{"mode", "owner"},
+			Old: &fileops.ManifestEntry{Type: "file", Mode: "0644", UID: &uid, GID: &gid},
+			New: &fileops.ManifestEntry{Type: "file", Mode: "0755", UID: &root, GID: &root},
+		},
+	}
+	output := d.FormatManifestChanges(changes)
+	for _, want := range []string{
+		"+ a.txt\n", "- b.txt\n", "~ c.txt (size, content)\n",
+		"! run.sh (-rw-r--r-- 0644 1000:1000 → -rwxr-xr-x 0755 0:0)\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("в выводе нет %q:\n%s", want, output)
+		}
+	}
+	if output := d.FormatManifestChanges(nil); !strings.Contains(output, "manifest_ok") {
+		t.Errorf("без расхождений ожидалось сообщение о совпадении: %q", output)
+	}
+}
+
+func TestFormatTrash(t *testing.T) {
+	d := NewDisplay()
+	d.UseColors = false
+	entries := []fileops.TrashEntry{
+		{Name: "a.txt", OriginalPath: "/home/user/a.txt", DeletionDate: time.Date(2024, 5, 10, 12, 30, 0, 0, time.Local), Size: 2048},
+		{Name: "photos", OriginalPath: "/home/user/photos", Size: 4096, IsDir: true},
+		{Name: "unknown.bin", Size: 1},
+	}
+	output := d.FormatTrash(entries, []int{2, 5, 7})
+	for _, want := range []string{"   2. 2024-05-10 12:30", "/home/user/a.txt\n", "   5. -", "/home/user/photos" + string(filepath.Separator) + "\n", "   7.", "unknown.bin\n"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("в выводе нет %q:\n%s", want, output)
+		}
+	}
+}
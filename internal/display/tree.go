@@ -0,0 +1,338 @@
+package display
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"file-manager/internal/fileops"
+	"file-manager/internal/i18n"
+	"file-manager/internal/ignore"
+
+	"github.com/fatih/color"
+)
+
+// TreeOptions управляет выводом дерева директорий
+type TreeOptions struct {
+	// MaxDepth — максимальная глубина вывода (-1 — без ограничения)
+	MaxDepth int
+	// ShowHidden — показывать скрытые записи
+	ShowHidden bool
+	// DirsOnly — показывать только директории
+	DirsOnly bool
+	// Ignore — правила ignore-файлов (nil — не учитываются)
+	Ignore *ignore.Matcher
+}
+
+// treeWriter хранит состояние вывода одного дерева
+type treeWriter struct {
+	display *Display
+	options TreeOptions
+	sb      strings.Builder
+	dirs    int
+	files   int
+}
+
+// FormatTree форматирует дерево директории root с отступами в виде ветвей.
+// Символические ссылки на директории не раскрываются, недоступные директории выводятся без содержимого.
+func (d *Display) FormatTree(root string, options TreeOptions) (string, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return "", fmt.Errorf(i18n.T("tree_error"), root, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf(i18n.T("tree_not_dir"), root)
+	}
+
+	w := &treeWriter{display: d, options: options}
+	w.sb.WriteString(w.name(root, root, true) + "\n")
+	w.write(root, "", 1)
+	w.sb.WriteString("\n" + fmt.Sprintf(i18n.T("tree_summary"), w.dirs, w.files) + "\n")
+	return w.sb.String(), nil
+}
+
+// write выводит содержимое директории dir с префиксом prefix
+func (w *treeWriter) write(dir, prefix string, depth int) {
+	if w.options.MaxDepth >= 0 && depth > w.options.MaxDepth {
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	var visible []os.DirEntry
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		switch {
+		case !w.options.ShowHidden && strings.HasPrefix(entry.Name(), "."):
+		case w.options.DirsOnly && !entry.IsDir():
+		case w.options.Ignore != nil && w.options.Ignore.Ignored(path, entry.IsDir()):
+		default:
+			visible = append(visible, entry)
+		}
+	}
+
+	for i, entry := range visible {
+		branch, indent := "├── ", "│   "
+		if i == len(visible)-1 {
+			branch, indent = "└── ", "    "
+		}
+		path := filepath.Join(dir, entry.Name())
+		w.sb.WriteString(prefix + branch + w.name(path, entry.Name(), entry.IsDir()) + "\n")
+		if entry.IsDir() {
+			w.dirs++
+			w.write(path, prefix+indent, depth+1)
+		} else {
+			w.files++
+		}
+	}
+}
+
+// name возвращает имя записи, при включенных цветах — раскрашенное по типу файла
+func (w *treeWriter) name(path, name string, isDir bool) string {
+	if !w.display.UseColors {
+		return name
+	}
+	isExec := false
+	if !isDir {
+		if info, err := os.Lstat(path); err == nil {
+			isExec = info.Mode().IsRegular() && info.Mode().Perm()&0111 != 0
+		}
+	}
+	return GetColorByFileType(name, isDir, isExec).Sprint(name)
+}
+
+// FormatDiskUsage форматирует результат подсчета размеров директорий: размер, число файлов и путь
+func (d *Display) FormatDiskUsage(usage []fileops.DirUsage) string {
+	var sb strings.Builder
+	for _, entry := range usage {
+		path := entry.Path
+		if d.UseColors {
+			path = GetColorByFileType(entry.Path, true, false).Sprint(entry.Path)
+		}
+		sb.WriteString(fmt.Sprintf("%12s  %8d  %s\n", formatSize(entry.Size), entry.Files, path))
+	}
+	return sb.String()
+}
+
+// syncMarks — обозначения действий синхронизации
+var syncMarks = map[fileops.SyncAction]struct {
+	mark  string
+	color *color.Color
+}{
+	fileops.SyncCreate: {"+", SuccessColor},
+	fileops.SyncUpdate: {"~", WarningColor},
+	fileops.SyncDelete: {"-", ErrorColor},
+}
+
+// FormatSyncPlan форматирует план синхронизации: «+» — создание, «~» — обновление,
+// «-» — удаление; директории отмечаются завершающим /
+func (d *Display) FormatSyncPlan(plan *fileops.SyncPlan) string {
+	var sb strings.Builder
+	for _, item := range plan.Items {
+		mark := syncMarks[item.Action]
+		path := item.Path
+		if path == "." {
+			path = plan.Destination
+		}
+		if item.IsDir {
+			path += string(filepath.Separator)
+		}
+		line := mark.mark + " " + path
+		if d.UseColors {
+			line = mark.color.Sprint(line)
+		}
+		sb.WriteString(line + "\n")
+	}
+	summary := plan.Summary()
+	sb.WriteString(fmt.Sprintf(i18n.T("sync_summary")+"\n",
+		summary.Created, summary.Updated, summary.Deleted, formatSize(summary.Bytes)))
+	return sb.String()
+}
+
+// compareMarks — обозначения различий при сравнении директорий
+var compareMarks = map[fileops.CompareStatus]struct {
+	mark  string
+	color *color.Color
+}{
+	fileops.CompareLeftOnly:     {"<", ErrorColor},
+	fileops.CompareRightOnly:    {">", SuccessColor},
+	fileops.CompareDiffers:      {"~", WarningColor},
+	fileops.CompareTypeMismatch: {"!", ErrorColor},
+}
+
+// FormatComparison форматирует результат сравнения директорий: «<» — только слева, «>» — только
+// справа, «~» — различаются (в скобках причины), «!» — разные типы записей
+func (d *Display) FormatComparison(result *fileops.CompareResult) string {
+	var sb strings.Builder
+	for _, entry := range result.Entries {
+		mark := compareMarks[entry.Status]
+		line := mark.mark + " " + entry.Path
+		if entry.LeftType == "dir" && entry.RightType == "" || entry.RightType == "dir" && entry.LeftType == "" {
+			line += string(filepath.Separator)
+		}
+		switch entry.Status {
+		case fileops.CompareDiffers:
+			line += " (" + strings.Join(entry.Reasons, ", ") + ")"
+		case fileops.CompareTypeMismatch:
+			line += " (" + entry.LeftType + " / " + entry.RightType + ")"
+		}
+		if d.UseColors {
+			line = mark.color.Sprint(line)
+		}
+		sb.WriteString(line + "\n")
+	}
+	if len(result.Entries) == 0 {
+		sb.WriteString(i18n.T("compare_equal") + "\n")
+		return sb.String()
+	}
+	s := result.Summary
+	sb.WriteString(fmt.Sprintf(i18n.T("compare_summary")+"\n", s.LeftOnly, s.RightOnly, s.Differs, s.TypeMismatch))
+	return sb.String()
+}
+
+// FormatDupes форматирует группы одинаковых файлов: размер, число копий и лишнее место, затем пути.
+// Если задано правило keep, оставляемая копия отмечается «*».
+func (d *Display) FormatDupes(groups []fileops.DupeGroup, keep fileops.DupeKeep) string {
+	if len(groups) == 0 {
+		return i18n.T("dupes_none") + "\n"
+	}
+	var sb strings.Builder
+	var files int
+	var wasted int64
+	for _, group := range groups {
+		header := fmt.Sprintf(i18n.T("dupes_group"), formatSize(group.Size), len(group.Files), formatSize(group.Wasted()))
+		if d.UseColors {
+			header = WarningColor.Sprint(header)
+		}
+		sb.WriteString(header + "\n")
+		keeper := -1
+		if keep != "" {
+			keeper = group.Keeper(keep)
+		}
+		for i, file := range group.Files {
+			mark := " "
+			if i == keeper {
+				mark = "*"
+			}
+			sb.WriteString("  " + mark + " " + file.Path + "\n")
+		}
+		files += len(group.Files) - 1
+		wasted += group.Wasted()
+	}
+	sb.WriteString(fmt.Sprintf(i18n.T("dupes_summary")+"\n", len(groups), files, formatSize(wasted)))
+	return sb.String()
+}
+
+// FormatDedupe форматирует итог удаления дубликатов
+func (d *Display) FormatDedupe(summary fileops.DedupeSummary) string {
+	return fmt.Sprintf(i18n.T("dupes_done")+"\n", summary.Files, formatSize(summary.Bytes))
+}
+
+// checksumColors — цвета результатов проверки контрольных сумм
+var checksumColors = map[fileops.ChecksumStatus]*color.Color{
+	fileops.ChecksumOK:      SuccessColor,
+	fileops.ChecksumFailed:  ErrorColor,
+	fileops.ChecksumMissing: WarningColor,
+}
+
+// FormatChecksumChecks форматирует результаты проверки контрольных сумм в виде «путь: OK»,
+// как sha256sum --check; после всех строк выводится итог, если все файлы совпали
+func (d *Display) FormatChecksumChecks(checks []fileops.ChecksumCheck) string {
+	var sb strings.Builder
+	ok := 0
+	for _, check := range checks {
+		status := string(check.Status)
+		if d.UseColors {
+			status = checksumColors[check.Status].Sprint(status)
+		}
+		line := check.Path + ": " + status
+		if check.Err != nil {
+			line += " (" + check.Err.Error() + ")"
+		}
+		sb.WriteString(line + "\n")
+		if check.Status == fileops.ChecksumOK {
+			ok++
+		}
+	}
+	if ok == len(checks) {
+		sb.WriteString(fmt.Sprintf(i18n.T("hash_check_ok")+"\n", ok))
+	}
+	return sb.String()
+}
+
+// manifestMarks — обозначения расхождений с манифестом
+var manifestMarks = map[fileops.ManifestChangeKind]struct {
+	mark  string
+	color *color.Color
+}{
+	fileops.ManifestAdded:       {"+", SuccessColor},
+	fileops.ManifestRemoved:     {"-", ErrorColor},
+	fileops.ManifestModified:    {"~", WarningColor},
+	fileops.ManifestPermissions: {"!", ErrorColor},
+}
+
+// FormatManifestChanges форматирует расхождения дерева с манифестом: «+» — добавлена, «-» — удалена,
+// «~» — изменена (в скобках причины), «!» — изменились права или владелец (было → стало, как в ls -l)
+func (d *Display) FormatManifestChanges(changes []fileops.ManifestChange) string {
+	if len(changes) == 0 {
+		return i18n.T("manifest_ok") + "\n"
+	}
+	var sb strings.Builder
+	counts := make(map[fileops.ManifestChangeKind]int)
+	for _, change := range changes {
+		mark := manifestMarks[change.Kind]
+		line := mark.mark + " " + change.Path
+		switch change.Kind {
+		case fileops.ManifestModified:
+			line += " (" + strings.Join(change.Reasons, ", ") + ")"
+		case fileops.ManifestPermissions:
+			line += " (" + manifestPermissions(change.Old) + " → " + manifestPermissions(change.New) + ")"
+		}
+		if d.UseColors {
+			line = mark.color.Sprint(line)
+		}
+		sb.WriteString(line + "\n")
+		counts[change.Kind]++
+	}
+	sb.WriteString(fmt.Sprintf(i18n.T("manifest_summary")+"\n", counts[fileops.ManifestAdded],
+		counts[fileops.ManifestRemoved], counts[fileops.ManifestModified], counts[fileops.ManifestPermissions]))
+	return sb.String()
+}
+
+// manifestPermissions описывает права и владельца записи манифеста: «-rw-r--r-- 0644 1000:1000»
+func manifestPermissions(entry *fileops.ManifestEntry) string {
+	bits, _ := strconv.ParseUint(entry.Mode, 8, 32)
+	mode := os.FileMode(bits).Perm()
+	if entry.Type == "dir" {
+		mode |= os.ModeDir
+	}
+	result := fileops.NewPermissionsManager().FormatPermissions(mode) + " " + entry.Mode
+	if entry.UID != nil && entry.GID != nil {
+		result += fmt.Sprintf(" %d:%d", *entry.UID, *entry.GID)
+	}
+	return result
+}
+
+// FormatTrash форматирует записи корзины: номер, время удаления, размер и исходный путь.
+// numbers — номера записей в полном списке корзины, по которым их восстанавливает restore.
+func (d *Display) FormatTrash(entries []fileops.TrashEntry, numbers []int) string {
+	var sb strings.Builder
+	for i, entry := range entries {
+		date := "-"
+		if !entry.DeletionDate.IsZero() {
+			date = entry.DeletionDate.Format("2006-01-02 15:04")
+		}
+		path := entry.DisplayPath()
+		if entry.IsDir {
+			path += string(filepath.Separator)
+			if d.UseColors {
+				path = DirColor.Sprint(path)
+			}
+		}
+		sb.WriteString(fmt.Sprintf("%4d. %-16s %10s  %s\n", numbers[i], date, formatSize(entry.Size), path))
+	}
+	return sb.String()
+}
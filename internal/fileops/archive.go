@@ -13,19 +13,88 @@ import (
 	"strings"
 
 	"errors"
+	"file-manager/internal/glob"
 	"file-manager/internal/i18n"
+	"file-manager/internal/ignore"
 
 	"github.com/ulikunitz/xz"
 )
 
 // Archiver предоставляет функции для работы с архивами
-type Archiver struct{}
+type Archiver struct {
+	// RespectIgnore — не добавлять в архив записи, исключенные ignore-файлами в архивируемых директориях
+	RespectIgnore bool
+	// Include — если задано, в архив попадают только файлы, подходящие хотя бы под один шаблон
+	Include []*glob.Pattern
+	// Exclude — файлы и директории, подходящие хотя бы под один шаблон, в архив не попадают
+	Exclude []*glob.Pattern
+}
 
 // NewArchiver создает новый экземпляр Archiver
 func NewArchiver() *Archiver {
 	return &Archiver{}
 }
 
+// WithIgnore возвращает копию Archiver с указанным режимом учета ignore-файлов
+func (a *Archiver) WithIgnore(respect bool) *Archiver {
+	archiver := *a
+	archiver.RespectIgnore = respect
+	return &archiver
+}
+
+// WithPatterns возвращает копию Archiver с шаблонами включения и исключения записей.
+// Шаблон без / сравнивается с именем записи, шаблон с / — с ее путем внутри архива.
+func (a *Archiver) WithPatterns(include, exclude []*glob.Pattern) *Archiver {
+	archiver := *a
+	archiver.Include = include
+	archiver.Exclude = exclude
+	return &archiver
+}
+
+// archiveFilter отбирает записи, добавляемые в архив из одного источника
+type archiveFilter struct {
+	rules            *ignore.Matcher
+	include, exclude []*glob.Pattern
+}
+
+// entryFilter возвращает фильтр записей для архивируемого источника или nil, если отбор не нужен
+func (a *Archiver) entryFilter(src string) *archiveFilter {
+	if !a.RespectIgnore && len(a.Include) == 0 && len(a.Exclude) == 0 {
+		return nil
+	}
+	filter := &archiveFilter{include: a.Include, exclude: a.Exclude}
+	if a.RespectIgnore {
+		filter.rules = ignore.NewMatcher(src)
+	}
+	return filter
+}
+
+// skip проверяет, нужно ли пропустить запись path с путем nameInArchive внутри архива
+func (f *archiveFilter) skip(path, nameInArchive string, isDir bool) bool {
+	if f == nil {
+		return false
+	}
+	if f.rules != nil && f.rules.Ignored(path, isDir) {
+		return true
+	}
+	nameInArchive = filepath.ToSlash(nameInArchive)
+	for _, pattern := range f.exclude {
+		if pattern.MatchPath(nameInArchive) {
+			return true
+		}
+	}
+	// Шаблоны включения относятся к файлам: директории обходятся, чтобы найти файлы внутри
+	if isDir || len(f.include) == 0 {
+		return false
+	}
+	for _, pattern := range f.include {
+		if pattern.MatchPath(nameInArchive) {
+			return false
+		}
+	}
+	return true
+}
+
 // ArchiveFiles создает архив из указанных файлов и директорий (zip, tar.gz, tar.bz2, tar.xz)
 func (a *Archiver) ArchiveFiles(sources []string, destination string, format string) error {
 	if format == "" {
@@ -70,7 +139,7 @@ func (a *Archiver) archiveZip(sources []string, destination string) error {
 		}
 	}()
 	for _, src := range sources {
-		err := addFileToZip(zipWriter, src, "")
+		err := addFileToZip(zipWriter, src, "", a.entryFilter(src))
 		if err != nil {
 			return fmt.Errorf(i18n.T("archive_add_error"), src, err)
 		}
@@ -122,7 +191,7 @@ func (a *Archiver) archiveTarCompressed(sources []string, destination, compressi
 		}
 	}()
 	for _, src := range sources {
-		err := addFileToTar(tw, src, "")
+		err := addFileToTar(tw, src, "", a.entryFilter(src))
 		if err != nil {
 			return fmt.Errorf(i18n.T("archive_add_error"), src, err)
 		}
@@ -147,7 +216,7 @@ func (a *Archiver) archiveTar(sources []string, destination string) error {
 		}
 	}()
 	for _, src := range sources {
-		err := addFileToTar(tw, src, "")
+		err := addFileToTar(tw, src, "", a.entryFilter(src))
 		if err != nil {
 			return fmt.Errorf(i18n.T("archive_add_error"), src, err)
 		}
@@ -155,7 +224,9 @@ func (a *Archiver) archiveTar(sources []string, destination string) error {
 	return nil
 }
 
-func addFileToTar(tw *tar.Writer, src, baseInTar string) error {
+// addFileToTar добавляет в архив файл или директорию рекурсивно; filter (может быть nil)
+// отбирает добавляемые записи
+func addFileToTar(tw *tar.Writer, src, baseInTar string, filter *archiveFilter) error {
 	info, err := os.Stat(src)
 	if err != nil {
 		return err
@@ -173,7 +244,10 @@ func addFileToTar(tw *tar.Writer, src, baseInTar string) error {
 			} else {
 				entryBase = filepath.Join(baseInTar, entry.Name())
 			}
-			err = addFileToTar(tw, entryPath, entryBase)
+			if filter.skip(entryPath, entryBase, entry.IsDir()) {
+				continue
+			}
+			err = addFileToTar(tw, entryPath, entryBase, filter)
 			if err != nil {
 				return err
 			}
@@ -434,7 +508,9 @@ func listTarCompressed(source, compression string) ([]string, error) {
 	return files, nil
 }
 
-func addFileToZip(zipWriter *zip.Writer, src, baseInZip string) error {
+// addFileToZip добавляет в архив файл или директорию рекурсивно; filter (может быть nil)
+// отбирает добавляемые записи
+func addFileToZip(zipWriter *zip.Writer, src, baseInZip string, filter *archiveFilter) error {
 	info, err := os.Stat(src)
 	if err != nil {
 		return err
@@ -452,7 +528,10 @@ func addFileToZip(zipWriter *zip.Writer, src, baseInZip string) error {
 			} else {
 				entryBase = filepath.Join(baseInZip, entry.Name())
 			}
-			err = addFileToZip(zipWriter, entryPath, entryBase)
+			if filter.skip(entryPath, entryBase, entry.IsDir()) {
+				continue
+			}
+			err = addFileToZip(zipWriter, entryPath, entryBase, filter)
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,244 @@
+package fileops
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"file-manager/internal/i18n"
+)
+
+var (
+	// resumeThreshold — размер файла, начиная с которого копирование ведет журнал для продолжения
+	resumeThreshold int64 = 64 << 20
+	// journalInterval — объем данных между сохранениями журнала
+	journalInterval int64 = 8 << 20
+)
+
+// resumeJournal — журнал прерываемого копирования большого файла
+type resumeJournal struct {
+	Source  string    `json:"source"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	// Offset — число байт копии, записанных на диск и учтенных в Checksum
+	Offset int64 `json:"offset"`
+	// Checksum — SHA-256 первых Offset байт копии
+	Checksum string `json:"checksum"`
+}
+
+// partialPath возвращает имя временного файла, в который пишется копия destination
+func partialPath(destination string) string {
+	dir, name := filepath.Split(destination)
+	return filepath.Join(dir, "."+name+".fm-partial")
+}
+
+// journalPath возвращает имя журнала копирования destination
+func journalPath(destination string) string {
+	dir, name := filepath.Split(destination)
+	return filepath.Join(dir, "."+name+".fm-journal")
+}
+
+// copyContents копирует содержимое обычного файла. Данные пишутся во временный файл рядом
+// с destination, сбрасываются на диск и атомарно переименовываются, поэтому после сбоя под
+// настоящим именем не остается недописанного файла. Для больших файлов ведется журнал:
+// с resume копирование продолжается с последнего проверенного смещения. buf используется,
+// если данные нельзя скопировать средствами ядра.
+func copyContents(source, destination string, info fs.FileInfo, resume bool, buf []byte) error {
+	if err := writePartial(source, destination, info, resume, true, buf); err != nil {
+		return err
+	}
+	if err := commitPartial(destination); err != nil {
+		return err
+	}
+	syncDir(filepath.Dir(destination))
+	return nil
+}
+
+// writePartial записывает копию source во временный файл destination. Без sync данные
+// не сбрасываются на диск: это делает вызывающий, например одним вызовом для пакета файлов.
+func writePartial(source, destination string, info fs.FileInfo, resume, sync bool, buf []byte) (err error) {
+	src, err := os.Open(source)
+	if err != nil {
+		return fmt.Errorf(i18n.T("fileops_open_source_error"), source, err)
+	}
+	defer func() {
+		if closeErr := src.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf(i18n.T("fileops_close_source_error"), source, closeErr)
+		}
+	}()
+
+	partial := partialPath(destination)
+	journaled := info.Size() >= resumeThreshold
+	var offset int64
+	sum := sha256.New()
+	if resume && journaled {
+		offset, sum = loadResume(destination, source, info)
+	}
+
+	flags := os.O_RDWR | os.O_CREATE
+	if offset == 0 {
+		flags |= os.O_TRUNC
+	}
+	dst, err := os.OpenFile(partial, flags, info.Mode().Perm()|0200)
+	if err != nil {
+		return fmt.Errorf(i18n.T("fileops_create_dest_error"), destination, err)
+	}
+	defer func() {
+		if dst != nil {
+			_ = dst.Close()
+		}
+		// Без журнала недописанный файл продолжить нельзя, поэтому он удаляется
+		if err != nil && !journaled {
+			_ = os.Remove(partial)
+		}
+	}()
+
+	switch {
+	case offset == 0 && cloneFile(dst, src):
+		// Копия разделяет блоки с источником (reflink), данные не переписываются
+	case !journaled:
+		if _, err = copyData(dst, src, -1, buf); err != nil {
+			return fmt.Errorf(i18n.T("fileops_copy_content_error"), err)
+		}
+	default:
+		if err = copyJournaled(source, destination, info, src, dst, offset, sum, buf); err != nil {
+			return err
+		}
+	}
+
+	if sync {
+		if err = dst.Sync(); err != nil {
+			return fmt.Errorf(i18n.T("fileops_copy_content_error"), err)
+		}
+	}
+	closeErr := dst.Close()
+	dst = nil
+	if closeErr != nil {
+		return fmt.Errorf(i18n.T("fileops_close_dest_error"), destination, closeErr)
+	}
+	return nil
+}
+
+// copyJournaled копирует большой файл частями по journalInterval, начиная с offset. После каждой
+// части данные сбрасываются на диск, а записанная часть перечитывается для контрольной суммы журнала.
+func copyJournaled(source, destination string, info fs.FileInfo, src, dst *os.File, offset int64, sum hash.Hash, buf []byte) error {
+	if offset > 0 {
+		err := dst.Truncate(offset)
+		if err == nil {
+			_, err = dst.Seek(offset, io.SeekStart)
+		}
+		if err == nil {
+			_, err = src.Seek(offset, io.SeekStart)
+		}
+		if err != nil {
+			return fmt.Errorf(i18n.T("fileops_copy_content_error"), err)
+		}
+	}
+	for {
+		n, err := copyData(dst, src, journalInterval, buf)
+		if err != nil {
+			return fmt.Errorf(i18n.T("fileops_copy_content_error"), err)
+		}
+		if n > 0 {
+			if _, err := io.CopyBuffer(sum, io.NewSectionReader(dst, offset, n), buf); err != nil {
+				return fmt.Errorf(i18n.T("fileops_copy_content_error"), err)
+			}
+			offset += n
+		}
+		if n < journalInterval {
+			return nil
+		}
+		if err := dst.Sync(); err != nil {
+			return fmt.Errorf(i18n.T("fileops_copy_content_error"), err)
+		}
+		if err := saveJournal(journalPath(destination), resumeJournal{
+			Source: source, Size: info.Size(), ModTime: info.ModTime(),
+			Offset: offset, Checksum: hex.EncodeToString(sum.Sum(nil)),
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+// commitPartial переименовывает записанную копию в destination и удаляет журнал
+func commitPartial(destination string) error {
+	if err := os.Rename(partialPath(destination), destination); err != nil {
+		return fmt.Errorf(i18n.T("fileops_create_dest_error"), destination, err)
+	}
+	_ = os.Remove(journalPath(destination))
+	return nil
+}
+
+// copyBuffered копирует до limit байт (все данные при limit < 0) через buf. Обертки скрывают
+// ReadFrom и WriteTo, чтобы io.CopyBuffer действительно использовал буфер заданного размера.
+func copyBuffered(dst io.Writer, src io.Reader, limit int64, buf []byte) (int64, error) {
+	if limit >= 0 {
+		src = io.LimitReader(src, limit)
+	}
+	return io.CopyBuffer(struct{ io.Writer }{dst}, struct{ io.Reader }{src}, buf)
+}
+
+// loadResume проверяет журнал и временный файл прерванного копирования. Возвращает смещение,
+// с которого можно продолжить, и контрольную сумму уже скопированной части; если журнал
+// не относится к этому источнику или копия не совпадает с ним, копирование начинается заново.
+func loadResume(destination, source string, info fs.FileInfo) (int64, hash.Hash) {
+	fresh := sha256.New()
+	data, err := os.ReadFile(journalPath(destination))
+	if err != nil {
+		return 0, fresh
+	}
+	var journal resumeJournal
+	if json.Unmarshal(data, &journal) != nil || journal.Source != source ||
+		journal.Size != info.Size() || !journal.ModTime.Equal(info.ModTime()) || journal.Offset > info.Size() {
+		return 0, fresh
+	}
+
+	file, err := os.Open(partialPath(destination))
+	if err != nil {
+		return 0, fresh
+	}
+	defer func() { _ = file.Close() }()
+	sum := sha256.New()
+	if n, err := io.CopyN(sum, file, journal.Offset); err != nil || n != journal.Offset {
+		return 0, fresh
+	}
+	if hex.EncodeToString(sum.Sum(nil)) != journal.Checksum {
+		return 0, fresh
+	}
+	return journal.Offset, sum
+}
+
+// saveJournal атомарно записывает журнал копирования
+func saveJournal(path string, journal resumeJournal) error {
+	data, err := json.Marshal(journal)
+	if err != nil {
+		return fmt.Errorf(i18n.T("fileops_journal_error"), err)
+	}
+	temp := path + ".tmp"
+	if err := os.WriteFile(temp, data, 0600); err != nil {
+		return fmt.Errorf(i18n.T("fileops_journal_error"), err)
+	}
+	if err := os.Rename(temp, path); err != nil {
+		_ = os.Remove(temp)
+		return fmt.Errorf(i18n.T("fileops_journal_error"), err)
+	}
+	return nil
+}
+
+// syncDir сбрасывает на диск запись директории после переименования. На платформах,
+// где директорию нельзя открыть для синхронизации, ничего не делает.
+func syncDir(dir string) {
+	file, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = file.Sync()
+	_ = file.Close()
+}
@@ -0,0 +1,290 @@
+package fileops
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"file-manager/internal/i18n"
+)
+
+// CompareStatus — результат сравнения одной записи
+type CompareStatus string
+
+const (
+	// CompareLeftOnly — запись есть только слева
+	CompareLeftOnly CompareStatus = "left-only"
+	// CompareRightOnly — запись есть только справа
+	CompareRightOnly CompareStatus = "right-only"
+	// CompareDiffers — записи одного типа различаются
+	CompareDiffers CompareStatus = "differs"
+	// CompareTypeMismatch — записи разных типов, например файл и директория
+	CompareTypeMismatch CompareStatus = "type-mismatch"
+)
+
+// Причины различия записей одного типа
+const (
+	CompareReasonSize    = "size"
+	CompareReasonMtime   = "mtime"
+	CompareReasonContent = "content"
+	CompareReasonTarget  = "target"
+)
+
+// CompareEntry — различие двух деревьев. Директория, которая есть только с одной стороны,
+// указывается один раз, без содержимого.
+type CompareEntry struct {
+	Path   string        `json:"path"`
+	Status CompareStatus `json:"status"`
+	// Reasons — чем различаются записи одного типа: size, mtime, content, target
+	Reasons []string `json:"reasons,omitempty"`
+	// LeftType и RightType — типы записей: file, dir, symlink, other
+	LeftType  string `json:"left_type,omitempty"`
+	RightType string `json:"right_type,omitempty"`
+}
+
+// CompareSummary — число различий каждого вида
+type CompareSummary struct {
+	LeftOnly     int `json:"left_only"`
+	RightOnly    int `json:"right_only"`
+	Differs      int `json:"differs"`
+	TypeMismatch int `json:"type_mismatch"`
+}
+
+// CompareResult — результат сравнения двух директорий
+type CompareResult struct {
+	Left    string         `json:"left"`
+	Right   string         `json:"right"`
+	Entries []CompareEntry `json:"entries"`
+	Summary CompareSummary `json:"summary"`
+}
+
+// CompareOptions задает режим сравнения
+type CompareOptions struct {
+	// Shallow сравнивает только записи верхнего уровня, не заходя в общие поддиректории
+	Shallow bool
+	// Content сравнивает файлы одинакового размера по SHA-256 вместо времени изменения
+	Content bool
+	// Workers — число директорий и файлов, обрабатываемых одновременно (0 — DefaultCopyWorkers)
+	Workers int
+}
+
+// Compare сравнивает директории left и right. Пары директорий обходятся параллельно, а содержимое
+// файлов хешируется только для файлов одинакового размера и только с options.Content.
+func Compare(left, right string, options CompareOptions) (*CompareResult, error) {
+	for _, dir := range []string{left, right} {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return nil, fmt.Errorf(i18n.T("fileops_stat_error"), dir, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf(i18n.T("compare_not_dir"), dir)
+		}
+	}
+	workers := options.Workers
+	if workers <= 0 {
+		workers = DefaultCopyWorkers()
+	}
+	c := &comparer{
+		left: left, right: right, options: options,
+		slots: make(chan struct{}, workers),
+	}
+	c.spawn(func() { c.dir(".") })
+	c.wg.Wait()
+	if c.err == nil {
+		c.hashAll(workers)
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	result := &CompareResult{Left: left, Right: right, Entries: c.entries}
+	if result.Entries == nil {
+		result.Entries = []CompareEntry{}
+	}
+	sort.Slice(result.Entries, func(i, j int) bool { return result.Entries[i].Path < result.Entries[j].Path })
+	for _, entry := range result.Entries {
+		switch entry.Status {
+		case CompareLeftOnly:
+			result.Summary.LeftOnly++
+		case CompareRightOnly:
+			result.Summary.RightOnly++
+		case CompareDiffers:
+			result.Summary.Differs++
+		case CompareTypeMismatch:
+			result.Summary.TypeMismatch++
+		}
+	}
+	return result, nil
+}
+
+// comparer хранит состояние одного сравнения
+type comparer struct {
+	left, right string
+	options     CompareOptions
+	// slots ограничивает число одновременно обрабатываемых директорий
+	slots chan struct{}
+	wg    sync.WaitGroup
+
+	mu      sync.Mutex
+	entries []CompareEntry
+	// candidates — файлы одинакового размера, содержимое которых нужно сравнить
+	candidates []string
+	err        error
+}
+
+// spawn выполняет задачу в отдельной горутине, когда освобождается место
+func (c *comparer) spawn(task func()) {
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.slots <- struct{}{}
+		defer func() { <-c.slots }()
+		task()
+	}()
+}
+
+// add записывает различие
+func (c *comparer) add(entry CompareEntry) {
+	c.mu.Lock()
+	c.entries = append(c.entries, entry)
+	c.mu.Unlock()
+}
+
+// fail запоминает первую ошибку
+func (c *comparer) fail(err error) {
+	c.mu.Lock()
+	if c.err == nil {
+		c.err = err
+	}
+	c.mu.Unlock()
+}
+
+// readEntries читает директорию и возвращает сведения о записях без разыменования ссылок
+func readEntries(dir string) (map[string]fs.FileInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("fileops_read_dir_error"), dir, err)
+	}
+	infos := make(map[string]fs.FileInfo, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf(i18n.T("fileops_stat_error"), filepath.Join(dir, entry.Name()), err)
+		}
+		infos[entry.Name()] = info
+	}
+	return infos, nil
+}
+
+// dir сравнивает содержимое пары директорий с путем rel
+func (c *comparer) dir(rel string) {
+	leftInfos, err := readEntries(filepath.Join(c.left, rel))
+	if err == nil {
+		var rightInfos map[string]fs.FileInfo
+		if rightInfos, err = readEntries(filepath.Join(c.right, rel)); err == nil {
+			c.entriesOf(rel, leftInfos, rightInfos)
+		}
+	}
+	if err != nil {
+		c.fail(err)
+	}
+}
+
+// entriesOf сравнивает записи пары директорий
+func (c *comparer) entriesOf(rel string, leftInfos, rightInfos map[string]fs.FileInfo) {
+	for name, l := range leftInfos {
+		path := filepath.Join(rel, name)
+		r, ok := rightInfos[name]
+		if !ok {
+			c.add(CompareEntry{Path: path, Status: CompareLeftOnly, LeftType: entryType(l)})
+			continue
+		}
+		if entryType(l) != entryType(r) {
+			c.add(CompareEntry{Path: path, Status: CompareTypeMismatch, LeftType: entryType(l), RightType: entryType(r)})
+			continue
+		}
+		switch {
+		case l.IsDir():
+			if !c.options.Shallow {
+				c.spawn(func() { c.dir(path) })
+			}
+		case l.Mode()&fs.ModeSymlink != 0:
+			leftTarget, errLeft := os.Readlink(filepath.Join(c.left, path))
+			rightTarget, errRight := os.Readlink(filepath.Join(c.right, path))
+			if errLeft != nil || errRight != nil || leftTarget != rightTarget {
+				c.add(CompareEntry{Path: path, Status: CompareDiffers, Reasons: []string{CompareReasonTarget}})
+			}
+		case l.Mode().IsRegular():
+			c.file(path, l, r)
+		}
+	}
+	for name, r := range rightInfos {
+		if _, ok := leftInfos[name]; !ok {
+			c.add(CompareEntry{Path: filepath.Join(rel, name), Status: CompareRightOnly, RightType: entryType(r)})
+		}
+	}
+}
+
+// file сравнивает обычные файлы по размеру и времени изменения; с Content файлы одинакового
+// размера откладываются для сравнения содержимого
+func (c *comparer) file(path string, l, r fs.FileInfo) {
+	var reasons []string
+	if l.Size() != r.Size() {
+		reasons = append(reasons, CompareReasonSize)
+	} else if c.options.Content {
+		c.mu.Lock()
+		c.candidates = append(c.candidates, path)
+		c.mu.Unlock()
+		return
+	}
+	if !c.options.Content && l.ModTime().Unix() != r.ModTime().Unix() {
+		reasons = append(reasons, CompareReasonMtime)
+	}
+	if len(reasons) > 0 {
+		c.add(CompareEntry{Path: path, Status: CompareDiffers, Reasons: reasons})
+	}
+}
+
+// hashAll сравнивает содержимое отложенных файлов заданным числом рабочих
+func (c *comparer) hashAll(workers int) {
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				same, err := sameContent(filepath.Join(c.left, path), filepath.Join(c.right, path))
+				if err != nil {
+					c.fail(fmt.Errorf(i18n.T("fileops_checksum_error"), err))
+					continue
+				}
+				if !same {
+					c.add(CompareEntry{Path: path, Status: CompareDiffers, Reasons: []string{CompareReasonContent}})
+				}
+			}
+		}()
+	}
+	for _, path := range c.candidates {
+		jobs <- path
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// entryType возвращает тип записи для отчета
+func entryType(info fs.FileInfo) string {
+	switch mode := info.Mode(); {
+	case mode.IsDir():
+		return "dir"
+	case mode&fs.ModeSymlink != 0:
+		return "symlink"
+	case mode.IsRegular():
+		return "file"
+	default:
+		return "other"
+	}
+}
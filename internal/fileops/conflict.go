@@ -0,0 +1,199 @@
+package fileops
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"file-manager/internal/i18n"
+)
+
+// ConflictPolicy определяет, что делать, если файл назначения уже существует
+type ConflictPolicy int
+
+const (
+	// ConflictOverwrite — заменить файл назначения
+	ConflictOverwrite ConflictPolicy = iota
+	// ConflictSkip — оставить файл назначения, источник не копировать
+	ConflictSkip
+	// ConflictKeepNewer — заменить, только если источник изменен позже файла назначения
+	ConflictKeepNewer
+	// ConflictRename — записать источник под свободным именем вида "file (1).txt"
+	ConflictRename
+	// ConflictBackup — переименовать файл назначения в резервную копию и записать источник
+	ConflictBackup
+	// ConflictAsk — спросить пользователя
+	ConflictAsk
+)
+
+// conflictNames — имена политик для флагов и настроек
+var conflictNames = []string{"overwrite", "skip", "keep-newer", "rename-new", "backup", "ask"}
+
+// String возвращает имя политики
+func (p ConflictPolicy) String() string {
+	if p < 0 || int(p) >= len(conflictNames) {
+		return strconv.Itoa(int(p))
+	}
+	return conflictNames[p]
+}
+
+// ParseConflictPolicy разбирает имя политики
+func ParseConflictPolicy(name string) (ConflictPolicy, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for i, policyName := range conflictNames {
+		if name == policyName {
+			return ConflictPolicy(i), nil
+		}
+	}
+	return 0, fmt.Errorf(i18n.T("fileops_unknown_conflict"), name, strings.Join(conflictNames, ", "))
+}
+
+// BackupStyle определяет имя резервной копии
+type BackupStyle int
+
+const (
+	// BackupSimple — "file~"; прежняя резервная копия заменяется
+	BackupSimple BackupStyle = iota
+	// BackupNumbered — "file.~1~", "file.~2~", ...
+	BackupNumbered
+)
+
+// backupNames — имена стилей резервных копий
+var backupNames = []string{"simple", "numbered"}
+
+// String возвращает имя стиля
+func (b BackupStyle) String() string {
+	if b < 0 || int(b) >= len(backupNames) {
+		return strconv.Itoa(int(b))
+	}
+	return backupNames[b]
+}
+
+// ParseBackupStyle разбирает имя стиля резервных копий
+func ParseBackupStyle(name string) (BackupStyle, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for i, styleName := range backupNames {
+		if name == styleName {
+			return BackupStyle(i), nil
+		}
+	}
+	return 0, fmt.Errorf(i18n.T("fileops_unknown_backup"), name, strings.Join(backupNames, ", "))
+}
+
+// ConflictAnswer — ответ пользователя на вопрос о конфликте
+type ConflictAnswer struct {
+	Policy ConflictPolicy
+	// All — применить ответ ко всем следующим конфликтам пакета
+	All bool
+}
+
+// Conflicts разрешает конфликты имен в пределах одной пакетной операции: ответ
+// «для всех» запоминается и применяется к следующим конфликтам того же пакета.
+// Нулевое значение и nil означают замену файлов назначения, как раньше.
+type Conflicts struct {
+	Policy ConflictPolicy
+	Backup BackupStyle
+	// Ask запрашивает решение для политики ConflictAsk; ответ ConflictAsk не допускается
+	Ask func(source, destination string) (ConflictAnswer, error)
+
+	remembered *ConflictPolicy
+}
+
+// resolve решает, куда записать source, если destination уже существует. Возвращает путь
+// для записи или пустую строку, если источник нужно пропустить. При merge директории
+// не конфликтуют с директориями: их содержимое объединяется.
+func (c *Conflicts) resolve(source, destination string, info fs.FileInfo, merge bool) (string, error) {
+	existing, err := os.Lstat(destination)
+	if os.IsNotExist(err) {
+		return destination, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf(i18n.T("fileops_stat_error"), destination, err)
+	}
+	if merge && info.IsDir() && existing.IsDir() {
+		return destination, nil
+	}
+	if c == nil {
+		return destination, nil
+	}
+
+	policy := c.Policy
+	if c.remembered != nil {
+		policy = *c.remembered
+	}
+	if policy == ConflictAsk {
+		if c.Ask == nil {
+			return "", fmt.Errorf(i18n.T("fileops_conflict_exists"), destination)
+		}
+		answer, err := c.Ask(source, destination)
+		if err != nil {
+			return "", err
+		}
+		policy = answer.Policy
+		if answer.All {
+			c.remembered = &policy
+		}
+	}
+
+	switch policy {
+	case ConflictSkip:
+		return "", nil
+	case ConflictKeepNewer:
+		if !info.ModTime().After(existing.ModTime()) {
+			return "", nil
+		}
+	case ConflictRename:
+		return freeName(destination), nil
+	case ConflictBackup:
+		backup := backupName(destination, c.Backup)
+		if err := os.Rename(destination, backup); err != nil {
+			return "", fmt.Errorf(i18n.T("fileops_backup_error"), destination, err)
+		}
+		return destination, nil
+	}
+	if existing.IsDir() != info.IsDir() || existing.IsDir() && !merge {
+		return "", fmt.Errorf(i18n.T("fileops_conflict_type"), destination)
+	}
+	return destination, nil
+}
+
+// freeName возвращает первое свободное имя вида "name (N).ext"
+func freeName(path string) string {
+	dir, name := filepath.Split(path)
+	ext := filepath.Ext(name)
+	if ext == name {
+		// Скрытый файл без расширения, например .bashrc
+		ext = ""
+	}
+	stem := strings.TrimSuffix(name, ext)
+	for n := 1; ; n++ {
+		candidate := filepath.Join(dir, fmt.Sprintf("%s (%d)%s", stem, n, ext))
+		if _, err := os.Lstat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
+
+// backupName возвращает имя резервной копии для path
+func backupName(path string, style BackupStyle) string {
+	if style != BackupNumbered {
+		return path + "~"
+	}
+	// Следующий номер после наибольшего из существующих копий
+	dir, name := filepath.Split(path)
+	entries, _ := os.ReadDir(filepath.Join(dir, "."))
+	last := 0
+	for _, entry := range entries {
+		suffix, ok := strings.CutPrefix(entry.Name(), name+".~")
+		if !ok || !strings.HasSuffix(suffix, "~") {
+			continue
+		}
+		if n, err := strconv.Atoi(strings.TrimSuffix(suffix, "~")); err == nil && n > last {
+			last = n
+		}
+	}
+	return fmt.Sprintf("%s.~%d~", path, last+1)
+}
@@ -0,0 +1,309 @@
+package fileops
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"runtime"
+	"strings"
+
+	"file-manager/internal/i18n"
+	"file-manager/internal/sysinfo"
+
+	"gopkg.in/djherbis/times.v1"
+)
+
+// Preserve — набор атрибутов, сохраняемых при копировании
+type Preserve uint
+
+const (
+	// PreserveMode — права доступа
+	PreserveMode Preserve = 1 << iota
+	// PreserveTimes — время изменения и последнего доступа
+	PreserveTimes
+	// PreserveOwnership — владелец и группа; без прав суперпользователя ошибка смены владельца пропускается
+	PreserveOwnership
+	// PreserveLinks — символические ссылки копируются как ссылки, а файлы с несколькими
+	// жесткими ссылками внутри копируемого дерева остаются связанными
+	PreserveLinks
+	// PreserveXattr — расширенные атрибуты
+	PreserveXattr
+	// PreserveSpecial — именованные каналы и файлы устройств создаются заново, а не читаются
+	PreserveSpecial
+
+	// PreserveAll — режим cp -a
+	PreserveAll = PreserveMode | PreserveTimes | PreserveOwnership | PreserveLinks | PreserveXattr | PreserveSpecial
+)
+
+// preserveNames — имена атрибутов для --preserve
+var preserveNames = map[string]Preserve{
+	"mode":       PreserveMode,
+	"times":      PreserveTimes,
+	"timestamps": PreserveTimes,
+	"ownership":  PreserveOwnership,
+	"links":      PreserveLinks,
+	"xattr":      PreserveXattr,
+	"special":    PreserveSpecial,
+	"all":        PreserveAll,
+}
+
+// ParsePreserve разбирает список атрибутов через запятую, например "mode,times,links"
+func ParsePreserve(list string) (Preserve, error) {
+	var preserve Preserve
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		p, ok := preserveNames[name]
+		if !ok {
+			return 0, fmt.Errorf(i18n.T("fileops_unknown_preserve"), name)
+		}
+		preserve |= p
+	}
+	return preserve, nil
+}
+
+// CopyOptions задает режим копирования
+type CopyOptions struct {
+	Preserve Preserve
+	// Conflicts разрешает конфликты с существующими файлами (nil — файлы назначения заменяются)
+	Conflicts *Conflicts
+	// Resume продолжает прерванное копирование больших файлов с последнего проверенного смещения
+	Resume bool
+	// Verify сравнивает контрольные суммы источника и копии после копирования каждого файла
+	Verify bool
+	// Workers — число файлов директории, копируемых одновременно (0 — DefaultCopyWorkers)
+	Workers int
+	// BufferSize — размер буфера, если данные нельзя скопировать средствами ядра (0 — DefaultCopyBuffer)
+	BufferSize int
+}
+
+// DefaultCopyBuffer — размер буфера копирования по умолчанию
+const DefaultCopyBuffer = 1 << 20
+
+// DefaultCopyWorkers возвращает число одновременно копируемых файлов по умолчанию: копирование
+// упирается в диск, а не в процессор, поэтому рабочих вдвое больше ядер, но не больше 16
+func DefaultCopyWorkers() int {
+	return min(max(2*runtime.NumCPU(), 4), 16)
+}
+
+// DefaultCopyOptions — обычное копирование: содержимое и права доступа
+var DefaultCopyOptions = CopyOptions{Preserve: PreserveMode}
+
+// ArchiveCopyOptions — копирование со всеми атрибутами, как cp -a
+var ArchiveCopyOptions = CopyOptions{Preserve: PreserveAll}
+
+// Copy копирует файл или директорию со всем содержимым, сохраняя атрибуты из options.Preserve.
+// Содержимое директории копируется параллельно (см. copyTree). Права и время директории
+// устанавливаются после копирования ее содержимого, поэтому директории без права записи
+// и их время изменения копируются без потерь.
+func (f *FileOperator) Copy(source, destination string, options CopyOptions) error {
+	c := newCopier(options)
+	info, err := c.stat(source)
+	if err != nil {
+		return fmt.Errorf(i18n.T("fileops_stat_error"), source, err)
+	}
+	return c.copy(source, destination, info)
+}
+
+// copier хранит состояние одного копирования
+type copier struct {
+	options CopyOptions
+	// links — уже скопированные файлы с несколькими жесткими ссылками (устройство и inode → путь копии)
+	links map[[2]uint64]string
+}
+
+// newCopier создает копировщик с заданными параметрами
+func newCopier(options CopyOptions) *copier {
+	return &copier{options: options, links: make(map[[2]uint64]string)}
+}
+
+// has проверяет, нужно ли сохранять атрибут
+func (c *copier) has(p Preserve) bool {
+	return c.options.Preserve&p != 0
+}
+
+// workers возвращает число одновременно копируемых файлов
+func (c *copier) workers() int {
+	if c.options.Workers > 0 {
+		return c.options.Workers
+	}
+	return DefaultCopyWorkers()
+}
+
+// buffer выделяет буфер копирования заданного размера
+func (c *copier) buffer() []byte {
+	if c.options.BufferSize > 0 {
+		return make([]byte, c.options.BufferSize)
+	}
+	return make([]byte, DefaultCopyBuffer)
+}
+
+// stat возвращает сведения о файле; ссылки разыменовываются, только если их не нужно сохранять
+func (c *copier) stat(path string) (fs.FileInfo, error) {
+	if c.has(PreserveLinks) {
+		return os.Lstat(path)
+	}
+	return os.Stat(path)
+}
+
+// copiesContents сообщает, копируется ли запись чтением содержимого: это обычные файлы,
+// а также ссылки и специальные файлы, если их не нужно сохранять как есть
+func (c *copier) copiesContents(info fs.FileInfo) bool {
+	mode := info.Mode()
+	special := mode&(fs.ModeNamedPipe|fs.ModeDevice|fs.ModeSocket) != 0 && c.has(PreserveSpecial)
+	return !mode.IsDir() && mode&fs.ModeSymlink == 0 && !special
+}
+
+// copy копирует одну запись; директории копируются деревом
+func (c *copier) copy(source, destination string, info fs.FileInfo) error {
+	if info.IsDir() {
+		return c.copyTree(source, destination, info)
+	}
+	destination, err := c.options.Conflicts.resolve(source, destination, info, true)
+	if err != nil || destination == "" {
+		return err
+	}
+	if !c.copiesContents(info) {
+		return c.copySpecial(source, destination, info)
+	}
+	if first := c.linkTarget(destination, info); first != "" {
+		return linkTo(first, destination)
+	}
+	return c.copyFile(source, destination, info, c.buffer())
+}
+
+// copyFile копирует содержимое обычного файла и затем его атрибуты
+func (c *copier) copyFile(source, destination string, info fs.FileInfo, buf []byte) error {
+	if err := copyContents(source, destination, info, c.options.Resume, buf); err != nil {
+		return err
+	}
+	if c.options.Verify {
+		if err := verifyCopy(source, destination); err != nil {
+			return err
+		}
+	}
+	return c.copyAttributes(source, destination, info)
+}
+
+// copySpecial воссоздает символическую ссылку или специальный файл и переносит атрибуты
+func (c *copier) copySpecial(source, destination string, info fs.FileInfo) error {
+	var err error
+	if info.Mode()&fs.ModeSymlink != 0 {
+		err = c.copySymlink(source, destination)
+	} else {
+		if err = removeExisting(destination); err == nil {
+			err = makeSpecial(source, destination, info)
+		}
+		if err != nil {
+			err = fmt.Errorf(i18n.T("fileops_special_error"), destination, err)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return c.copyAttributes(source, destination, info)
+}
+
+// copySymlink создает символическую ссылку с тем же содержимым
+func (c *copier) copySymlink(source, destination string) error {
+	target, err := os.Readlink(source)
+	if err == nil {
+		err = removeExisting(destination)
+	}
+	if err == nil {
+		err = os.Symlink(target, destination)
+	}
+	if err != nil {
+		return fmt.Errorf(i18n.T("fileops_symlink_error"), source, err)
+	}
+	return nil
+}
+
+// linkTarget возвращает путь уже скопированного файла, жесткой ссылкой на который является
+// источник, или пустую строку, если содержимое нужно скопировать. Первая копия запоминается.
+func (c *copier) linkTarget(destination string, info fs.FileInfo) string {
+	if !c.has(PreserveLinks) {
+		return ""
+	}
+	st, ok := sysinfo.Stat(info)
+	if !ok || st.Nlink < 2 {
+		return ""
+	}
+	key := [2]uint64{st.Dev, st.Ino}
+	first, seen := c.links[key]
+	if !seen {
+		c.links[key] = destination
+		return ""
+	}
+	return first
+}
+
+// linkTo создает destination жесткой ссылкой на first
+func linkTo(first, destination string) error {
+	if err := removeExisting(destination); err != nil {
+		return fmt.Errorf(i18n.T("fileops_link_error"), destination, err)
+	}
+	if err := os.Link(first, destination); err != nil {
+		return fmt.Errorf(i18n.T("fileops_link_error"), destination, err)
+	}
+	return nil
+}
+
+// copyAttributes переносит атрибуты: сначала владельца (смена владельца сбрасывает setuid),
+// затем расширенные атрибуты и права, время — последним
+func (c *copier) copyAttributes(source, destination string, info fs.FileInfo) error {
+	isLink := info.Mode()&fs.ModeSymlink != 0
+	if c.has(PreserveOwnership) {
+		if st, ok := sysinfo.Stat(info); ok {
+			err := os.Lchown(destination, int(st.UID), int(st.GID))
+			if err != nil && !errors.Is(err, fs.ErrPermission) {
+				return fmt.Errorf(i18n.T("fileops_attr_error"), destination, err)
+			}
+		}
+	}
+	if c.has(PreserveXattr) {
+		if err := copyXattrs(source, destination); err != nil {
+			return fmt.Errorf(i18n.T("fileops_attr_error"), destination, err)
+		}
+	}
+	// Права символической ссылки системой не используются. Права директории восстанавливаются
+	// всегда: при создании к ним добавлялось право записи
+	if !isLink && (c.has(PreserveMode) || info.IsDir()) {
+		mode := info.Mode().Perm()
+		if c.has(PreserveMode) {
+			mode |= info.Mode() & (fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky)
+		}
+		if err := os.Chmod(destination, mode); err != nil {
+			return fmt.Errorf(i18n.T("fileops_chmod_error"), err)
+		}
+	}
+	if c.has(PreserveTimes) {
+		atime := times.Get(info).AccessTime()
+		var err error
+		if isLink {
+			err = setLinkTimes(destination, atime, info.ModTime())
+		} else {
+			err = os.Chtimes(destination, atime, info.ModTime())
+		}
+		if err != nil {
+			return fmt.Errorf(i18n.T("fileops_attr_error"), destination, err)
+		}
+	}
+	return nil
+}
+
+// removeExisting удаляет запись назначения, которую нельзя перезаписать на месте
+// (ссылку или специальный файл); директории не удаляются
+func removeExisting(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if info.IsDir() {
+		return &fs.PathError{Op: "remove", Path: path, Err: fs.ErrExist}
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,21 @@
+//go:build !unix
+
+package fileops
+
+import (
+	"errors"
+	"io/fs"
+	"time"
+
+	"file-manager/internal/i18n"
+)
+
+// makeSpecial на этой платформе не поддерживается
+func makeSpecial(_, _ string, _ fs.FileInfo) error {
+	return errors.New(i18n.T("fileops_special_unsupported"))
+}
+
+// setLinkTimes на этой платформе ничего не делает: время ссылки изменить нельзя
+func setLinkTimes(_ string, _, _ time.Time) error {
+	return nil
+}
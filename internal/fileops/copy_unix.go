@@ -0,0 +1,28 @@
+//go:build unix
+
+package fileops
+
+import (
+	"io/fs"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// makeSpecial создает именованный канал или файл устройства по образцу source
+func makeSpecial(source, destination string, info fs.FileInfo) error {
+	if info.Mode()&fs.ModeNamedPipe != 0 {
+		return unix.Mkfifo(destination, uint32(info.Mode().Perm()))
+	}
+	var st unix.Stat_t
+	if err := unix.Lstat(source, &st); err != nil {
+		return err
+	}
+	return mknod(destination, uint32(st.Mode), uint64(st.Rdev))
+}
+
+// setLinkTimes устанавливает время самой символической ссылки, не переходя по ней
+func setLinkTimes(path string, atime, mtime time.Time) error {
+	ts := []unix.Timespec{unix.NsecToTimespec(atime.UnixNano()), unix.NsecToTimespec(mtime.UnixNano())}
+	return unix.UtimesNanoAt(unix.AT_FDCWD, path, ts, unix.AT_SYMLINK_NOFOLLOW)
+}
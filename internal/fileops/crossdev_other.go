@@ -0,0 +1,8 @@
+//go:build !unix && !windows
+
+package fileops
+
+// isCrossDevice на этой платформе не распознает ошибку разных файловых систем
+func isCrossDevice(_ error) bool {
+	return false
+}
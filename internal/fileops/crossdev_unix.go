@@ -0,0 +1,14 @@
+//go:build unix
+
+package fileops
+
+import (
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+// isCrossDevice сообщает, что переименование не удалось из-за разных файловых систем
+func isCrossDevice(err error) bool {
+	return errors.Is(err, unix.EXDEV)
+}
@@ -0,0 +1,327 @@
+package fileops
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"file-manager/internal/i18n"
+	"file-manager/internal/ignore"
+	"file-manager/internal/sysinfo"
+)
+
+// dupeBlock — размер начального и конечного блоков для частичного хеша
+const dupeBlock = 4 << 10
+
+// DupeFile — один из одинаковых файлов
+type DupeFile struct {
+	Path    string
+	ModTime time.Time
+}
+
+// DupeGroup — файлы с одинаковым содержимым, отсортированные по пути
+type DupeGroup struct {
+	Size  int64
+	Files []DupeFile
+}
+
+// Wasted возвращает место, которое занимают лишние копии
+func (g DupeGroup) Wasted() int64 {
+	return g.Size * int64(len(g.Files)-1)
+}
+
+// DupeOptions задает поиск дубликатов
+type DupeOptions struct {
+	// MinSize — минимальный размер файла; пустые файлы не сравниваются никогда
+	MinSize int64
+	// Workers — число одновременно хешируемых файлов (0 — DefaultCopyWorkers)
+	Workers int
+	// Rules (может быть nil) исключает записи по правилам ignore-файлов
+	Rules *ignore.Matcher
+}
+
+// DupeKeep — какая копия группы остается на месте
+type DupeKeep string
+
+const (
+	// DupeKeepOldest оставляет файл с самым ранним временем изменения
+	DupeKeepOldest DupeKeep = "oldest"
+	// DupeKeepNewest оставляет файл с самым поздним временем изменения
+	DupeKeepNewest DupeKeep = "newest"
+	// DupeKeepShortest оставляет файл с самым коротким путем
+	DupeKeepShortest DupeKeep = "shortest"
+)
+
+// ParseDupeKeep разбирает правило выбора оставляемой копии
+func ParseDupeKeep(value string) (DupeKeep, error) {
+	switch keep := DupeKeep(strings.ToLower(value)); keep {
+	case DupeKeepOldest, DupeKeepNewest, DupeKeepShortest:
+		return keep, nil
+	}
+	return "", fmt.Errorf(i18n.T("dupes_bad_keep"), value)
+}
+
+// DupeAction — что делать с лишними копиями
+type DupeAction int
+
+const (
+	// DupeTrash отправляет лишние копии в корзину
+	DupeTrash DupeAction = iota
+	// DupeHardlink заменяет лишние копии жесткими ссылками на оставляемый файл
+	DupeHardlink
+	// DupeSymlink заменяет лишние копии символическими ссылками на оставляемый файл
+	DupeSymlink
+)
+
+// Keeper возвращает индекс копии, которая остается на месте. При равенстве выбирается
+// файл, идущий раньше по пути.
+func (g DupeGroup) Keeper(keep DupeKeep) int {
+	best := 0
+	for i := 1; i < len(g.Files); i++ {
+		a, b := g.Files[i], g.Files[best]
+		var better bool
+		switch keep {
+		case DupeKeepNewest:
+			better = a.ModTime.After(b.ModTime)
+		case DupeKeepShortest:
+			better = len(a.Path) < len(b.Path)
+		default:
+			better = a.ModTime.Before(b.ModTime)
+		}
+		if better {
+			best = i
+		}
+	}
+	return best
+}
+
+// FindDupes ищет в дереве root файлы с одинаковым содержимым. Кандидаты отбираются по размеру,
+// затем по SHA-256 первого и последнего блоков и только потом по SHA-256 всего файла; хеши
+// считаются параллельно. Символические ссылки не разыменовываются, жесткие ссылки на один
+// файл дубликатами не считаются, недоступные директории пропускаются. Группы упорядочены
+// по убыванию занятого лишними копиями места.
+func (f *FileOperator) FindDupes(root string, options DupeOptions) ([]DupeGroup, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("fileops_stat_error"), root, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf(i18n.T("compare_not_dir"), root)
+	}
+	workers := options.Workers
+	if workers <= 0 {
+		workers = DefaultCopyWorkers()
+	}
+
+	bySize := make(map[int64][]DupeFile)
+	seen := make(map[[2]uint64]bool)
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if entry != nil && entry.IsDir() && path != root {
+				return fs.SkipDir // Пропускаем директории, к которым нет доступа
+			}
+			return err
+		}
+		if path != root && options.Rules != nil && options.Rules.Ignored(path, entry.IsDir()) {
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil || info.Size() == 0 || info.Size() < options.MinSize {
+			return nil
+		}
+		if stat, ok := sysinfo.Stat(info); ok && stat.Nlink > 1 {
+			key := [2]uint64{stat.Dev, stat.Ino}
+			if seen[key] {
+				return nil
+			}
+			seen[key] = true
+		}
+		bySize[info.Size()] = append(bySize[info.Size()], DupeFile{Path: path, ModTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("fileops_read_dir_error"), root, err)
+	}
+
+	var candidates []DupeGroup
+	for size, files := range bySize {
+		if len(files) > 1 {
+			candidates = append(candidates, DupeGroup{Size: size, Files: files})
+		}
+	}
+	// Файлы не больше двух блоков частичный хеш читает целиком
+	candidates = splitByHash(candidates, workers, partialChecksum)
+	var groups, large []DupeGroup
+	for _, group := range candidates {
+		if group.Size <= 2*dupeBlock {
+			groups = append(groups, group)
+		} else {
+			large = append(large, group)
+		}
+	}
+	groups = append(groups, splitByHash(large, workers, func(path string, _ int64) ([]byte, error) {
+		return fileChecksum(path)
+	})...)
+
+	for _, group := range groups {
+		sort.Slice(group.Files, func(i, j int) bool { return group.Files[i].Path < group.Files[j].Path })
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if wi, wj := groups[i].Wasted(), groups[j].Wasted(); wi != wj {
+			return wi > wj
+		}
+		return groups[i].Files[0].Path < groups[j].Files[0].Path
+	})
+	return groups, nil
+}
+
+// splitByHash хеширует файлы групп заданным числом рабочих и разбивает группы по значению хеша.
+// Файлы, которые не удалось прочитать, исключаются; остаются только группы из двух и более файлов.
+func splitByHash(groups []DupeGroup, workers int, checksum func(path string, size int64) ([]byte, error)) []DupeGroup {
+	type job struct{ group, file int }
+	sums := make([][][]byte, len(groups))
+	jobs := make(chan job)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				// Каждый рабочий пишет в свою ячейку, поэтому блокировка не нужна
+				sum, err := checksum(groups[j.group].Files[j.file].Path, groups[j.group].Size)
+				if err == nil {
+					sums[j.group][j.file] = sum
+				}
+			}
+		}()
+	}
+	for g, group := range groups {
+		sums[g] = make([][]byte, len(group.Files))
+		for i := range group.Files {
+			jobs <- job{g, i}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	var result []DupeGroup
+	for g, group := range groups {
+		byHash := make(map[string][]DupeFile)
+		var order []string
+		for i, file := range group.Files {
+			if sums[g][i] == nil {
+				continue
+			}
+			key := string(sums[g][i])
+			if byHash[key] == nil {
+				order = append(order, key)
+			}
+			byHash[key] = append(byHash[key], file)
+		}
+		for _, key := range order {
+			if files := byHash[key]; len(files) > 1 {
+				result = append(result, DupeGroup{Size: group.Size, Files: files})
+			}
+		}
+	}
+	return result
+}
+
+// partialChecksum вычисляет SHA-256 первого и последнего блоков файла (небольшой файл — целиком)
+func partialChecksum(path string, size int64) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+	hash := sha256.New()
+	if size <= 2*dupeBlock {
+		_, err = io.Copy(hash, file)
+	} else {
+		_, err = io.Copy(hash, io.NewSectionReader(file, 0, dupeBlock))
+		if err == nil {
+			_, err = io.Copy(hash, io.NewSectionReader(file, size-dupeBlock, dupeBlock))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
+
+// DedupeSummary — итог удаления дубликатов
+type DedupeSummary struct {
+	Files int
+	// Bytes — освобожденное место
+	Bytes int64
+}
+
+// Dedupe оставляет в каждой группе одну копию по правилу keep, а остальные отправляет в корзину
+// или заменяет ссылками на нее. Перед заменой каждая копия сверяется с оставляемой по содержимому;
+// изменившиеся после поиска файлы пропускаются. Ссылка создается под временным именем и атомарно
+// заменяет копию, поэтому при ошибке файл остается на месте.
+func (f *FileOperator) Dedupe(groups []DupeGroup, action DupeAction, keep DupeKeep) (DedupeSummary, error) {
+	var summary DedupeSummary
+	for _, group := range groups {
+		keeper := group.Files[group.Keeper(keep)].Path
+		for _, file := range group.Files {
+			if file.Path == keeper {
+				continue
+			}
+			same, err := sameContent(keeper, file.Path)
+			if err != nil {
+				return summary, fmt.Errorf(i18n.T("fileops_checksum_error"), err)
+			}
+			if !same {
+				continue
+			}
+			if err := f.dedupeFile(keeper, file.Path, action); err != nil {
+				return summary, err
+			}
+			summary.Files++
+			summary.Bytes += group.Size
+		}
+	}
+	return summary, nil
+}
+
+// dedupeFile убирает копию path файла keeper
+func (f *FileOperator) dedupeFile(keeper, path string, action DupeAction) error {
+	if action == DupeTrash {
+		return f.DeleteFile(path)
+	}
+	temp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".fm-dedupe")
+	_ = os.Remove(temp)
+	var err error
+	if action == DupeHardlink {
+		err = os.Link(keeper, temp)
+	} else {
+		target := keeper
+		if rel, relErr := filepath.Rel(filepath.Dir(path), keeper); relErr == nil {
+			target = rel
+		}
+		err = os.Symlink(target, temp)
+	}
+	if err == nil {
+		if err = os.Rename(temp, path); err != nil {
+			_ = os.Remove(temp)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf(i18n.T("dupes_link_error"), path, err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+//go:build linux
+
+package fileops
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// maxCopyRange — наибольший объем одного вызова copy_file_range
+const maxCopyRange = 1 << 30
+
+// cloneFile делает dst клоном src (FICLONE): на Btrfs, XFS и других файловых системах
+// с reflink копия разделяет блоки с источником и создается мгновенно. Возвращает false,
+// если клонирование не поддерживается, например источник и копия на разных файловых системах.
+func cloneFile(dst, src *os.File) bool {
+	return unix.IoctlFileClone(int(dst.Fd()), int(src.Fd())) == nil
+}
+
+// copyData копирует до limit байт (все данные при limit < 0) с текущих позиций файлов через
+// copy_file_range, не передавая данные через память процесса. Если ядро или файловая система
+// не поддерживают copy_file_range, данные копируются через buf.
+func copyData(dst, src *os.File, limit int64, buf []byte) (int64, error) {
+	var written int64
+	for limit < 0 || written < limit {
+		chunk := maxCopyRange
+		if limit >= 0 && limit-written < int64(chunk) {
+			chunk = int(limit - written)
+		}
+		n, err := unix.CopyFileRange(int(src.Fd()), nil, int(dst.Fd()), nil, chunk, 0)
+		if errors.Is(err, unix.EINTR) {
+			continue
+		}
+		// Некоторые файловые системы (procfs, sysfs) возвращают 0 для непустых файлов
+		if unsupportedCopyRange(err) || (err == nil && n == 0 && written == 0) {
+			rest := int64(-1)
+			if limit >= 0 {
+				rest = limit - written
+			}
+			n, err := copyBuffered(dst, src, rest, buf)
+			return written + n, err
+		}
+		if err != nil {
+			return written, err
+		}
+		if n == 0 {
+			break
+		}
+		written += int64(n)
+	}
+	return written, nil
+}
+
+// unsupportedCopyRange сообщает, что copy_file_range неприменим к этой паре файлов
+func unsupportedCopyRange(err error) bool {
+	return errors.Is(err, unix.ENOSYS) || errors.Is(err, unix.EXDEV) || errors.Is(err, unix.EINVAL) ||
+		errors.Is(err, unix.EOPNOTSUPP) || errors.Is(err, unix.EPERM) || errors.Is(err, unix.EBADF)
+}
+
+// syncFilesystem одним вызовом syncfs сбрасывает на диск все данные файловой системы,
+// на которой находится dir
+func syncFilesystem(dir string) bool {
+	file, err := os.Open(dir)
+	if err != nil {
+		return false
+	}
+	defer func() { _ = file.Close() }()
+	return unix.Syncfs(int(file.Fd())) == nil
+}
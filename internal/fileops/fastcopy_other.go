@@ -0,0 +1,20 @@
+//go:build !linux
+
+package fileops
+
+import "os"
+
+// cloneFile на этой платформе не поддерживается
+func cloneFile(dst, src *os.File) bool {
+	return false
+}
+
+// copyData копирует до limit байт (все данные при limit < 0) с текущих позиций файлов через buf
+func copyData(dst, src *os.File, limit int64, buf []byte) (int64, error) {
+	return copyBuffered(dst, src, limit, buf)
+}
+
+// syncFilesystem на этой платформе не поддерживается: файлы сбрасываются на диск по одному
+func syncFilesystem(dir string) bool {
+	return false
+}
@@ -2,9 +2,7 @@ package fileops
 
 import (
 	"fmt"
-	"io"
 	"os"
-	"path/filepath"
 
 	"file-manager/internal/i18n"
 )
@@ -44,103 +42,36 @@ func (f *FileOperator) CreateDirectory(path string) error {
 	return nil
 }
 
-// CopyFile копирует файл из source в destination
+// CopyFile копирует файл из source в destination вместе с правами доступа
 func (f *FileOperator) CopyFile(source, destination string) error {
-	// Открываем исходный файл
-	src, err := os.Open(source)
-	if err != nil {
-		return fmt.Errorf(i18n.T("fileops_open_source_error"), source, err)
-	}
-	defer func() {
-		if err := src.Close(); err != nil {
-			panic(fmt.Errorf(i18n.T("fileops_close_source_error"), source, err))
-		}
-	}()
-
-	// Создаем файл назначения
-	dst, err := os.Create(destination)
-	if err != nil {
-		return fmt.Errorf(i18n.T("fileops_create_dest_error"), destination, err)
-	}
-	defer func() {
-		if err := dst.Close(); err != nil {
-			panic(fmt.Errorf(i18n.T("fileops_close_dest_error"), destination, err))
-		}
-	}()
-
-	// Копируем содержимое
-	_, err = io.Copy(dst, src)
-	if err != nil {
-		return fmt.Errorf(i18n.T("fileops_copy_content_error"), err)
-	}
-
-	// Получаем информацию о разрешениях исходного файла
-	srcInfo, err := os.Stat(source)
-	if err != nil {
-		return fmt.Errorf(i18n.T("fileops_stat_error"), source, err)
-	}
-
-	// Копируем разрешения
-	err = os.Chmod(destination, srcInfo.Mode())
-	if err != nil {
-		return fmt.Errorf(i18n.T("fileops_chmod_error"), err)
-	}
-
-	return nil
+	return f.Copy(source, destination, DefaultCopyOptions)
 }
 
 // CopyDirectory рекурсивно копирует директорию из source в destination
 func (f *FileOperator) CopyDirectory(source, destination string) error {
-	// Получаем информацию об исходной директории
-	srcInfo, err := os.Stat(source)
-	if err != nil {
-		return fmt.Errorf(i18n.T("fileops_stat_dir_error"), source, err)
-	}
-
-	// Создаем директорию назначения с теми же разрешениями
-	err = os.MkdirAll(destination, srcInfo.Mode())
-	if err != nil {
-		return fmt.Errorf(i18n.T("fileops_create_dir_error"), destination, err)
-	}
-
-	// Читаем содержимое исходной директории
-	entries, err := os.ReadDir(source)
-	if err != nil {
-		return fmt.Errorf(i18n.T("fileops_read_dir_error"), source, err)
-	}
-
-	// Копируем каждую запись
-	for _, entry := range entries {
-		sourcePath := filepath.Join(source, entry.Name())
-		destPath := filepath.Join(destination, entry.Name())
-
-		fileInfo, err := os.Stat(sourcePath)
-		if err != nil {
-			return fmt.Errorf(i18n.T("fileops_stat_error"), sourcePath, err)
-		}
-
-		if fileInfo.IsDir() {
-			// Рекурсивно копируем директорию
-			if err = f.CopyDirectory(sourcePath, destPath); err != nil {
-				return err
-			}
-		} else {
-			// Копируем файл
-			if err = f.CopyFile(sourcePath, destPath); err != nil {
-				return err
-			}
-		}
-	}
-
-	return nil
+	return f.Copy(source, destination, DefaultCopyOptions)
 }
 
-// MoveFile перемещает файл или директорию
+// MoveFile перемещает файл или директорию, заменяя существующий файл назначения
 func (f *FileOperator) MoveFile(source, destination string) error {
-	err := os.Rename(source, destination)
+	return f.Move(source, destination, nil)
+}
+
+// Move перемещает файл или директорию, разрешая конфликт с существующим файлом
+// назначения по правилам conflicts (nil — файл назначения заменяется). Между файловыми
+// системами перемещение выполняется проверенным копированием с удалением источника.
+func (f *FileOperator) Move(source, destination string, conflicts *Conflicts) error {
+	info, err := os.Lstat(source)
 	if err != nil {
 		return fmt.Errorf(i18n.T("fileops_move_error"), source, destination, err)
 	}
+	target, err := conflicts.resolve(source, destination, info, true)
+	if err != nil || target == "" {
+		return err
+	}
+	if err := movePath(source, target); err != nil {
+		return fmt.Errorf(i18n.T("fileops_move_error"), source, target, err)
+	}
 	return nil
 }
 
@@ -149,11 +80,29 @@ func (f *FileOperator) DeleteFile(path string) error {
 	return f.SoftDeleter.MoveToTrash(path)
 }
 
-// DeleteDirectory рекурсивно удаляет директорию
+// DeleteDirectory отправляет директорию вместе с содержимым в корзину (soft-delete)
 func (f *FileOperator) DeleteDirectory(path string) error {
-	err := os.RemoveAll(path)
+	info, err := os.Lstat(path)
 	if err != nil {
+		return fmt.Errorf(i18n.T("fileops_stat_error"), path, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf(i18n.T("fileops_not_dir"), path)
+	}
+	if err := f.SoftDeleter.MoveToTrash(path); err != nil {
 		return fmt.Errorf(i18n.T("fileops_delete_dir_error"), path, err)
 	}
 	return nil
 }
+
+// Purge безвозвратно удаляет файл или директорию вместе с содержимым, минуя корзину.
+// Директории без права записи удаляются тоже.
+func (f *FileOperator) Purge(path string) error {
+	if _, err := os.Lstat(path); err != nil {
+		return fmt.Errorf(i18n.T("fileops_stat_error"), path, err)
+	}
+	if err := removeTree(path); err != nil {
+		return fmt.Errorf(i18n.T("fileops_purge_error"), path, err)
+	}
+	return nil
+}
@@ -1,11 +1,20 @@
 package fileops
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
+
+	"file-manager/internal/glob"
+	"file-manager/internal/sysinfo"
 )
 
 func TestFileOperator(t *testing.T) {
@@ -186,8 +195,9 @@ func TestFileOperator(t *testing.T) {
 		}
 	})
 
-	// Тест на удаление директории
+	// Тест на удаление директории в корзину
 	t.Run("DeleteDirectory", func(t *testing.T) {
+		t.Setenv("HOME", filepath.Join(tempDir, "home"))
 		// Создаем директорию для удаления
 		dirToDelete := filepath.Join(tempDir, "dir_to_delete")
 		err := os.Mkdir(dirToDelete, 0755)
@@ -208,10 +218,48 @@ func TestFileOperator(t *testing.T) {
 			t.Errorf("не удалось удалить директорию: %v", err)
 		}
 
-		// Проверяем, что директория удалена
+		// Проверяем, что директория удалена и попала в корзину вместе с содержимым
 		if _, err := os.Stat(dirToDelete); !os.IsNotExist(err) {
 			t.Error("директория не была удалена")
 		}
+		if runtime.GOOS == "linux" {
+			trashed := filepath.Join(tempDir, "home", ".local", "share", "Trash", "files", "dir_to_delete", "file.txt")
+			if _, err := os.Stat(trashed); err != nil {
+				t.Errorf("директория не попала в корзину: %v", err)
+			}
+		}
+
+		notDir := filepath.Join(tempDir, "not_dir.txt")
+		if err := os.WriteFile(notDir, []byte("file"), 0644); err != nil {
+			t.Fatalf("не удалось создать файл: %v", err)
+		}
+		if err := fileOperator.DeleteDirectory(notDir); err == nil {
+			t.Error("ожидалась ошибка для файла")
+		}
+	})
+
+	// Тест на безвозвратное удаление
+	t.Run("Purge", func(t *testing.T) {
+		dirToPurge := filepath.Join(tempDir, "dir_to_purge")
+		if err := os.MkdirAll(filepath.Join(dirToPurge, "locked"), 0755); err != nil {
+			t.Fatalf("не удалось создать директорию: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dirToPurge, "locked", "file.txt"), []byte("x"), 0644); err != nil {
+			t.Fatalf("не удалось создать файл: %v", err)
+		}
+		// Директория без права записи тоже удаляется
+		if err := os.Chmod(filepath.Join(dirToPurge, "locked"), 0555); err != nil {
+			t.Fatalf("не удалось изменить права: %v", err)
+		}
+		if err := fileOperator.Purge(dirToPurge); err != nil {
+			t.Errorf("не удалось удалить директорию: %v", err)
+		}
+		if _, err := os.Stat(dirToPurge); !os.IsNotExist(err) {
+			t.Error("директория не была удалена")
+		}
+		if err := fileOperator.Purge(dirToPurge); err == nil {
+			t.Error("ожидалась ошибка для несуществующего пути")
+		}
 	})
 }
 
@@ -458,92 +506,1941 @@ func TestArchiver(t *testing.T) {
 			t.Error("содержимое распакованного файла не соответствует исходному")
 		}
 	})
+
+	// Тест на учет ignore-файлов при архивации
+	t.Run("ArchiveFiles_Ignore", func(t *testing.T) {
+		t.Setenv("HOME", tempDir)
+		if err := os.WriteFile(filepath.Join(tempDir, "subdir", ".fmignore"), []byte("file3.txt\n"), 0644); err != nil {
+			t.Fatalf("не удалось создать .fmignore: %v", err)
+		}
+		defer func() { _ = os.Remove(filepath.Join(tempDir, "subdir", ".fmignore")) }()
+
+		tarFile := filepath.Join(tempDir, "ignore.tar")
+		if err := archiver.WithIgnore(true).ArchiveFiles([]string{filepath.Join(tempDir, "subdir")}, tarFile, "tar"); err != nil {
+			t.Fatalf("не удалось создать tar-архив: %v", err)
+		}
+		contents, err := archiver.ListArchiveContents(tarFile)
+		if err != nil {
+			t.Fatalf("не удалось получить содержимое архива: %v", err)
+		}
+		if strings.Join(contents, ",") != ".fmignore" {
+			t.Errorf("исключенный файл не должен попадать в архив, получено %v", contents)
+		}
+		if archiver.RespectIgnore {
+			t.Error("WithIgnore не должен изменять исходный Archiver")
+		}
+	})
 }
 
-func TestPermissionsManager(t *testing.T) {
-	// Создаем временную директорию для тестов
-	tempDir, err := os.MkdirTemp("", "permissions_test")
-	if err != nil {
-		t.Fatalf("не удалось создать временную директорию: %v", err)
+func TestDiskUsage(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]int{"a.bin": 100, "sub/b.bin": 200, "sub/deep/c.bin": 300, "skip/d.bin": 1000}
+	for name, size := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("не удалось создать директорию: %v", err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatalf("не удалось создать файл %s: %v", name, err)
+		}
 	}
-	defer func() {
-		err := os.RemoveAll(tempDir)
+	fileOperator := NewFileOperator()
+
+	t.Run("Размеры директорий", func(t *testing.T) {
+		usage, err := fileOperator.DiskUsage(tempDir, 1, nil)
 		if err != nil {
-			t.Errorf("ошибка при удалении временной директории: %v", err)
+			t.Fatalf("ошибка при подсчете размера: %v", err)
 		}
-	}()
+		if len(usage) != 3 {
+			t.Fatalf("ожидалось 3 записи (skip, sub и корень), получено %v", usage)
+		}
+		root := usage[len(usage)-1]
+		if root.Path != tempDir || root.Size != 1600 || root.Files != 4 {
+			t.Errorf("неверный итог для корня: %+v", root)
+		}
+		if usage[1].Path != filepath.Join(tempDir, "sub") || usage[1].Size != 500 {
+			t.Errorf("неверный размер sub: %+v", usage[1])
+		}
+	})
 
-	// Создаем тестовый файл
-	testFile := filepath.Join(tempDir, "test_permissions.txt")
-	err = os.WriteFile(testFile, []byte("test content"), 0644)
-	if err != nil {
-		t.Fatalf("не удалось создать тестовый файл: %v", err)
+	t.Run("Жесткие ссылки учитываются один раз", func(t *testing.T) {
+		link := filepath.Join(tempDir, "sub", "link.bin")
+		if err := os.Link(filepath.Join(tempDir, "a.bin"), link); err != nil {
+			t.Skipf("жесткие ссылки не поддерживаются: %v", err)
+		}
+		defer func() { _ = os.Remove(link) }()
+		usage, err := fileOperator.DiskUsage(tempDir, 0, nil)
+		if err != nil {
+			t.Fatalf("ошибка при подсчете размера: %v", err)
+		}
+		if len(usage) != 1 || usage[0].Size != 1600 {
+			t.Errorf("ожидался размер 1600 без повторного учета ссылки, получено %v", usage)
+		}
+	})
+
+	t.Run("Несуществующий путь", func(t *testing.T) {
+		if _, err := fileOperator.DiskUsage(filepath.Join(tempDir, "missing"), -1, nil); err == nil {
+			t.Error("ожидалась ошибка для несуществующего пути")
+		}
+	})
+}
+
+func TestCopyOptions(t *testing.T) {
+	tempDir := t.TempDir()
+	source := filepath.Join(tempDir, "src")
+	for _, name := range []string{"file.txt", "locked/inner.txt"} {
+		path := filepath.Join(source, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("не удалось создать директорию: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0640); err != nil {
+			t.Fatalf("не удалось создать файл %s: %v", name, err)
+		}
+	}
+	if err := os.Symlink("file.txt", filepath.Join(source, "link")); err != nil {
+		t.Fatalf("не удалось создать символическую ссылку: %v", err)
+	}
+	if err := os.Link(filepath.Join(source, "file.txt"), filepath.Join(source, "hard.txt")); err != nil {
+		t.Fatalf("не удалось создать жесткую ссылку: %v", err)
+	}
+	old := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, name := range []string{"file.txt", "locked/inner.txt", "locked"} {
+		if err := os.Chtimes(filepath.Join(source, name), old, old); err != nil {
+			t.Fatalf("не удалось изменить время %s: %v", name, err)
+		}
+	}
+	if err := os.Chmod(filepath.Join(source, "locked"), 0500); err != nil {
+		t.Fatalf("не удалось изменить права: %v", err)
 	}
+	defer func() { _ = os.Chmod(filepath.Join(source, "locked"), 0755) }()
+	fileOperator := NewFileOperator()
 
-	// Инициализируем PermissionsManager
-	permissionsManager := NewPermissionsManager()
+	t.Run("Режим архива", func(t *testing.T) {
+		dest := filepath.Join(tempDir, "archive")
+		if err := fileOperator.Copy(source, dest, ArchiveCopyOptions); err != nil {
+			t.Fatalf("ошибка копирования: %v", err)
+		}
+		defer func() { _ = os.Chmod(filepath.Join(dest, "locked"), 0755) }()
 
-	// Тест на изменение прав доступа
-	t.Run("ChangePermissions", func(t *testing.T) {
-		// Изменяем права на "только чтение"
-		err := permissionsManager.ChangePermissions(testFile, "444")
+		info, err := os.Lstat(filepath.Join(dest, "file.txt"))
 		if err != nil {
-			t.Errorf("не удалось изменить права доступа: %v", err)
+			t.Fatalf("файл не скопирован: %v", err)
+		}
+		if info.Mode().Perm() != 0640 || !info.ModTime().Equal(old) {
+			t.Errorf("права или время файла не сохранены: %v %v", info.Mode(), info.ModTime())
+		}
+		if target, err := os.Readlink(filepath.Join(dest, "link")); err != nil || target != "file.txt" {
+			t.Errorf("символическая ссылка должна копироваться как ссылка, получено %q, %v", target, err)
+		}
+		hard, err := os.Stat(filepath.Join(dest, "hard.txt"))
+		if err != nil || !os.SameFile(info, hard) {
+			t.Errorf("жесткая ссылка должна указывать на тот же файл копии: %v", err)
+		}
+		dir, err := os.Stat(filepath.Join(dest, "locked"))
+		if err != nil {
+			t.Fatalf("директория не скопирована: %v", err)
+		}
+		if dir.Mode().Perm() != 0500 || !dir.ModTime().Equal(old) {
+			t.Errorf("права или время директории не сохранены: %v %v", dir.Mode(), dir.ModTime())
+		}
+		if _, err := os.Stat(filepath.Join(dest, "locked", "inner.txt")); err != nil {
+			t.Errorf("содержимое директории без права записи не скопировано: %v", err)
 		}
+	})
 
-		// Проверяем, что права изменились
-		fileInfo, err := os.Stat(testFile)
+	t.Run("Обычное копирование", func(t *testing.T) {
+		dest := filepath.Join(tempDir, "plain")
+		if err := fileOperator.Copy(source, dest, DefaultCopyOptions); err != nil {
+			t.Fatalf("ошибка копирования: %v", err)
+		}
+		defer func() { _ = os.Chmod(filepath.Join(dest, "locked"), 0755) }()
+		link, err := os.Lstat(filepath.Join(dest, "link"))
+		if err != nil || link.Mode()&os.ModeSymlink != 0 {
+			t.Errorf("без сохранения ссылок копируется содержимое файла: %v", err)
+		}
+		file, err := os.Stat(filepath.Join(dest, "file.txt"))
+		if err != nil || file.ModTime().Equal(old) || file.Mode().Perm() != 0640 {
+			t.Errorf("обычное копирование сохраняет только права: %v", err)
+		}
+	})
+
+	t.Run("Именованный канал", func(t *testing.T) {
+		fifo := filepath.Join(tempDir, "fifo")
+		if err := exec.Command("mkfifo", fifo).Run(); err != nil {
+			t.Skipf("именованные каналы не поддерживаются: %v", err)
+		}
+		dest := filepath.Join(tempDir, "fifo_copy")
+		if err := fileOperator.Copy(fifo, dest, ArchiveCopyOptions); err != nil {
+			t.Fatalf("ошибка копирования канала: %v", err)
+		}
+		info, err := os.Lstat(dest)
+		if err != nil || info.Mode()&os.ModeNamedPipe == 0 {
+			t.Errorf("канал должен создаваться заново, а не читаться: %v", err)
+		}
+	})
+
+	t.Run("ParsePreserve", func(t *testing.T) {
+		preserve, err := ParsePreserve("mode, Times,links")
+		if err != nil || preserve != PreserveMode|PreserveTimes|PreserveLinks {
+			t.Errorf("неверный разбор списка атрибутов: %v, %v", preserve, err)
+		}
+		if _, err := ParsePreserve("mode,color"); err == nil {
+			t.Error("ожидалась ошибка для неизвестного атрибута")
+		}
+	})
+}
+
+func TestConflicts(t *testing.T) {
+	tempDir := t.TempDir()
+	write := func(name, content string, mtime time.Time) string {
+		t.Helper()
+		path := filepath.Join(tempDir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("не удалось создать файл %s: %v", name, err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatalf("не удалось изменить время %s: %v", name, err)
+		}
+		return path
+	}
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(tempDir, name))
 		if err != nil {
-			t.Fatalf("не удалось получить информацию о файле: %v", err)
+			t.Fatalf("не удалось прочитать %s: %v", name, err)
+		}
+		return string(data)
+	}
+	older, newer := time.Now().Add(-time.Hour), time.Now()
+	fileOperator := NewFileOperator()
+	copyWith := func(conflicts *Conflicts, source, destination string) {
+		t.Helper()
+		if err := fileOperator.Copy(source, destination, CopyOptions{Preserve: PreserveMode, Conflicts: conflicts}); err != nil {
+			t.Fatalf("ошибка копирования: %v", err)
 		}
+	}
 
-		// Сравниваем права доступа (в восьмеричной системе)
-		expectedMode := os.FileMode(0444)
-		if fileInfo.Mode().Perm() != expectedMode {
-			t.Errorf("неверные права доступа: получено %o, ожидалось %o", fileInfo.Mode().Perm(), expectedMode)
+	t.Run("Пропуск и более новый", func(t *testing.T) {
+		source := write("src.txt", "new", older)
+		dest := write("dst.txt", "old", newer)
+		copyWith(&Conflicts{Policy: ConflictSkip}, source, dest)
+		copyWith(&Conflicts{Policy: ConflictKeepNewer}, source, dest)
+		if read("dst.txt") != "old" {
+			t.Error("более новый файл назначения не должен заменяться")
+		}
+		if err := os.Chtimes(source, newer.Add(time.Minute), newer.Add(time.Minute)); err != nil {
+			t.Fatalf("не удалось изменить время: %v", err)
+		}
+		copyWith(&Conflicts{Policy: ConflictKeepNewer}, source, dest)
+		if read("dst.txt") != "new" {
+			t.Error("более новый источник должен заменять файл назначения")
 		}
 	})
 
-	// Тест на получение прав доступа
-	t.Run("GetPermissions", func(t *testing.T) {
-		// Сначала устанавливаем известные права доступа
-		err := os.Chmod(testFile, 0644)
+	t.Run("Переименование", func(t *testing.T) {
+		source := write("doc.txt", "a", older)
+		dest := write("report.txt", "b", older)
+		conflicts := &Conflicts{Policy: ConflictRename}
+		copyWith(conflicts, source, dest)
+		copyWith(conflicts, source, dest)
+		if read("report.txt") != "b" || read("report (1).txt") != "a" || read("report (2).txt") != "a" {
+			t.Error("источник должен записываться под свободными именами")
+		}
+		if got := freeName(filepath.Join(tempDir, ".profile")); filepath.Base(got) != ".profile (1)" {
+			t.Errorf("неверное имя для скрытого файла: %s", got)
+		}
+	})
+
+	t.Run("Резервные копии", func(t *testing.T) {
+		source := write("v.txt", "v2", older)
+		dest := write("cfg.txt", "v1", older)
+		copyWith(&Conflicts{Policy: ConflictBackup}, source, dest)
+		if read("cfg.txt") != "v2" || read("cfg.txt~") != "v1" {
+			t.Error("простая резервная копия не создана")
+		}
+		numbered := &Conflicts{Policy: ConflictBackup, Backup: BackupNumbered}
+		copyWith(numbered, source, dest)
+		copyWith(numbered, source, dest)
+		if read("cfg.txt.~1~") != "v2" || read("cfg.txt.~2~") != "v2" {
+			t.Error("нумерованные резервные копии не созданы")
+		}
+	})
+
+	t.Run("Вопрос и ответ для всех", func(t *testing.T) {
+		dir := filepath.Join(tempDir, "tree")
+		for _, name := range []string{"a", "b", "c"} {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				t.Fatalf("не удалось создать директорию: %v", err)
+			}
+			write(filepath.Join("tree", name), "new", older)
+		}
+		dest := filepath.Join(tempDir, "tree_copy")
+		copyWith(nil, dir, dest)
+		for _, name := range []string{"a", "b", "c"} {
+			write(filepath.Join("tree_copy", name), "old", older)
+		}
+		asked := 0
+		conflicts := &Conflicts{Policy: ConflictAsk, Ask: func(_, _ string) (ConflictAnswer, error) {
+			asked++
+			return ConflictAnswer{Policy: ConflictSkip, All: true}, nil
+		}}
+		copyWith(conflicts, dir, dest)
+		if asked != 1 {
+			t.Errorf("ответ для всех должен запрашиваться один раз, запрошено %d", asked)
+		}
+		if read("tree_copy/b") != "old" {
+			t.Error("файлы должны пропускаться по ответу для всех")
+		}
+	})
+
+	t.Run("Перемещение", func(t *testing.T) {
+		source := write("move.txt", "moved", older)
+		dest := write("target.txt", "kept", older)
+		if err := fileOperator.Move(source, dest, &Conflicts{Policy: ConflictSkip}); err != nil {
+			t.Fatalf("ошибка перемещения: %v", err)
+		}
+		if read("target.txt") != "kept" || read("move.txt") != "moved" {
+			t.Error("при пропуске источник и назначение не должны меняться")
+		}
+		if err := fileOperator.Move(source, dest, &Conflicts{Policy: ConflictBackup}); err != nil {
+			t.Fatalf("ошибка перемещения: %v", err)
+		}
+		if read("target.txt") != "moved" || read("target.txt~") != "kept" {
+			t.Error("перемещение с резервной копией выполнено неверно")
+		}
+	})
+
+	t.Run("Разбор имен", func(t *testing.T) {
+		for _, name := range []string{"overwrite", "skip", "keep-newer", "rename-new", "backup", "ask"} {
+			policy, err := ParseConflictPolicy(name)
+			if err != nil || policy.String() != name {
+				t.Errorf("политика %s разобрана неверно: %v, %v", name, policy, err)
+			}
+		}
+		if _, err := ParseConflictPolicy("merge"); err == nil {
+			t.Error("ожидалась ошибка для неизвестной политики")
+		}
+		if _, err := ParseBackupStyle("never"); err == nil {
+			t.Error("ожидалась ошибка для неизвестного стиля")
+		}
+	})
+}
+
+func TestMoveAcrossDevices(t *testing.T) {
+	// makeTree создает дерево с файлом, символической и жесткой ссылками и директорией без права записи
+	makeTree := func(t *testing.T, root string) time.Time {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Join(root, "ro"), 0755); err != nil {
+			t.Fatalf("не удалось создать директорию: %v", err)
+		}
+		for _, name := range []string{"data.txt", "ro/inner.txt"} {
+			if err := os.WriteFile(filepath.Join(root, name), []byte("content of "+name), 0640); err != nil {
+				t.Fatalf("не удалось создать файл %s: %v", name, err)
+			}
+		}
+		if err := os.Symlink("data.txt", filepath.Join(root, "link")); err != nil {
+			t.Fatalf("не удалось создать ссылку: %v", err)
+		}
+		if err := os.Link(filepath.Join(root, "data.txt"), filepath.Join(root, "hard.txt")); err != nil {
+			t.Fatalf("не удалось создать жесткую ссылку: %v", err)
+		}
+		mtime := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+		if err := os.Chtimes(filepath.Join(root, "data.txt"), mtime, mtime); err != nil {
+			t.Fatalf("не удалось изменить время: %v", err)
+		}
+		if err := os.Chmod(filepath.Join(root, "ro"), 0555); err != nil {
+			t.Fatalf("не удалось изменить права: %v", err)
+		}
+		return mtime
+	}
+	// checkMoved проверяет, что дерево перенесено целиком, а источник удален
+	checkMoved := func(t *testing.T, source, dest string, mtime time.Time) {
+		t.Helper()
+		defer func() { _ = os.Chmod(filepath.Join(dest, "ro"), 0755) }()
+		if _, err := os.Lstat(source); !os.IsNotExist(err) {
+			t.Errorf("источник должен быть удален: %v", err)
+		}
+		info, err := os.Stat(filepath.Join(dest, "data.txt"))
 		if err != nil {
-			t.Fatalf("не удалось установить права доступа: %v", err)
+			t.Fatalf("файл не перенесен: %v", err)
+		}
+		if !info.ModTime().Equal(mtime) || info.Mode().Perm() != 0640 {
+			t.Errorf("атрибуты файла не сохранены: %v %v", info.Mode(), info.ModTime())
+		}
+		if target, err := os.Readlink(filepath.Join(dest, "link")); err != nil || target != "data.txt" {
+			t.Errorf("символическая ссылка не сохранена: %q, %v", target, err)
+		}
+		if hard, err := os.Stat(filepath.Join(dest, "hard.txt")); err != nil || !os.SameFile(info, hard) {
+			t.Errorf("жесткая ссылка не сохранена: %v", err)
+		}
+		if dir, err := os.Stat(filepath.Join(dest, "ro")); err != nil || dir.Mode().Perm() != 0555 {
+			t.Errorf("права директории не сохранены: %v", err)
+		}
+		entries, _ := os.ReadDir(filepath.Dir(dest))
+		for _, entry := range entries {
+			if strings.Contains(entry.Name(), ".fm-move-") {
+				t.Errorf("временная копия не удалена: %s", entry.Name())
+			}
+		}
+	}
+
+	t.Run("Копирование с удалением источника", func(t *testing.T) {
+		tempDir := t.TempDir()
+		source, dest := filepath.Join(tempDir, "src"), filepath.Join(tempDir, "dst")
+		mtime := makeTree(t, source)
+		if err := moveByCopy(source, dest); err != nil {
+			t.Fatalf("ошибка перемещения: %v", err)
 		}
+		checkMoved(t, source, dest, mtime)
+	})
 
-		// Получаем права доступа через менеджер
-		permissions, err := permissionsManager.GetPermissions(testFile)
+	t.Run("Разные файловые системы", func(t *testing.T) {
+		tempDir := t.TempDir()
+		other, err := os.MkdirTemp("/dev/shm", "fileops_test")
 		if err != nil {
-			t.Errorf("не удалось получить права доступа: %v", err)
+			t.Skipf("отдельная файловая система недоступна: %v", err)
+		}
+		defer func() { _ = removeTree(other) }()
+		tempInfo, _ := os.Stat(tempDir)
+		otherInfo, _ := os.Stat(other)
+		if a, ok := sysinfo.Stat(tempInfo); !ok {
+			t.Skip("сведения об устройстве недоступны")
+		} else if b, _ := sysinfo.Stat(otherInfo); a.Dev == b.Dev {
+			t.Skip("директории находятся на одной файловой системе")
 		}
 
-		// Проверяем, что права не пустые и соответствуют формату восьмеричного числа
-		if len(permissions) == 0 {
-			t.Error("права доступа не должны быть пустыми")
+		source, dest := filepath.Join(tempDir, "src"), filepath.Join(other, "dst")
+		mtime := makeTree(t, source)
+		if err := NewFileOperator().MoveFile(source, dest); err != nil {
+			t.Fatalf("ошибка перемещения между файловыми системами: %v", err)
 		}
+		checkMoved(t, source, dest, mtime)
+	})
 
-		// Проверяем, что права можно преобразовать в восьмеричное число
-		_, err = strconv.ParseUint(permissions, 8, 32)
+	t.Run("Откат при ошибке", func(t *testing.T) {
+		tempDir := t.TempDir()
+		source := filepath.Join(tempDir, "keep.txt")
+		if err := os.WriteFile(source, []byte("keep"), 0644); err != nil {
+			t.Fatalf("не удалось создать файл: %v", err)
+		}
+		if err := moveByCopy(source, filepath.Join(tempDir, "missing", "dst.txt")); err == nil {
+			t.Fatal("ожидалась ошибка при отсутствующей директории назначения")
+		}
+		if _, err := os.Stat(source); err != nil {
+			t.Errorf("при ошибке источник должен сохраниться: %v", err)
+		}
+	})
+
+	t.Run("Проверка копии", func(t *testing.T) {
+		tempDir := t.TempDir()
+		a, b := filepath.Join(tempDir, "a"), filepath.Join(tempDir, "b")
+		for path, content := range map[string]string{a: "same size", b: "Same size"} {
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("не удалось создать файл: %v", err)
+			}
+		}
+		if err := verifyCopy(a, b); err == nil {
+			t.Error("проверка должна обнаруживать различие содержимого")
+		}
+		if err := verifyCopy(a, a); err != nil {
+			t.Errorf("одинаковые файлы должны проходить проверку: %v", err)
+		}
+	})
+}
+
+func TestAtomicCopy(t *testing.T) {
+	savedThreshold, savedInterval := resumeThreshold, journalInterval
+	resumeThreshold, journalInterval = 1024, 1024
+	defer func() { resumeThreshold, journalInterval = savedThreshold, savedInterval }()
+
+	tempDir := t.TempDir()
+	source := filepath.Join(tempDir, "big.bin")
+	data := make([]byte, 10*1024+100)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	if err := os.WriteFile(source, data, 0644); err != nil {
+		t.Fatalf("не удалось создать файл: %v", err)
+	}
+	info, err := os.Stat(source)
+	if err != nil {
+		t.Fatalf("не удалось получить сведения о файле: %v", err)
+	}
+	fileOperator := NewFileOperator()
+
+	t.Run("Временный файл не остается", func(t *testing.T) {
+		dest := filepath.Join(tempDir, "copy.bin")
+		if err := fileOperator.Copy(source, dest, CopyOptions{Preserve: PreserveMode, Verify: true}); err != nil {
+			t.Fatalf("ошибка копирования: %v", err)
+		}
+		for _, path := range []string{partialPath(dest), journalPath(dest)} {
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("после копирования не должен оставаться %s", filepath.Base(path))
+			}
+		}
+	})
+
+	// interrupt имитирует прерванное копирование: первые offset байт записаны, журнал сохранен
+	interrupt := func(t *testing.T, dest string, offset int) {
+		t.Helper()
+		if err := os.WriteFile(partialPath(dest), data[:offset], 0644); err != nil {
+			t.Fatalf("не удалось создать временный файл: %v", err)
+		}
+		sum := sha256.Sum256(data[:offset])
+		err := saveJournal(journalPath(dest), resumeJournal{
+			Source: source, Size: info.Size(), ModTime: info.ModTime(),
+			Offset: int64(offset), Checksum: hex.EncodeToString(sum[:]),
+		})
 		if err != nil {
-			t.Errorf("права доступа %s не являются корректным восьмеричным числом: %v", permissions, err)
+			t.Fatalf("не удалось сохранить журнал: %v", err)
+		}
+	}
+
+	t.Run("Продолжение копирования", func(t *testing.T) {
+		dest := filepath.Join(tempDir, "resumed.bin")
+		interrupt(t, dest, 4096)
+		// Данные за смещением журнала не проверены: при продолжении они должны отбрасываться
+		partial, err := os.OpenFile(partialPath(dest), os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			t.Fatalf("не удалось открыть временный файл: %v", err)
+		}
+		_, _ = partial.Write([]byte("garbage"))
+		_ = partial.Close()
+
+		if err := fileOperator.Copy(source, dest, CopyOptions{Preserve: PreserveMode, Resume: true, Verify: true}); err != nil {
+			t.Fatalf("ошибка продолжения копирования: %v", err)
+		}
+		copied, err := os.ReadFile(dest)
+		if err != nil || !bytes.Equal(copied, data) {
+			t.Error("продолженная копия не совпадает с источником")
+		}
+		if _, err := os.Stat(journalPath(dest)); !os.IsNotExist(err) {
+			t.Error("журнал должен удаляться после завершения")
 		}
 	})
 
-	// Тест на форматирование прав доступа
-	t.Run("FormatPermissions", func(t *testing.T) {
-		// Получаем информацию о файле
-		fileInfo, err := os.Stat(testFile)
+	t.Run("Журнал другой версии источника", func(t *testing.T) {
+		dest := filepath.Join(tempDir, "stale.bin")
+		interrupt(t, dest, 2048)
+		// Копия не совпадает с журналом — копирование должно начаться заново
+		if err := os.WriteFile(partialPath(dest), make([]byte, 2048), 0644); err != nil {
+			t.Fatalf("не удалось изменить временный файл: %v", err)
+		}
+		if err := fileOperator.Copy(source, dest, CopyOptions{Preserve: PreserveMode, Resume: true}); err != nil {
+			t.Fatalf("ошибка копирования: %v", err)
+		}
+		if copied, err := os.ReadFile(dest); err != nil || !bytes.Equal(copied, data) {
+			t.Error("при недействительном журнале файл должен копироваться заново")
+		}
+	})
+
+	t.Run("Проверка обнаруживает расхождение", func(t *testing.T) {
+		dest := filepath.Join(tempDir, "verified.bin")
+		interrupt(t, dest, 4096)
+		// Продолжение берет первые 4096 байт из временного файла, поэтому изменение
+		// источника в этой части при тех же размере и времени видно только проверке
+		changed := append([]byte{}, data...)
+		changed[0] ^= 0xff
+		if err := os.WriteFile(source, changed, 0644); err != nil {
+			t.Fatalf("не удалось изменить источник: %v", err)
+		}
+		defer func() { _ = os.WriteFile(source, data, 0644) }()
+		if err := os.Chtimes(source, info.ModTime(), info.ModTime()); err != nil {
+			t.Fatalf("не удалось восстановить время: %v", err)
+		}
+		err := fileOperator.Copy(source, dest, CopyOptions{Preserve: PreserveMode, Resume: true, Verify: true})
+		if err == nil {
+			t.Error("--verify должен обнаружить расхождение копии и источника")
+		}
+	})
+
+	t.Run("Существующее назначение не портится при ошибке", func(t *testing.T) {
+		dest := filepath.Join(tempDir, "keep.bin")
+		if err := os.WriteFile(dest, []byte("original"), 0644); err != nil {
+			t.Fatalf("не удалось создать файл: %v", err)
+		}
+		if err := fileOperator.Copy(filepath.Join(tempDir, "missing.bin"), dest, DefaultCopyOptions); err == nil {
+			t.Fatal("ожидалась ошибка для отсутствующего источника")
+		}
+		if content, _ := os.ReadFile(dest); string(content) != "original" {
+			t.Error("файл назначения не должен меняться при ошибке")
+		}
+	})
+}
+
+func TestParallelCopy(t *testing.T) {
+	tempDir := t.TempDir()
+	source := filepath.Join(tempDir, "assets")
+	// Много мелких файлов в нескольких директориях и несколько крупных
+	for d := 0; d < 5; d++ {
+		dir := filepath.Join(source, "dir"+strconv.Itoa(d), "sub")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("не удалось создать директорию: %v", err)
+		}
+		for f := 0; f < 150; f++ {
+			name := filepath.Join(dir, "file"+strconv.Itoa(f)+".txt")
+			if err := os.WriteFile(name, []byte(strings.Repeat(name, f%7)), 0644); err != nil {
+				t.Fatalf("не удалось создать файл: %v", err)
+			}
+		}
+	}
+	big := make([]byte, smallFileSize+12345)
+	for i := range big {
+		big[i] = byte(i % 253)
+	}
+	for _, name := range []string{"big1.bin", "big2.bin"} {
+		if err := os.WriteFile(filepath.Join(source, name), big, 0600); err != nil {
+			t.Fatalf("не удалось создать файл: %v", err)
+		}
+	}
+	fileOperator := NewFileOperator()
+
+	t.Run("Дерево копируется целиком", func(t *testing.T) {
+		dest := filepath.Join(tempDir, "copy")
+		options := CopyOptions{Preserve: PreserveAll, Workers: 4, BufferSize: 4096, Verify: true}
+		if err := fileOperator.Copy(source, dest, options); err != nil {
+			t.Fatalf("ошибка копирования: %v", err)
+		}
+		if err := verifyCopy(source, dest); err != nil {
+			t.Errorf("копия не совпадает с источником: %v", err)
+		}
+		err := filepath.WalkDir(dest, func(path string, entry os.DirEntry, err error) error {
+			if err == nil && strings.HasSuffix(entry.Name(), ".fm-partial") {
+				t.Errorf("временный файл не удален: %s", path)
+			}
+			return err
+		})
 		if err != nil {
-			t.Fatalf("не удалось получить информацию о файле: %v", err)
+			t.Fatalf("ошибка обхода копии: %v", err)
+		}
+		if info, err := os.Stat(filepath.Join(dest, "big1.bin")); err != nil || info.Mode().Perm() != 0600 {
+			t.Error("права крупного файла должны сохраняться")
 		}
+	})
 
-		// Форматируем права доступа
-		formatted := permissionsManager.FormatPermissions(fileInfo.Mode())
+	t.Run("Жесткие ссылки и директории без права записи", func(t *testing.T) {
+		tree := filepath.Join(tempDir, "linked")
+		locked := filepath.Join(tree, "locked")
+		if err := os.MkdirAll(locked, 0755); err != nil {
+			t.Fatalf("не удалось создать директорию: %v", err)
+		}
+		original := filepath.Join(locked, "a.txt")
+		if err := os.WriteFile(original, []byte("shared"), 0644); err != nil {
+			t.Fatalf("не удалось создать файл: %v", err)
+		}
+		if err := os.Link(original, filepath.Join(tree, "b.txt")); err != nil {
+			t.Skipf("жесткие ссылки не поддерживаются: %v", err)
+		}
+		mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+		if err := os.Chtimes(locked, mtime, mtime); err != nil {
+			t.Fatalf("не удалось установить время: %v", err)
+		}
+		if err := os.Chmod(locked, 0555); err != nil {
+			t.Fatalf("не удалось изменить права: %v", err)
+		}
+		defer func() { _ = os.Chmod(locked, 0755) }()
 
-		// Проверяем, что формат соответствует Unix-подобному (например, "-rw-r--r--")
-		if len(formatted) != 10 || formatted[0] != '-' {
-			t.Errorf("неверный формат прав доступа: %s", formatted)
+		dest := filepath.Join(tempDir, "linked-copy")
+		if err := fileOperator.Copy(tree, dest, CopyOptions{Preserve: PreserveAll, Workers: 3}); err != nil {
+			t.Fatalf("ошибка копирования: %v", err)
+		}
+		defer func() { _ = os.Chmod(filepath.Join(dest, "locked"), 0755) }()
+		a, errA := os.Stat(filepath.Join(dest, "locked", "a.txt"))
+		b, errB := os.Stat(filepath.Join(dest, "b.txt"))
+		if errA != nil || errB != nil || !os.SameFile(a, b) {
+			t.Error("жесткая ссылка должна остаться связанной")
+		}
+		info, err := os.Stat(filepath.Join(dest, "locked"))
+		if err != nil || info.Mode().Perm() != 0555 || !info.ModTime().Equal(mtime) {
+			t.Error("права и время директории должны устанавливаться после копирования файлов")
+		}
+	})
+
+	t.Run("Ошибка останавливает копирование", func(t *testing.T) {
+		dest := filepath.Join(tempDir, "conflict")
+		if err := os.MkdirAll(filepath.Join(dest, "dir2"), 0755); err != nil {
+			t.Fatalf("не удалось создать директорию: %v", err)
+		}
+		// Файл на месте директории — конфликт типов
+		if err := os.WriteFile(filepath.Join(dest, "dir2", "sub"), nil, 0644); err != nil {
+			t.Fatalf("не удалось создать файл: %v", err)
+		}
+		if err := fileOperator.Copy(source, dest, CopyOptions{Preserve: PreserveMode, Workers: 2}); err == nil {
+			t.Error("ожидалась ошибка конфликта типов")
+		}
+	})
+
+	t.Run("Буферное копирование с ограничением", func(t *testing.T) {
+		var out bytes.Buffer
+		n, err := copyBuffered(&out, bytes.NewReader(big), 5000, make([]byte, 128))
+		if err != nil || n != 5000 || !bytes.Equal(out.Bytes(), big[:5000]) {
+			t.Errorf("ожидалось 5000 байт, скопировано %d: %v", n, err)
+		}
+	})
+
+	t.Run("Неудачный сброс не заменяет последующие", func(t *testing.T) {
+		if runtime.GOOS != "linux" {
+			t.Skip("syncfs доступен только на Linux")
+		}
+		var syncs syncGroup
+		ticket := syncs.ticket()
+		if syncs.sync(filepath.Join(tempDir, "missing"), ticket) {
+			t.Fatal("сброс несуществующей директории должен завершиться неудачей")
+		}
+		// Неудачный сброс не должен считаться выполненным для пакета, записанного до него
+		if syncs.synced > ticket {
+			t.Error("неудачный сброс учтен как успешный")
+		}
+		if !syncs.sync(tempDir, ticket) || syncs.synced <= ticket {
+			t.Error("пакет должен сбрасываться повторно после неудачного сброса")
+		}
+		if !syncs.sync(tempDir, ticket) {
+			t.Error("успешный сброс после записи пакета должен засчитываться")
+		}
+	})
+}
+
+func TestRename(t *testing.T) {
+	names := func(t *testing.T, rule RenameRule, inputs ...string) []string {
+		t.Helper()
+		info, err := os.Stat(t.TempDir())
+		if err != nil {
+			t.Fatalf("не удалось получить сведения: %v", err)
+		}
+		result := make([]string, len(inputs))
+		for i, name := range inputs {
+			if result[i], err = rule(name, RenameFile{Index: i, Info: info}); err != nil {
+				t.Fatalf("ошибка правила: %v", err)
+			}
+		}
+		return result
+	}
+
+	t.Run("Правила", func(t *testing.T) {
+		cases := []struct {
+			rule   func() (RenameRule, error)
+			inputs []string
+			want   []string
+		}{
+			{func() (RenameRule, error) { return ParseSubstitution(`s/IMG_(\d+)/photo-$1x/`) },
+				[]string{"IMG_0042.jpg", "notes.txt"}, []string{"photo-0042x.jpg", "notes.txt"}},
+			{func() (RenameRule, error) { return ParseSubstitution("s/a/o/") }, []string{"banana"}, []string{"bonana"}},
+			{func() (RenameRule, error) { return ParseSubstitution("s/a/o/g") }, []string{"banana"}, []string{"bonono"}},
+			{func() (RenameRule, error) { return ParseSubstitution(`s|\.JPEG$|.jpg|i`) }, []string{"a.jpeg"}, []string{"a.jpg"}},
+			{func() (RenameRule, error) { return ParseSubstitution(`s/\//-/`) }, []string{"a/b"}, []string{"a-b"}},
+			{func() (RenameRule, error) { return ParseTemplate("img-{n:3}{ext}", 9) },
+				[]string{"a.png", "b.png"}, []string{"img-009.png", "img-010.png"}},
+			{func() (RenameRule, error) { return ParseCase("upper") }, []string{"read me.txt"}, []string{"READ ME.TXT"}},
+			{func() (RenameRule, error) { return ParseCase("title") }, []string{"my holiday-photo.JPG"}, []string{"My Holiday-Photo.Jpg"}},
+			{func() (RenameRule, error) { return ExtensionRule("md"), nil }, []string{"a.txt", ".bashrc", "b"}, []string{"a.md", ".bashrc.md", "b.md"}},
+			{func() (RenameRule, error) { return ExtensionRule(""), nil }, []string{"a.tar.gz"}, []string{"a.tar"}},
+		}
+		for _, tc := range cases {
+			rule, err := tc.rule()
+			if err != nil {
+				t.Fatalf("ошибка разбора правила: %v", err)
+			}
+			got := names(t, rule, tc.inputs...)
+			if strings.Join(got, "|") != strings.Join(tc.want, "|") {
+				t.Errorf("для %v ожидалось %v, получено %v", tc.inputs, tc.want, got)
+			}
+		}
+
+		for _, expr := range []string{"s/a/b", "s/(/b/", "s/a/b/x", "x/a/b/"} {
+			if _, err := ParseSubstitution(expr); err == nil {
+				t.Errorf("ожидалась ошибка для %q", expr)
+			}
+		}
+		if _, err := ParseTemplate("{name}-{owner}", 1); err == nil {
+			t.Error("ожидалась ошибка для неизвестной подстановки")
+		}
+		if _, err := ParseCase("camel"); err == nil {
+			t.Error("ожидалась ошибка для неизвестного регистра")
+		}
+		if !IsSubstitution("s#a#b#g") || IsSubstitution("summer.jpg") || IsSubstitution("s/a") {
+			t.Error("неверное распознавание выражения замены")
+		}
+	})
+
+	t.Run("Время изменения в шаблоне", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "scan.pdf")
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("не удалось создать файл: %v", err)
+		}
+		mtime := time.Date(2021, 3, 4, 5, 6, 7, 0, time.Local)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatalf("не удалось установить время: %v", err)
+		}
+		rule, err := ParseTemplate("{mtime}_{mtime:150405}_{name}{ext}", 1)
+		if err != nil {
+			t.Fatalf("ошибка разбора шаблона: %v", err)
+		}
+		plan, err := PlanRename([]string{path}, []RenameRule{rule})
+		if err != nil {
+			t.Fatalf("ошибка планирования: %v", err)
+		}
+		if len(plan.Items) != 1 || filepath.Base(plan.Items[0].To) != "2021-03-04_050607_scan.pdf" {
+			t.Errorf("неверное имя по шаблону: %+v", plan.Items)
+		}
+	})
+
+	// setup создает файлы с содержимым, равным имени
+	setup := func(t *testing.T, files ...string) (string, []string) {
+		t.Helper()
+		dir := t.TempDir()
+		paths := make([]string, len(files))
+		for i, name := range files {
+			paths[i] = filepath.Join(dir, name)
+			if err := os.WriteFile(paths[i], []byte(name), 0644); err != nil {
+				t.Fatalf("не удалось создать файл: %v", err)
+			}
+		}
+		return dir, paths
+	}
+	content := func(dir, name string) string {
+		data, _ := os.ReadFile(filepath.Join(dir, name))
+		return string(data)
+	}
+	fileOperator := NewFileOperator()
+
+	t.Run("Конфликты обнаруживаются до выполнения", func(t *testing.T) {
+		_, paths := setup(t, "a1.txt", "a2.txt", "b.txt", "keep.txt")
+		same, _ := ParseSubstitution(`s/\d//`)
+		if _, err := PlanRename(paths[:2], []RenameRule{same}); err == nil {
+			t.Error("ожидалась ошибка: два файла получают одно имя")
+		}
+		toKeep, _ := ParseSubstitution("s/b/keep/")
+		if _, err := PlanRename(paths[2:3], []RenameRule{toKeep}); err == nil {
+			t.Error("ожидалась ошибка: новое имя занято файлом вне пакета")
+		}
+		slash, _ := ParseSubstitution("s|b|sub/b|")
+		if _, err := PlanRename(paths[2:3], []RenameRule{slash}); err == nil {
+			t.Error("ожидалась ошибка: имя с разделителем пути")
+		}
+	})
+
+	t.Run("Циклы и цепочки", func(t *testing.T) {
+		dir, paths := setup(t, "a", "b", "c", "d", "e")
+		// a → b → c → a — цикл, d → e → f — цепочка
+		rule := func(name string, _ RenameFile) (string, error) {
+			return map[string]string{"a": "b", "b": "c", "c": "a", "d": "e", "e": "f"}[name], nil
+		}
+		plan, err := PlanRename(paths, []RenameRule{rule})
+		if err != nil {
+			t.Fatalf("ошибка планирования: %v", err)
+		}
+		if plan.Cycles != 1 {
+			t.Errorf("ожидался 1 цикл, найдено %d", plan.Cycles)
+		}
+		if err := fileOperator.Rename(plan); err != nil {
+			t.Fatalf("ошибка переименования: %v", err)
+		}
+		for name, want := range map[string]string{"a": "c", "b": "a", "c": "b", "e": "d", "f": "e", "d": ""} {
+			if got := content(dir, name); got != want {
+				t.Errorf("%s: ожидалось содержимое %q, получено %q", name, want, got)
+			}
+		}
+		entries, _ := os.ReadDir(dir)
+		if len(entries) != 5 {
+			t.Errorf("временные файлы не должны оставаться: %d записей", len(entries))
+		}
+
+		undo, err := plan.Undo()
+		if err != nil {
+			t.Fatalf("ошибка отмены: %v", err)
+		}
+		if err := fileOperator.Rename(undo); err != nil {
+			t.Fatalf("ошибка отмены: %v", err)
+		}
+		for _, name := range []string{"a", "b", "c", "d", "e"} {
+			if got := content(dir, name); got != name {
+				t.Errorf("после отмены %s содержит %q", name, got)
+			}
+		}
+	})
+
+	t.Run("Ошибка откатывает пакет", func(t *testing.T) {
+		dir, paths := setup(t, "x1", "x2")
+		rule, _ := ParseSubstitution("s/x/y/")
+		plan, err := PlanRename(paths, []RenameRule{rule})
+		if err != nil {
+			t.Fatalf("ошибка планирования: %v", err)
+		}
+		// Второй шаг не выполнится: источник исчез после проверки
+		plan.Steps = append(plan.Steps, RenameItem{From: filepath.Join(dir, "missing"), To: filepath.Join(dir, "z")})
+		if err := fileOperator.Rename(plan); err == nil {
+			t.Fatal("ожидалась ошибка переименования")
+		}
+		if content(dir, "x1") != "x1" || content(dir, "x2") != "x2" {
+			t.Error("выполненные шаги должны откатываться")
+		}
+	})
+
+	t.Run("История", func(t *testing.T) {
+		history := &RenameHistory{HistoryFile: filepath.Join(t.TempDir(), "rename-history.json")}
+		if _, err := history.Last(); err == nil {
+			t.Error("ожидалась ошибка для пустой истории")
+		}
+		if err := history.Push(&RenamePlan{Items: []RenameItem{{From: "/a", To: "/b"}}}); err != nil {
+			t.Fatalf("ошибка сохранения: %v", err)
+		}
+		loaded := &RenameHistory{HistoryFile: history.HistoryFile}
+		if err := loaded.LoadHistory(); err != nil {
+			t.Fatalf("ошибка загрузки: %v", err)
+		}
+		last, err := loaded.Last()
+		if err != nil || len(last.Items) != 1 || last.Items[0].To != "/b" {
+			t.Errorf("история загружена неверно: %+v, %v", last, err)
+		}
+		if err := loaded.Pop(); err != nil || len(loaded.Batches) != 0 {
+			t.Errorf("пакет не удален из истории: %v", err)
+		}
+	})
+}
+
+func TestSync(t *testing.T) {
+	tempDir := t.TempDir()
+	source := filepath.Join(tempDir, "src")
+	dest := filepath.Join(tempDir, "dst")
+	write := func(t *testing.T, path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("не удалось создать директорию: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("не удалось создать файл: %v", err)
+		}
+	}
+	for name, content := range map[string]string{
+		"a.txt": "a", "docs/b.txt": "b", "docs/deep/c.txt": "c", "cache/tmp.bin": "x", "skip.log": "log",
+	} {
+		write(t, filepath.Join(source, name), content)
+	}
+	fileOperator := NewFileOperator()
+	exclude := []*glob.Pattern{glob.MustCompile("*.log"), glob.MustCompile("cache")}
+	// actions возвращает план в виде строк «действие путь»
+	actions := func(plan *SyncPlan) []string {
+		var result []string
+		for _, item := range plan.Items {
+			result = append(result, strconv.Itoa(int(item.Action))+" "+filepath.ToSlash(item.Path))
+		}
+		return result
+	}
+	sync := func(t *testing.T, options SyncOptions) *SyncPlan {
+		t.Helper()
+		plan, err := PlanSync(source, dest, options)
+		if err != nil {
+			t.Fatalf("ошибка планирования: %v", err)
+		}
+		if err := fileOperator.Sync(plan, options); err != nil {
+			t.Fatalf("ошибка синхронизации: %v", err)
+		}
+		return plan
+	}
+
+	t.Run("Первая синхронизация", func(t *testing.T) {
+		plan := sync(t, SyncOptions{Exclude: exclude})
+		summary := plan.Summary()
+		// Корень, docs, docs/deep и три файла
+		if summary.Created != 6 || summary.Updated != 0 || summary.Bytes != 3 {
+			t.Errorf("неверный итог: %+v, план %v", summary, actions(plan))
+		}
+		if _, err := os.Stat(filepath.Join(dest, "cache")); !os.IsNotExist(err) {
+			t.Error("исключенная директория не должна копироваться")
+		}
+		again, err := PlanSync(source, dest, SyncOptions{Exclude: exclude})
+		if err != nil || len(again.Items) != 0 {
+			t.Errorf("после синхронизации изменений быть не должно: %v %v", actions(again), err)
+		}
+	})
+
+	t.Run("Изменения, удаление и замена типа", func(t *testing.T) {
+		write(t, filepath.Join(source, "a.txt"), "aaa")
+		write(t, filepath.Join(dest, "extra.txt"), "old")
+		write(t, filepath.Join(dest, "keep.log"), "excluded")
+		if err := os.RemoveAll(filepath.Join(source, "docs", "deep")); err != nil {
+			t.Fatalf("не удалось удалить: %v", err)
+		}
+		write(t, filepath.Join(source, "docs", "deep"), "now a file")
+
+		plan := sync(t, SyncOptions{Exclude: exclude, Delete: true})
+		got := strings.Join(actions(plan), ", ")
+		for _, want := range []string{"1 a.txt", "2 docs/deep", "0 docs/deep", "2 extra.txt"} {
+			if !strings.Contains(got, want) {
+				t.Errorf("в плане нет %q: %s", want, got)
+			}
+		}
+		if data, _ := os.ReadFile(filepath.Join(dest, "docs", "deep")); string(data) != "now a file" {
+			t.Error("директория должна заменяться файлом")
+		}
+		if _, err := os.Stat(filepath.Join(dest, "extra.txt")); !os.IsNotExist(err) {
+			t.Error("лишний файл должен удаляться с --delete")
+		}
+		if _, err := os.Stat(filepath.Join(dest, "keep.log")); err != nil {
+			t.Error("исключенные файлы назначения не удаляются")
+		}
+	})
+
+	t.Run("Сравнение по содержимому", func(t *testing.T) {
+		// Тот же размер и время изменения, другое содержимое
+		path := filepath.Join(dest, "docs", "b.txt")
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("не удалось получить сведения: %v", err)
+		}
+		write(t, path, "B")
+		if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+			t.Fatalf("не удалось установить время: %v", err)
+		}
+		if plan, _ := PlanSync(source, dest, SyncOptions{Exclude: exclude}); len(plan.Items) != 0 {
+			t.Errorf("по размеру и времени изменение не видно: %v", actions(plan))
+		}
+		plan := sync(t, SyncOptions{Exclude: exclude, Checksum: true})
+		if strings.Join(actions(plan), ",") != "1 docs/b.txt" {
+			t.Errorf("--checksum должен найти изменение: %v", actions(plan))
+		}
+	})
+
+	t.Run("Вложенные источник и назначение", func(t *testing.T) {
+		if _, err := PlanSync(source, filepath.Join(source, "backup"), SyncOptions{}); err == nil {
+			t.Error("ожидалась ошибка: назначение внутри источника")
+		}
+		if _, err := PlanSync(source, filepath.Dir(source), SyncOptions{Delete: true}); err == nil {
+			t.Error("ожидалась ошибка: источник внутри назначения при удалении лишних файлов")
+		}
+		// Без удаления копирование источника в содержащую его директорию допустимо
+		if _, err := PlanSync(source, filepath.Dir(source), SyncOptions{}); err != nil {
+			t.Errorf("источник внутри назначения без --delete должен синхронизироваться: %v", err)
+		}
+	})
+}
+
+func TestCompare(t *testing.T) {
+	tempDir := t.TempDir()
+	left := filepath.Join(tempDir, "left")
+	right := filepath.Join(tempDir, "right")
+	stamp := time.Now().Add(-time.Hour).Truncate(time.Second)
+	write := func(t *testing.T, path, content string, modTime time.Time) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("не удалось создать директорию: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("не удалось создать файл: %v", err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("не удалось изменить время: %v", err)
+		}
+	}
+	for _, dir := range []string{left, right} {
+		write(t, filepath.Join(dir, "same.txt"), "same", stamp)
+		write(t, filepath.Join(dir, "sub", "deep", "same.txt"), "same", stamp)
+	}
+	write(t, filepath.Join(left, "only-left.txt"), "l", stamp)
+	write(t, filepath.Join(left, "gone", "x.txt"), "x", stamp)
+	write(t, filepath.Join(right, "only-right.txt"), "r", stamp)
+	write(t, filepath.Join(left, "size.txt"), "short", stamp)
+	write(t, filepath.Join(right, "size.txt"), "longer", stamp)
+	write(t, filepath.Join(left, "sub", "mtime.txt"), "m", stamp)
+	write(t, filepath.Join(right, "sub", "mtime.txt"), "m", stamp.Add(time.Minute))
+	// Одинаковые размер и время, но разное содержимое
+	write(t, filepath.Join(left, "sub", "deep", "content.txt"), "aaaa", stamp)
+	write(t, filepath.Join(right, "sub", "deep", "content.txt"), "bbbb", stamp)
+	write(t, filepath.Join(left, "kind"), "file", stamp)
+	write(t, filepath.Join(right, "kind", "inner.txt"), "dir", stamp)
+
+	// statuses возвращает различия в виде «путь статус причины»
+	statuses := func(result *CompareResult) map[string]string {
+		got := make(map[string]string, len(result.Entries))
+		for _, entry := range result.Entries {
+			got[filepath.ToSlash(entry.Path)] = string(entry.Status) + " " + strings.Join(entry.Reasons, ",")
+		}
+		return got
+	}
+	compare := func(t *testing.T, options CompareOptions) *CompareResult {
+		t.Helper()
+		result, err := Compare(left, right, options)
+		if err != nil {
+			t.Fatalf("ошибка сравнения: %v", err)
+		}
+		return result
+	}
+
+	t.Run("Рекурсивное сравнение", func(t *testing.T) {
+		result := compare(t, CompareOptions{Workers: 4})
+		want := map[string]string{
+			"only-left.txt":  "left-only ",
+			"gone":           "left-only ",
+			"only-right.txt": "right-only ",
+			"size.txt":       "differs size",
+			"sub/mtime.txt":  "differs mtime",
+			"kind":           "type-mismatch ",
+		}
+		got := statuses(result)
+		if len(got) != len(want) {
+			t.Errorf("ожидалось %d различий, получено %v", len(want), got)
+		}
+		for path, status := range want {
+			if got[path] != status {
+				t.Errorf("%s: ожидалось %q, получено %q", path, status, got[path])
+			}
+		}
+		s := result.Summary
+		if s.LeftOnly != 2 || s.RightOnly != 1 || s.Differs != 2 || s.TypeMismatch != 1 {
+			t.Errorf("неверный итог: %+v", s)
+		}
+		for i := 1; i < len(result.Entries); i++ {
+			if result.Entries[i-1].Path > result.Entries[i].Path {
+				t.Error("различия должны быть отсортированы по пути")
+			}
+		}
+	})
+
+	t.Run("Сравнение содержимого", func(t *testing.T) {
+		got := statuses(compare(t, CompareOptions{Content: true}))
+		if got["sub/deep/content.txt"] != "differs content" {
+			t.Errorf("ожидалось различие содержимого, получено %v", got)
+		}
+		if _, ok := got["sub/mtime.txt"]; ok {
+			t.Error("с Content время изменения не сравнивается")
+		}
+		if _, ok := got["sub/deep/same.txt"]; ok {
+			t.Error("одинаковые файлы не должны попадать в отчет")
+		}
+	})
+
+	t.Run("Поверхностное сравнение", func(t *testing.T) {
+		got := statuses(compare(t, CompareOptions{Shallow: true}))
+		if _, ok := got["sub/mtime.txt"]; ok {
+			t.Error("без рекурсии содержимое общих поддиректорий не сравнивается")
+		}
+		if got["size.txt"] != "differs size" || got["kind"] != "type-mismatch " {
+			t.Errorf("записи верхнего уровня должны сравниваться: %v", got)
+		}
+	})
+
+	t.Run("Одинаковые директории и ошибки", func(t *testing.T) {
+		result, err := Compare(filepath.Join(left, "sub", "deep"), filepath.Join(left, "sub", "deep"), CompareOptions{Content: true})
+		if err != nil || len(result.Entries) != 0 || result.Entries == nil {
+			t.Errorf("директория должна совпадать сама с собой: %+v %v", result, err)
+		}
+		if _, err := Compare(filepath.Join(left, "same.txt"), right, CompareOptions{}); err == nil {
+			t.Error("ожидалась ошибка: сравнивается файл")
+		}
+		if _, err := Compare(filepath.Join(tempDir, "missing"), right, CompareOptions{}); err == nil {
+			t.Error("ожидалась ошибка для несуществующей директории")
+		}
+	})
+}
+
+func TestDupes(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+	root := filepath.Join(tempDir, "photos")
+	stamp := time.Now().Add(-time.Hour).Truncate(time.Second)
+	big := strings.Repeat("0123456789", 2000)
+	write := func(t *testing.T, name, content string, age time.Duration) string {
+		t.Helper()
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("не удалось создать директорию: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("не удалось создать файл: %v", err)
+		}
+		if err := os.Chtimes(path, stamp.Add(-age), stamp.Add(-age)); err != nil {
+			t.Fatalf("не удалось изменить время: %v", err)
+		}
+		return path
+	}
+	setup := func(t *testing.T) {
+		t.Helper()
+		if err := os.RemoveAll(root); err != nil {
+			t.Fatalf("не удалось очистить директорию: %v", err)
+		}
+		write(t, "a/small.txt", "hello", 0)
+		write(t, "b/small-copy.txt", "hello", time.Minute)
+		write(t, "other.txt", "world", 0) // тот же размер, другое содержимое
+		write(t, "big.bin", big, 0)
+		write(t, "deep/dir/big-copy.bin", big, 2*time.Minute)
+		// Совпадают первый и последний блоки, но не середина
+		write(t, "big-fake.bin", big[:10000]+strings.Repeat("x", 10)+big[10010:], 0)
+		write(t, "empty1", "", 0)
+		write(t, "empty2", "", 0)
+		if err := os.Link(filepath.Join(root, "other.txt"), filepath.Join(root, "other-link.txt")); err != nil {
+			t.Fatalf("не удалось создать жесткую ссылку: %v", err)
+		}
+	}
+	fileOperator := NewFileOperator()
+	find := func(t *testing.T, options DupeOptions) []DupeGroup {
+		t.Helper()
+		groups, err := fileOperator.FindDupes(root, options)
+		if err != nil {
+			t.Fatalf("ошибка поиска дубликатов: %v", err)
+		}
+		return groups
+	}
+	names := func(group DupeGroup) string {
+		var result []string
+		for _, file := range group.Files {
+			rel, _ := filepath.Rel(root, file.Path)
+			result = append(result, filepath.ToSlash(rel))
+		}
+		return strings.Join(result, ",")
+	}
+
+	t.Run("Поиск", func(t *testing.T) {
+		setup(t)
+		groups := find(t, DupeOptions{Workers: 3})
+		if len(groups) != 2 {
+			t.Fatalf("ожидалось 2 группы, получено %d: %+v", len(groups), groups)
+		}
+		if got := names(groups[0]); got != "big.bin,deep/dir/big-copy.bin" || groups[0].Wasted() != int64(len(big)) {
+			t.Errorf("первой должна идти группа с наибольшим лишним местом: %s", got)
+		}
+		if got := names(groups[1]); got != "a/small.txt,b/small-copy.txt" {
+			t.Errorf("неверная группа мелких файлов: %s", got)
+		}
+		if groups := find(t, DupeOptions{MinSize: 1024}); len(groups) != 1 {
+			t.Errorf("--min-size должен отсекать мелкие файлы: %d групп", len(groups))
+		}
+	})
+
+	t.Run("Выбор оставляемой копии", func(t *testing.T) {
+		group := DupeGroup{Files: []DupeFile{
+			{Path: "/b/long/name", ModTime: stamp},
+			{Path: "/a", ModTime: stamp.Add(time.Minute)},
+			{Path: "/c/x", ModTime: stamp.Add(-time.Minute)},
+		}}
+		for keep, want := range map[DupeKeep]int{DupeKeepOldest: 2, DupeKeepNewest: 1, DupeKeepShortest: 1} {
+			if got := group.Keeper(keep); got != want {
+				t.Errorf("%s: ожидался индекс %d, получен %d", keep, want, got)
+			}
+		}
+		if _, err := ParseDupeKeep("largest"); err == nil {
+			t.Error("ожидалась ошибка для неизвестного правила")
+		}
+	})
+
+	t.Run("Замена жесткими ссылками", func(t *testing.T) {
+		setup(t)
+		summary, err := fileOperator.Dedupe(find(t, DupeOptions{}), DupeHardlink, DupeKeepNewest)
+		if err != nil {
+			t.Fatalf("ошибка: %v", err)
+		}
+		if summary.Files != 2 || summary.Bytes != int64(len(big))+5 {
+			t.Errorf("неверный итог: %+v", summary)
+		}
+		a, _ := os.Stat(filepath.Join(root, "big.bin"))
+		b, _ := os.Stat(filepath.Join(root, "deep", "dir", "big-copy.bin"))
+		if !os.SameFile(a, b) {
+			t.Error("копия должна стать жесткой ссылкой")
+		}
+		if groups := find(t, DupeOptions{}); len(groups) != 0 {
+			t.Errorf("после замены дубликатов быть не должно: %+v", groups)
+		}
+	})
+
+	t.Run("Замена символическими ссылками", func(t *testing.T) {
+		setup(t)
+		if _, err := fileOperator.Dedupe(find(t, DupeOptions{}), DupeSymlink, DupeKeepShortest); err != nil {
+			t.Fatalf("ошибка: %v", err)
+		}
+		target, err := os.Readlink(filepath.Join(root, "deep", "dir", "big-copy.bin"))
+		if err != nil || target != filepath.Join("..", "..", "big.bin") {
+			t.Errorf("ожидалась относительная ссылка на big.bin: %q %v", target, err)
+		}
+		if data, err := os.ReadFile(filepath.Join(root, "b", "small-copy.txt")); err != nil || string(data) != "hello" {
+			t.Errorf("ссылка должна вести на оставленный файл: %q %v", data, err)
+		}
+	})
+
+	t.Run("В корзину", func(t *testing.T) {
+		setup(t)
+		groups := find(t, DupeOptions{})
+		// Файл, изменившийся после поиска, не трогается
+		write(t, "b/small-copy.txt", "HELLO", 0)
+		summary, err := fileOperator.Dedupe(groups, DupeTrash, DupeKeepOldest)
+		if err != nil || summary.Files != 1 {
+			t.Fatalf("ожидалась одна обработанная копия: %+v %v", summary, err)
+		}
+		if _, err := os.Stat(filepath.Join(root, "big.bin")); !os.IsNotExist(err) {
+			t.Error("более новая копия должна уйти в корзину")
+		}
+		if _, err := os.Stat(filepath.Join(root, "b", "small-copy.txt")); err != nil {
+			t.Error("изменившийся файл должен остаться на месте")
+		}
+	})
+}
+
+func TestHash(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "abc.txt")
+	if err := os.WriteFile(path, []byte("abc"), 0644); err != nil {
+		t.Fatalf("не удалось создать файл: %v", err)
+	}
+
+	t.Run("Алгоритмы", func(t *testing.T) {
+		want := map[string]string{
+			"md5":    "900150983cd24fb0d6963f7d28e17f72",
+			"sha1":   "a9993e364706816aba3e25717850c26c9cd0d89d",
+			"sha256": "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+			"crc32":  "352441c2",
+		}
+		for algorithm, sum := range want {
+			if got, err := FileHash(path, algorithm); err != nil || got != sum {
+				t.Errorf("%s: ожидалось %s, получено %s (%v)", algorithm, sum, got, err)
+			}
+		}
+		if sum, err := FileHash(path, "sha512"); err != nil || len(sum) != 128 {
+			t.Errorf("sha512: неверная сумма %q: %v", sum, err)
+		}
+		if _, err := ParseHashAlgorithm("sha3"); err == nil {
+			t.Error("ожидалась ошибка для неизвестного алгоритма")
+		}
+		if _, err := FileHash(tempDir, "sha256"); err == nil {
+			t.Error("ожидалась ошибка для директории")
+		}
+	})
+
+	t.Run("Параллельное хеширование", func(t *testing.T) {
+		var paths []string
+		for i := 0; i < 20; i++ {
+			p := filepath.Join(tempDir, "f"+strconv.Itoa(i))
+			if err := os.WriteFile(p, []byte(strconv.Itoa(i)), 0644); err != nil {
+				t.Fatalf("не удалось создать файл: %v", err)
+			}
+			paths = append(paths, p)
+		}
+		paths = append(paths, filepath.Join(tempDir, "missing"))
+		results := HashFiles(paths, "sha256", 4)
+		for i, result := range results[:20] {
+			sum := sha256.Sum256([]byte(strconv.Itoa(i)))
+			if result.Path != paths[i] || result.Sum != hex.EncodeToString(sum[:]) || result.Err != nil {
+				t.Errorf("неверный результат %d: %+v", i, result)
+			}
+		}
+		if results[20].Err == nil {
+			t.Error("ожидалась ошибка для несуществующего файла")
+		}
+	})
+
+	t.Run("Формат sha256sum", func(t *testing.T) {
+		line := FormatChecksumLine("abcd1234", "dir/a b.txt")
+		if line != "abcd1234  dir/a b.txt" {
+			t.Errorf("неверная строка: %q", line)
+		}
+		escaped := FormatChecksumLine("abcd1234", "new\nline\\x")
+		if escaped != `\abcd1234  new\nline\\x` {
+			t.Errorf("неверное экранирование: %q", escaped)
+		}
+		input := "# комментарий\n" + line + "\n" + escaped + "\n\nABCD1234 *binary.bin\n"
+		entries, err := ParseChecksums(strings.NewReader(input), "")
+		if err != nil {
+			t.Fatalf("ошибка разбора: %v", err)
+		}
+		if len(entries) != 3 || entries[0].Path != "dir/a b.txt" || entries[1].Path != "new\nline\\x" ||
+			entries[2].Path != "binary.bin" || entries[2].Sum != "abcd1234" || entries[2].Algorithm != "crc32" {
+			t.Errorf("неверные записи: %+v", entries)
+		}
+		for _, bad := range []string{"abcd1234 x", "zz  file", "abc  file"} {
+			if _, err := ParseChecksums(strings.NewReader(bad), ""); err == nil {
+				t.Errorf("ожидалась ошибка для строки %q", bad)
+			}
+		}
+	})
+
+	t.Run("Проверка", func(t *testing.T) {
+		if err := os.WriteFile(filepath.Join(tempDir, "changed.txt"), []byte("new"), 0644); err != nil {
+			t.Fatalf("не удалось создать файл: %v", err)
+		}
+		sum, _ := FileHash(path, "sha256")
+		old := sha256.Sum256([]byte("old"))
+		entries := []ChecksumEntry{
+			{Algorithm: "sha256", Sum: sum, Path: "abc.txt"},
+			{Algorithm: "sha256", Sum: hex.EncodeToString(old[:]), Path: "changed.txt"},
+			{Algorithm: "sha256", Sum: sum, Path: "gone.txt"},
+			{Algorithm: "sha256", Sum: sum, Path: path},
+		}
+		want := []ChecksumStatus{ChecksumOK, ChecksumFailed, ChecksumMissing, ChecksumOK}
+		for i, check := range VerifyChecksums(entries, tempDir, 2) {
+			if check.Status != want[i] || check.Path != entries[i].Path {
+				t.Errorf("%s: ожидалось %s, получено %s", entries[i].Path, want[i], check.Status)
+			}
+		}
+	})
+}
+
+func TestManifest(t *testing.T) {
+	tempDir := t.TempDir()
+	root := filepath.Join(tempDir, "srv")
+	write := func(t *testing.T, name, content string, mode os.FileMode) {
+		t.Helper()
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("не удалось создать директорию: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), mode); err != nil {
+			t.Fatalf("не удалось создать файл: %v", err)
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			t.Fatalf("не удалось изменить права: %v", err)
+		}
+	}
+	write(t, "bin/app", "#!/bin/sh", 0755)
+	write(t, "etc/app.conf", "port=80", 0644)
+	write(t, "etc/name with space=#.txt", "x", 0600)
+	write(t, "var/log.txt", "log", 0644)
+	if err := os.Symlink("app.conf", filepath.Join(root, "etc", "current")); err != nil {
+		t.Fatalf("не удалось создать ссылку: %v", err)
+	}
+
+	manifest, err := CreateManifest(root, ManifestOptions{Workers: 2})
+	if err != nil {
+		t.Fatalf("ошибка создания манифеста: %v", err)
+	}
+	byPath := make(map[string]ManifestEntry)
+	for _, entry := range manifest.Entries {
+		byPath[entry.Path] = entry
+	}
+	sum := sha256.Sum256([]byte("port=80"))
+	if e := byPath["etc/app.conf"]; e.Type != "file" || e.Size != 7 || e.Mode != "0644" || e.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("неверная запись файла: %+v", e)
+	}
+	if e := byPath["etc/current"]; e.Type != "link" || e.Link != "app.conf" || e.SHA256 != "" {
+		t.Errorf("неверная запись ссылки: %+v", e)
+	}
+	if e := byPath["."]; e.Type != "dir" || len(manifest.Entries) != 9 {
+		t.Errorf("ожидалось 9 записей с корнем: %d", len(manifest.Entries))
+	}
+
+	t.Run("Форматы", func(t *testing.T) {
+		for _, format := range []string{ManifestMTree, ManifestJSON} {
+			var buf bytes.Buffer
+			if err := manifest.Write(&buf, format); err != nil {
+				t.Fatalf("%s: ошибка записи: %v", format, err)
+			}
+			if format == ManifestMTree && !strings.Contains(buf.String(), `./etc/name\040with\040space\075\043.txt type=file mode=0600`) {
+				t.Errorf("имена со специальными символами должны кодироваться:\n%s", buf.String())
+			}
+			read, err := ReadManifest(&buf)
+			if err != nil {
+				t.Fatalf("%s: ошибка чтения: %v", format, err)
+			}
+			changes, err := VerifyManifest(root, read, ManifestOptions{})
+			if err != nil || len(changes) != 0 {
+				t.Errorf("%s: после чтения расхождений быть не должно: %+v %v", format, changes, err)
+			}
+		}
+		if err := manifest.Write(&bytes.Buffer{}, "xml"); err == nil {
+			t.Error("ожидалась ошибка для неизвестного формата")
+		}
+		if _, err := ReadManifest(strings.NewReader("./a type=file mode=0644 size=x\n")); err == nil {
+			t.Error("ожидалась ошибка для неверной строки")
+		}
+	})
+
+	t.Run("Расхождения", func(t *testing.T) {
+		write(t, "etc/app.conf", "port=8080", 0644)
+		if err := os.Chmod(filepath.Join(root, "bin", "app"), 0755|os.ModeSetuid); err != nil {
+			t.Fatalf("не удалось изменить права: %v", err)
+		}
+		if err := os.Remove(filepath.Join(root, "var", "log.txt")); err != nil {
+			t.Fatalf("не удалось удалить файл: %v", err)
+		}
+		write(t, "var/new.txt", "new", 0644)
+		if err := os.Remove(filepath.Join(root, "etc", "current")); err != nil {
+			t.Fatalf("не удалось удалить ссылку: %v", err)
+		}
+		if err := os.Symlink("other.conf", filepath.Join(root, "etc", "current")); err != nil {
+			t.Fatalf("не удалось создать ссылку: %v", err)
+		}
+
+		changes, err := VerifyManifest(root, manifest, ManifestOptions{})
+		if err != nil {
+			t.Fatalf("ошибка проверки: %v", err)
+		}
+		got := make(map[string]string)
+		for _, change := range changes {
+			got[change.Path+" "+string(change.Kind)] = strings.Join(change.Reasons, ",")
+		}
+		want := map[string]string{
+			"etc/app.conf modified": "size,content",
+			"bin/app permissions":   "mode",
+			"var/log.txt removed":   "",
+			"var/new.txt added":     "",
+		}
+		for key, reasons := range want {
+			if r, ok := got[key]; !ok || !strings.HasPrefix(r, reasons) {
+				t.Errorf("нет расхождения %q (%q): %v", key, reasons, got)
+			}
+		}
+		if r := got["etc/current modified"]; !strings.Contains(r, "target") {
+			t.Errorf("смена цели ссылки должна обнаруживаться: %v", got)
+		}
+		if _, ok := got["var modified"]; ok {
+			t.Error("время изменения директорий не сравнивается")
+		}
+	})
+}
+
+func TestPermissionsManager(t *testing.T) {
+	// Создаем временную директорию для тестов
+	tempDir, err := os.MkdirTemp("", "permissions_test")
+	if err != nil {
+		t.Fatalf("не удалось создать временную директорию: %v", err)
+	}
+	defer func() {
+		err := os.RemoveAll(tempDir)
+		if err != nil {
+			t.Errorf("ошибка при удалении временной директории: %v", err)
+		}
+	}()
+
+	// Создаем тестовый файл
+	testFile := filepath.Join(tempDir, "test_permissions.txt")
+	err = os.WriteFile(testFile, []byte("test content"), 0644)
+	if err != nil {
+		t.Fatalf("не удалось создать тестовый файл: %v", err)
+	}
+
+	// Инициализируем PermissionsManager
+	permissionsManager := NewPermissionsManager()
+
+	// Тест на изменение прав доступа
+	t.Run("ChangePermissions", func(t *testing.T) {
+		// Изменяем права на "только чтение"
+		err := permissionsManager.ChangePermissions(testFile, "444")
+		if err != nil {
+			t.Errorf("не удалось изменить права доступа: %v", err)
+		}
+
+		// Проверяем, что права изменились
+		fileInfo, err := os.Stat(testFile)
+		if err != nil {
+			t.Fatalf("не удалось получить информацию о файле: %v", err)
+		}
+
+		// Сравниваем права доступа (в восьмеричной системе)
+		expectedMode := os.FileMode(0444)
+		if fileInfo.Mode().Perm() != expectedMode {
+			t.Errorf("неверные права доступа: получено %o, ожидалось %o", fileInfo.Mode().Perm(), expectedMode)
+		}
+	})
+
+	// Тест на получение прав доступа
+	t.Run("GetPermissions", func(t *testing.T) {
+		// Сначала устанавливаем известные права доступа
+		err := os.Chmod(testFile, 0644)
+		if err != nil {
+			t.Fatalf("не удалось установить права доступа: %v", err)
+		}
+
+		// Получаем права доступа через менеджер
+		permissions, err := permissionsManager.GetPermissions(testFile)
+		if err != nil {
+			t.Errorf("не удалось получить права доступа: %v", err)
+		}
+
+		// Проверяем, что права не пустые и соответствуют формату восьмеричного числа
+		if len(permissions) == 0 {
+			t.Error("права доступа не должны быть пустыми")
+		}
+
+		// Проверяем, что права можно преобразовать в восьмеричное число
+		_, err = strconv.ParseUint(permissions, 8, 32)
+		if err != nil {
+			t.Errorf("права доступа %s не являются корректным восьмеричным числом: %v", permissions, err)
+		}
+	})
+
+	// Тест на форматирование прав доступа
+	t.Run("FormatPermissions", func(t *testing.T) {
+		// Получаем информацию о файле
+		fileInfo, err := os.Stat(testFile)
+		if err != nil {
+			t.Fatalf("не удалось получить информацию о файле: %v", err)
+		}
+
+		// Форматируем права доступа
+		formatted := permissionsManager.FormatPermissions(fileInfo.Mode())
+
+		// Проверяем, что формат соответствует Unix-подобному (например, "-rw-r--r--")
+		if len(formatted) != 10 || formatted[0] != '-' {
+			t.Errorf("неверный формат прав доступа: %s", formatted)
+		}
+	})
+
+	t.Run("SymbolicModes", func(t *testing.T) {
+		tests := []struct {
+			spec string
+			from os.FileMode
+			dir  bool
+			want os.FileMode
+		}{
+			{"u+x,g-w,o=r", 0664, false, 0744},
+			{"a+X", 0644, false, 0644},
+			{"a+X", 0744, false, 0755},
+			{"a+X", 0700, true, 0711},
+			{"go=", 0755, false, 0700},
+			{"+x", 0600, false, 0711},
+			{"-w", 0666, false, 0444},
+			{"u=rw-w", 0700, false, 0400},
+			{"g=u", 0640, false, 0660},
+			{"u+s,g+s", 0755, false, 0755 | os.ModeSetuid | os.ModeSetgid},
+			{"+t", 0777, true, 0777 | os.ModeSticky},
+			{"o-x", 0755 | os.ModeSetuid, false, 0754 | os.ModeSetuid},
+			{"u-s", 0755 | os.ModeSetuid | os.ModeSticky, false, 0755 | os.ModeSticky},
+			{"4755", 0600, false, 0755 | os.ModeSetuid},
+			{"755", 0600 | os.ModeSetgid, false, 0755},
+		}
+		for _, tt := range tests {
+			spec, err := ParseMode(tt.spec)
+			if err != nil {
+				t.Errorf("%s: ошибка разбора: %v", tt.spec, err)
+				continue
+			}
+			from := tt.from
+			if tt.dir {
+				from |= os.ModeDir
+			}
+			if got := spec.Apply(from); got != tt.want {
+				t.Errorf("%s из %v: получено %v, ожидалось %v", tt.spec, tt.from, got, tt.want)
+			}
+		}
+		for _, bad := range []string{"", "u", "u+y", "z+x", "88888", "u+x,"} {
+			if _, err := ParseMode(bad); err == nil {
+				t.Errorf("ожидалась ошибка для режима %q", bad)
+			}
+		}
+	})
+
+	t.Run("SpecialBits", func(t *testing.T) {
+		if err := permissionsManager.ChangePermissions(testFile, "0644"); err != nil {
+			t.Fatalf("не удалось изменить права: %v", err)
+		}
+		if err := permissionsManager.ChangePermissions(testFile, "u+xs,g+s,+t"); err != nil {
+			t.Fatalf("не удалось изменить права: %v", err)
+		}
+		if permissions, _ := permissionsManager.GetPermissions(testFile); permissions != "7744" {
+			t.Errorf("специальные биты должны устанавливаться: %s", permissions)
+		}
+		// Изменение прав доступа не сбрасывает специальные биты
+		if err := permissionsManager.ChangePermissions(testFile, "g+w"); err != nil {
+			t.Fatalf("не удалось изменить права: %v", err)
+		}
+		info, _ := os.Stat(testFile)
+		if got := permissionsManager.FormatPermissions(info.Mode()); got != "-rwsrwSr-T" {
+			t.Errorf("неверный формат специальных битов: %s", got)
+		}
+		if got := permissionsManager.FormatPermissions(0755 | os.ModeDir | os.ModeSticky); got != "drwxr-xr-t" {
+			t.Errorf("неверный формат sticky: %s", got)
+		}
+	})
+
+	t.Run("Recursive", func(t *testing.T) {
+		root := filepath.Join(tempDir, "tree")
+		for _, name := range []string{"a.txt", "sub/b.sh", "sub/deep/c.txt"} {
+			path := filepath.Join(root, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+				t.Fatalf("не удалось создать директорию: %v", err)
+			}
+			if err := os.WriteFile(path, []byte(name), 0600); err != nil {
+				t.Fatalf("не удалось создать файл: %v", err)
+			}
+		}
+		if err := os.Symlink("a.txt", filepath.Join(root, "link")); err != nil {
+			t.Fatalf("не удалось создать ссылку: %v", err)
+		}
+		fileMode, _ := ParseMode("644")
+		dirMode, _ := ParseMode("u=rwx,go=rx")
+		err := permissionsManager.ChangePermissionsTree(root, ChmodOptions{FileMode: fileMode, DirMode: dirMode, Recursive: true})
+		if err != nil {
+			t.Fatalf("ошибка рекурсивного изменения: %v", err)
+		}
+		for name, want := range map[string]os.FileMode{".": 0755, "sub": 0755, "sub/deep": 0755, "a.txt": 0644, "sub/deep/c.txt": 0644} {
+			if info, _ := os.Stat(filepath.Join(root, name)); info.Mode().Perm() != want {
+				t.Errorf("%s: получено %v, ожидалось %v", name, info.Mode().Perm(), want)
+			}
+		}
+
+		mode, _ := ParseMode("o-rwx")
+		if err := permissionsManager.ChangePermissionsTree(root, ChmodOptions{Mode: mode}); err != nil {
+			t.Fatalf("ошибка: %v", err)
+		}
+		if info, _ := os.Stat(filepath.Join(root, "sub")); info.Mode().Perm() != 0755 {
+			t.Error("без -R содержимое не меняется")
+		}
+		if info, _ := os.Stat(root); info.Mode().Perm() != 0750 {
+			t.Errorf("права корня должны измениться: %v", info.Mode().Perm())
+		}
+	})
+
+	t.Run("Owner", func(t *testing.T) {
+		uid, gid, err := ParseOwner("0:0")
+		if err != nil || uid != 0 || gid != 0 {
+			t.Errorf("неверный разбор 0:0: %d %d %v", uid, gid, err)
+		}
+		if uid, gid, err := ParseOwner(":0"); err != nil || uid != -1 || gid != 0 {
+			t.Errorf("«:группа» должна менять только группу: %d %d %v", uid, gid, err)
+		}
+		if uid, gid, err := ParseOwner("0"); err != nil || uid != 0 || gid != -1 {
+			t.Errorf("без группы меняется только пользователь: %d %d %v", uid, gid, err)
+		}
+		for _, bad := range []string{":", "no-such-user-fm", "0:no-such-group-fm"} {
+			if _, _, err := ParseOwner(bad); err == nil {
+				t.Errorf("ожидалась ошибка для %q", bad)
+			}
+		}
+		if os.Geteuid() != 0 {
+			t.Skip("смена владельца требует прав root")
+		}
+		if uid, gid, err := ParseOwner("root:"); err != nil || uid != 0 || gid != 0 {
+			t.Errorf("«пользователь:» назначает основную группу: %d %d %v", uid, gid, err)
+		}
+		root := filepath.Join(tempDir, "owned")
+		if err := os.MkdirAll(filepath.Join(root, "sub"), 0755); err != nil {
+			t.Fatalf("не удалось создать директорию: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(root, "sub", "f"), nil, 0644); err != nil {
+			t.Fatalf("не удалось создать файл: %v", err)
+		}
+		if err := permissionsManager.ChangeOwnerTree(root, 1234, 5678, true); err != nil {
+			t.Fatalf("ошибка смены владельца: %v", err)
+		}
+		info, _ := os.Stat(filepath.Join(root, "sub", "f"))
+		if st, ok := sysinfo.Stat(info); !ok || st.UID != 1234 || st.GID != 5678 {
+			t.Errorf("владелец вложенного файла должен измениться: %+v", st)
+		}
+	})
+}
+
+func TestTrash(t *testing.T) {
+	t.Run("TrashInfo", func(t *testing.T) {
+		// Пример из спецификации: путь в корзине тома задан относительно его корня
+		data := []byte("[Trash Info]\nPath=foo/bar/meow.bow-wow\nDeletionDate=20040831T22:32:08\n")
+		info, err := parseTrashInfo(data, "/media/usb")
+		if err != nil {
+			t.Fatalf("ошибка разбора: %v", err)
+		}
+		if info.Path != "/media/usb/foo/bar/meow.bow-wow" {
+			t.Errorf("неверный путь: %s", info.Path)
+		}
+		if want := time.Date(2004, 8, 31, 22, 32, 8, 0, time.Local); !info.DeletionDate.Equal(want) {
+			t.Errorf("неверная дата: %v", info.DeletionDate)
+		}
+		deleted := time.Date(2024, 3, 1, 9, 5, 7, 0, time.Local)
+		for _, path := range []string{"/home/user/my file.txt", "/tmp/100% кот\n#?&=+.txt", "/a/b(1)~'!*.tar.gz"} {
+			info, err := parseTrashInfo(formatTrashInfo(path, deleted), "")
+			if err != nil || info.Path != path || !info.DeletionDate.Equal(deleted) {
+				t.Errorf("запись не сохранилась для %q: %+v %v", path, info, err)
+			}
+		}
+		if got := string(formatTrashInfo("/home/user/my file.txt", deleted)); got != "[Trash Info]\nPath=/home/user/my%20file.txt\nDeletionDate=2024-03-01T09:05:07\n" {
+			t.Errorf("неверное содержимое .trashinfo:\n%s", got)
+		}
+		// Ключи других групп не учитываются
+		if _, err := parseTrashInfo([]byte("[Other]\nPath=/x\n"), ""); err == nil {
+			t.Error("ожидалась ошибка без группы [Trash Info]")
+		}
+	})
+
+	t.Run("DirectorySizes", func(t *testing.T) {
+		sizes := []directorySize{{Size: 16384, MTime: 15803468, Name: "Documents"}, {Size: 4096, MTime: 1, Name: "my dir %"}}
+		data := formatDirectorySizes(sizes)
+		if !strings.Contains(string(data), "4096 1 my%20dir%20%25\n") {
+			t.Errorf("имя должно быть закодировано:\n%s", data)
+		}
+		parsed := parseDirectorySizes(append(data, "bad line\n"...))
+		if len(parsed) != 2 || parsed[0] != sizes[0] || parsed[1] != sizes[1] {
+			t.Errorf("строки не сохранились: %+v", parsed)
+		}
+	})
+
+	t.Run("VolumeTrashes", func(t *testing.T) {
+		topdir := t.TempDir()
+		uid := strconv.Itoa(os.Getuid())
+		trashes := volumeTrashes(topdir, true)
+		if len(trashes) != 1 || trashes[0].path != filepath.Join(topdir, ".Trash-"+uid) {
+			t.Fatalf("без общей .Trash используется .Trash-$uid: %+v", trashes)
+		}
+		// Общая .Trash без бита sticky не используется
+		if err := os.Mkdir(filepath.Join(topdir, ".Trash"), 0777); err != nil {
+			t.Fatalf("не удалось создать директорию: %v", err)
+		}
+		if trashes := volumeTrashes(topdir, false); len(trashes) != 1 {
+			t.Errorf("директория без sticky не должна использоваться: %+v", trashes)
+		}
+		if err := os.Chmod(filepath.Join(topdir, ".Trash"), 0777|os.ModeSticky); err != nil {
+			t.Fatalf("не удалось изменить права: %v", err)
+		}
+		trashes = volumeTrashes(topdir, true)
+		if len(trashes) != 2 || trashes[0].path != filepath.Join(topdir, ".Trash", uid) || trashes[0].topdir != topdir {
+			t.Errorf("первой должна идти $topdir/.Trash/$uid: %+v", trashes)
+		}
+		other := t.TempDir()
+		if err := os.Symlink(other, filepath.Join(other, ".Trash-"+uid)); err != nil {
+			t.Fatalf("не удалось создать ссылку: %v", err)
+		}
+		if trashes := volumeTrashes(other, true); len(trashes) != 0 {
+			t.Errorf("ссылка вместо корзины не должна использоваться: %+v", trashes)
+		}
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		deleted := time.Date(2024, 5, 10, 12, 0, 0, 0, time.Local)
+		entry := TrashEntry{Name: "a.log", OriginalPath: "/srv/app/logs/a.log", DeletionDate: deleted}
+		cases := []struct {
+			filter TrashFilter
+			match  bool
+		}{
+			{TrashFilter{}, true},
+			{TrashFilter{Prefix: "/srv/app"}, true},
+			{TrashFilter{Prefix: "/srv/app/"}, true},
+			{TrashFilter{Prefix: "/srv/ap"}, false},
+			{TrashFilter{Prefix: "/"}, true},
+			{TrashFilter{Pattern: glob.MustCompile("*.log")}, true},
+			{TrashFilter{Pattern: glob.MustCompile("**/logs/*.log")}, true},
+			{TrashFilter{Pattern: glob.MustCompile("*.txt")}, false},
+			{TrashFilter{After: deleted.Add(-time.Hour), Before: deleted.Add(time.Hour)}, true},
+			{TrashFilter{After: deleted.Add(time.Hour)}, false},
+			{TrashFilter{Before: deleted.Add(-time.Hour)}, false},
+		}
+		for i, c := range cases {
+			if got := c.filter.Match(entry); got != c.match {
+				t.Errorf("случай %d: ожидалось %v, получено %v", i, c.match, got)
+			}
+		}
+		if (TrashFilter{Before: deleted}).Match(TrashEntry{Name: "x"}) {
+			t.Error("запись без времени удаления не подходит под верхнюю границу")
+		}
+	})
+
+	t.Run("MoveRestoreEmpty", func(t *testing.T) {
+		if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+			t.Skip("корзина FreeDesktop используется только в Linux и других Unix")
+		}
+		tempDir := t.TempDir()
+		t.Setenv("XDG_DATA_HOME", filepath.Join(tempDir, "data"))
+		trash := trashDir{path: filepath.Join(tempDir, "data", "Trash")}
+		deleter := &linuxSoftDeleter{}
+		write := func(name string) string {
+			path := filepath.Join(tempDir, "work", name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatalf("не удалось создать директорию: %v", err)
+			}
+			if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+				t.Fatalf("не удалось создать файл: %v", err)
+			}
+			return path
+		}
+
+		report := write("report.txt")
+		if err := deleter.MoveToTrash(report); err != nil {
+			t.Fatalf("ошибка удаления: %v", err)
+		}
+		write("report.txt")
+		if err := deleter.MoveToTrash(report); err != nil {
+			t.Fatalf("ошибка удаления: %v", err)
+		}
+		find := func(name string) TrashEntry {
+			t.Helper()
+			entries, err := deleter.ListTrash()
+			if err != nil {
+				t.Fatalf("ошибка чтения корзины: %v", err)
+			}
+			for _, entry := range entries {
+				if entry.Name == name {
+					return entry
+				}
+			}
+			t.Fatalf("запись %s не найдена в корзине", name)
+			return TrashEntry{}
+		}
+		// Повтор получает номер перед расширением
+		if entry := find("report.2.txt"); entry.OriginalPath != report || entry.Size != int64(len("report.txt")) || entry.DeletionDate.IsZero() {
+			t.Errorf("неверные сведения о записи: %+v", entry)
+		}
+
+		write("my dir/sub/a.txt")
+		dir := filepath.Join(tempDir, "work", "my dir")
+		if err := deleter.MoveToTrash(dir); err != nil {
+			t.Fatalf("ошибка удаления директории: %v", err)
+		}
+		data, err := os.ReadFile(filepath.Join(trash.path, "directorysizes"))
+		if err != nil {
+			t.Fatalf("нет файла directorysizes: %v", err)
+		}
+		sizes := parseDirectorySizes(data)
+		if len(sizes) != 1 || sizes[0].Name != "my dir" || sizes[0].Size <= 0 {
+			t.Errorf("неверная запись directorysizes: %s", data)
+		}
+		if entry := find("my dir"); !entry.IsDir || entry.Size != sizes[0].Size {
+			t.Errorf("размер директории должен браться из directorysizes: %+v", entry)
+		}
+
+		// Родительская директория удалена после записи в корзину и создается заново
+		if err := os.RemoveAll(filepath.Join(tempDir, "work")); err != nil {
+			t.Fatalf("не удалось удалить директорию: %v", err)
+		}
+		restored, err := deleter.RestoreFromTrash(find("my dir"), "", &Conflicts{Policy: ConflictRename})
+		if err != nil || restored != dir {
+			t.Fatalf("ошибка восстановления: %s %v", restored, err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "sub", "a.txt")); err != nil {
+			t.Errorf("директория не восстановлена: %v", err)
+		}
+		if _, err := os.Stat(trash.infoPath("my dir")); !os.IsNotExist(err) {
+			t.Error(".trashinfo должен удаляться после восстановления")
+		}
+		if _, err := os.Stat(filepath.Join(trash.path, "directorysizes")); !os.IsNotExist(err) {
+			t.Error("запись восстановленной директории должна уйти из directorysizes")
+		}
+		if _, err := deleter.RestoreFromTrash(TrashEntry{Name: "../report.txt", Trash: trash.path}, "", nil); err == nil {
+			t.Error("ожидалась ошибка для имени с путем")
+		}
+
+		// Занятое место разрешается по политике конфликтов
+		write("report.txt")
+		if restored, err := deleter.RestoreFromTrash(find("report.txt"), "", &Conflicts{Policy: ConflictSkip}); err != nil || restored != "" {
+			t.Errorf("запись должна быть пропущена: %s %v", restored, err)
+		}
+		restored, err = deleter.RestoreFromTrash(find("report.txt"), "", &Conflicts{Policy: ConflictRename})
+		if err != nil || restored != filepath.Join(tempDir, "work", "report (1).txt") {
+			t.Errorf("запись должна получить свободное имя: %s %v", restored, err)
+		}
+		elsewhere := filepath.Join(tempDir, "elsewhere", "report.txt")
+		if restored, err := deleter.RestoreFromTrash(find("report.2.txt"), elsewhere, nil); err != nil || restored != elsewhere {
+			t.Errorf("запись должна восстановиться в другое место: %s %v", restored, err)
+		}
+
+		// Запись, удаленная другим файловым менеджером с закодированным путем
+		original := filepath.Join(tempDir, "work", "from gnome #1.txt")
+		if err := os.WriteFile(filepath.Join(trash.files(), "from gnome #1.txt"), []byte("gnome"), 0644); err != nil {
+			t.Fatalf("не удалось создать файл: %v", err)
+		}
+		info := "[Trash Info]\nPath=" + escapeTrashPath(original) + "\nDeletionDate=2024-01-02T03:04:05\n"
+		if err := os.WriteFile(trash.infoPath("from gnome #1.txt"), []byte(info), 0600); err != nil {
+			t.Fatalf("не удалось создать .trashinfo: %v", err)
+		}
+		if _, err := deleter.RestoreFromTrash(find("from gnome #1.txt"), "", nil); err != nil {
+			t.Fatalf("ошибка восстановления: %v", err)
+		}
+		if content, err := os.ReadFile(original); err != nil || string(content) != "gnome" {
+			t.Errorf("файл не восстановлен по закодированному пути: %v", err)
+		}
+
+		if err := os.WriteFile(trash.infoPath("orphan"), []byte("[Trash Info]\nPath=/orphan\n"), 0600); err != nil {
+			t.Fatalf("не удалось создать .trashinfo: %v", err)
+		}
+		if err := deleter.EmptyTrash(); err != nil {
+			t.Fatalf("ошибка очистки: %v", err)
+		}
+		for _, sub := range []string{trash.files(), trash.info()} {
+			entries, err := os.ReadDir(sub)
+			if err != nil || len(entries) != 0 {
+				t.Errorf("%s должна остаться пустой: %v %v", sub, entries, err)
+			}
 		}
 	})
 }
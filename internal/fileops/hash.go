@@ -0,0 +1,253 @@
+package fileops
+
+import (
+	"bufio"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"file-manager/internal/i18n"
+)
+
+// DefaultHashAlgorithm — алгоритм контрольных сумм по умолчанию
+const DefaultHashAlgorithm = "sha256"
+
+// hashAlgorithms — поддерживаемые алгоритмы и длина суммы в шестнадцатеричном виде
+var hashAlgorithms = []struct {
+	name      string
+	hexLength int
+	new       func() hash.Hash
+}{
+	{"md5", 32, md5.New},
+	{"sha1", 40, sha1.New},
+	{"sha256", 64, sha256.New},
+	{"sha512", 128, sha512.New},
+	{"crc32", 8, func() hash.Hash { return crc32.NewIEEE() }},
+}
+
+// newHash создает хеш по имени алгоритма
+func newHash(algorithm string) (hash.Hash, error) {
+	for _, a := range hashAlgorithms {
+		if a.name == algorithm {
+			return a.new(), nil
+		}
+	}
+	return nil, fmt.Errorf(i18n.T("hash_bad_algo"), algorithm)
+}
+
+// ParseHashAlgorithm проверяет имя алгоритма контрольной суммы: md5, sha1, sha256, sha512, crc32
+func ParseHashAlgorithm(value string) (string, error) {
+	algorithm := strings.ToLower(value)
+	if _, err := newHash(algorithm); err != nil {
+		return "", err
+	}
+	return algorithm, nil
+}
+
+// algorithmBySum определяет алгоритм по длине суммы
+func algorithmBySum(sum string) string {
+	for _, a := range hashAlgorithms {
+		if a.hexLength == len(sum) {
+			return a.name
+		}
+	}
+	return ""
+}
+
+// FileHash вычисляет контрольную сумму файла, читая его потоком. Содержимое не проверяется
+// на двоичность, поэтому подходит файл любого типа; директории не хешируются.
+func FileHash(path, algorithm string) (string, error) {
+	sum, err := newHash(algorithm)
+	if err != nil {
+		return "", err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = file.Close() }()
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf(i18n.T("hash_is_dir"), path)
+	}
+	if _, err := copyBuffered(sum, file, -1, make([]byte, 256<<10)); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// HashResult — контрольная сумма одного файла
+type HashResult struct {
+	Path string
+	Sum  string
+	Err  error
+}
+
+// HashFiles вычисляет контрольные суммы файлов заданным числом рабочих (0 — DefaultCopyWorkers).
+// Результаты идут в порядке paths; ошибка чтения одного файла не останавливает остальные.
+func HashFiles(paths []string, algorithm string, workers int) []HashResult {
+	results := make([]HashResult, len(paths))
+	parallel(len(paths), workers, func(i int) {
+		sum, err := FileHash(paths[i], algorithm)
+		results[i] = HashResult{Path: paths[i], Sum: sum, Err: err}
+	})
+	return results
+}
+
+// parallel выполняет task для индексов 0..n-1 заданным числом рабочих
+func parallel(n, workers int, task func(i int)) {
+	if workers <= 0 {
+		workers = DefaultCopyWorkers()
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				task(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// FormatChecksumLine форматирует строку в формате sha256sum: «сумма  путь». Имена с переводом
+// строки или обратной косой чертой экранируются, а строка начинается с \, как в GNU coreutils.
+func FormatChecksumLine(sum, path string) string {
+	if strings.ContainsAny(path, "\\\n\r") {
+		escaped := strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`).Replace(path)
+		return `\` + sum + "  " + escaped
+	}
+	return sum + "  " + path
+}
+
+// ChecksumEntry — строка файла контрольных сумм
+type ChecksumEntry struct {
+	Algorithm string
+	Sum       string
+	Path      string
+}
+
+// ParseChecksums читает файл контрольных сумм в формате sha256sum: «сумма  путь» или «сумма *путь»
+// (двоичный режим). Пустые строки и комментарии # пропускаются. Если algorithm пуст, алгоритм
+// каждой строки определяется по длине суммы.
+func ParseChecksums(r io.Reader, algorithm string) ([]ChecksumEntry, error) {
+	var entries []ChecksumEntry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		escaped := strings.HasPrefix(line, `\`)
+		if escaped {
+			line = line[1:]
+		}
+		sum, path, ok := strings.Cut(line, " ")
+		if !ok || len(path) < 2 || path[0] != ' ' && path[0] != '*' {
+			return nil, fmt.Errorf(i18n.T("hash_bad_line"), n)
+		}
+		path = path[1:]
+		if escaped {
+			path = unescapeChecksumPath(path)
+		}
+		entry := ChecksumEntry{Algorithm: algorithm, Sum: strings.ToLower(sum), Path: path}
+		if entry.Algorithm == "" {
+			entry.Algorithm = algorithmBySum(entry.Sum)
+		}
+		if _, err := hex.DecodeString(entry.Sum); err != nil || entry.Algorithm == "" {
+			return nil, fmt.Errorf(i18n.T("hash_bad_line"), n)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// unescapeChecksumPath снимает экранирование имени файла в строке контрольной суммы
+func unescapeChecksumPath(path string) string {
+	var sb strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '\\' && i+1 < len(path) {
+			i++
+			switch path[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			default:
+				sb.WriteByte(path[i])
+			}
+			continue
+		}
+		sb.WriteByte(path[i])
+	}
+	return sb.String()
+}
+
+// ChecksumStatus — результат проверки одного файла
+type ChecksumStatus string
+
+const (
+	// ChecksumOK — сумма совпадает
+	ChecksumOK ChecksumStatus = "OK"
+	// ChecksumFailed — сумма не совпадает или файл не удалось прочитать
+	ChecksumFailed ChecksumStatus = "FAILED"
+	// ChecksumMissing — файла нет
+	ChecksumMissing ChecksumStatus = "MISSING"
+)
+
+// ChecksumCheck — результат проверки строки файла контрольных сумм
+type ChecksumCheck struct {
+	Path   string
+	Status ChecksumStatus
+	// Err — ошибка чтения файла для ChecksumFailed
+	Err error
+}
+
+// VerifyChecksums проверяет файлы по контрольным суммам заданным числом рабочих. Относительные
+// пути отсчитываются от dir. Результаты идут в порядке entries.
+func VerifyChecksums(entries []ChecksumEntry, dir string, workers int) []ChecksumCheck {
+	checks := make([]ChecksumCheck, len(entries))
+	parallel(len(entries), workers, func(i int) {
+		entry := entries[i]
+		path := entry.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		check := ChecksumCheck{Path: entry.Path, Status: ChecksumOK}
+		sum, err := FileHash(path, entry.Algorithm)
+		switch {
+		case os.IsNotExist(err):
+			check.Status = ChecksumMissing
+		case err != nil:
+			check.Status, check.Err = ChecksumFailed, err
+		case sum != entry.Sum:
+			check.Status = ChecksumFailed
+		}
+		checks[i] = check
+	})
+	return checks
+}
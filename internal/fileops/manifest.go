@@ -0,0 +1,412 @@
+package fileops
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"file-manager/internal/i18n"
+	"file-manager/internal/sysinfo"
+)
+
+// Форматы манифеста
+const (
+	// ManifestMTree — текстовый формат в духе mtree: строка на запись, ключи key=value
+	ManifestMTree = "mtree"
+	// ManifestJSON — формат JSON
+	ManifestJSON = "json"
+)
+
+// ManifestEntry — сведения об одной записи дерева
+type ManifestEntry struct {
+	// Path — путь относительно корня через / ("." — сам корень)
+	Path string `json:"path"`
+	// Type — тип записи, как в mtree: file, dir, link, fifo, socket, char, block
+	Type string `json:"type"`
+	Size int64  `json:"size,omitempty"`
+	// Mode — права в восьмеричном виде вместе с setuid, setgid и sticky, например 0644
+	Mode    string    `json:"mode"`
+	ModTime time.Time `json:"mtime"`
+	// UID и GID — владелец; отсутствуют на платформах без владельцев
+	UID *uint32 `json:"uid,omitempty"`
+	GID *uint32 `json:"gid,omitempty"`
+	// SHA256 — контрольная сумма содержимого обычного файла
+	SHA256 string `json:"sha256,omitempty"`
+	// Link — цель символической ссылки
+	Link string `json:"link,omitempty"`
+}
+
+// Manifest — снимок дерева директорий для проверки целостности
+type Manifest struct {
+	Root    string          `json:"root"`
+	Created time.Time       `json:"created"`
+	Entries []ManifestEntry `json:"entries"`
+}
+
+// ManifestOptions задает создание и проверку манифеста
+type ManifestOptions struct {
+	// Workers — число одновременно хешируемых файлов (0 — DefaultCopyWorkers)
+	Workers int
+	// Exclude — абсолютные пути, которые не попадают в манифест, например сам файл манифеста
+	Exclude []string
+}
+
+// CreateManifest обходит дерево dir и записывает для каждой записи путь, тип, размер, права,
+// время изменения, владельца и SHA-256 содержимого. Ссылки не разыменовываются, файлы хешируются
+// параллельно. Записи упорядочены по пути.
+func CreateManifest(dir string, options ManifestOptions) (*Manifest, error) {
+	dir = filepath.Clean(dir)
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("fileops_stat_error"), dir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf(i18n.T("compare_not_dir"), dir)
+	}
+	excluded := make(map[string]bool, len(options.Exclude))
+	for _, path := range options.Exclude {
+		excluded[filepath.Clean(path)] = true
+	}
+
+	manifest := &Manifest{Root: dir, Created: time.Now()}
+	var files []int
+	err = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if excluded[path] {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		item := ManifestEntry{
+			Path:    filepath.ToSlash(rel),
+			Type:    manifestType(info.Mode()),
+			Mode:    formatUnixMode(info.Mode()),
+			ModTime: info.ModTime(),
+		}
+		if stat, ok := sysinfo.Stat(info); ok {
+			uid, gid := stat.UID, stat.GID
+			item.UID, item.GID = &uid, &gid
+		}
+		switch item.Type {
+		case "file":
+			item.Size = info.Size()
+			files = append(files, len(manifest.Entries))
+		case "link":
+			if item.Link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+		manifest.Entries = append(manifest.Entries, item)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("manifest_walk_error"), dir, err)
+	}
+
+	errs := make([]error, len(files))
+	parallel(len(files), options.Workers, func(i int) {
+		entry := &manifest.Entries[files[i]]
+		entry.SHA256, errs[i] = FileHash(filepath.Join(dir, filepath.FromSlash(entry.Path)), "sha256")
+	})
+	for _, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf(i18n.T("fileops_checksum_error"), err)
+		}
+	}
+	sort.Slice(manifest.Entries, func(i, j int) bool { return manifest.Entries[i].Path < manifest.Entries[j].Path })
+	return manifest, nil
+}
+
+// manifestType возвращает тип записи в обозначениях mtree
+func manifestType(mode fs.FileMode) string {
+	switch {
+	case mode.IsDir():
+		return "dir"
+	case mode&fs.ModeSymlink != 0:
+		return "link"
+	case mode&fs.ModeNamedPipe != 0:
+		return "fifo"
+	case mode&fs.ModeSocket != 0:
+		return "socket"
+	case mode&fs.ModeCharDevice != 0:
+		return "char"
+	case mode&fs.ModeDevice != 0:
+		return "block"
+	default:
+		return "file"
+	}
+}
+
+// formatUnixMode записывает права в восьмеричном виде, как chmod
+func formatUnixMode(mode fs.FileMode) string {
+	return fmt.Sprintf("%04o", toUnixMode(mode))
+}
+
+// Write записывает манифест в формате format (ManifestMTree или ManifestJSON)
+func (m *Manifest) Write(w io.Writer, format string) error {
+	switch format {
+	case ManifestJSON:
+		data, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	case ManifestMTree:
+		return m.writeMTree(w)
+	}
+	return fmt.Errorf(i18n.T("manifest_bad_format"), format)
+}
+
+// writeMTree записывает манифест строками «./путь type=... mode=...»; специальные символы
+// в путях кодируются восьмеричными последовательностями \ooo, как в mtree
+func (m *Manifest) writeMTree(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "#mtree v2.0\n# root: %s\n# created: %s\n", m.Root, m.Created.Format(time.RFC3339))
+	for _, e := range m.Entries {
+		path := "."
+		if e.Path != "." {
+			path = "./" + e.Path
+		}
+		fmt.Fprintf(bw, "%s type=%s mode=%s", mtreeEscape(path), e.Type, e.Mode)
+		if e.Type == "file" {
+			fmt.Fprintf(bw, " size=%d", e.Size)
+		}
+		fmt.Fprintf(bw, " time=%d.%09d", e.ModTime.Unix(), e.ModTime.Nanosecond())
+		if e.UID != nil && e.GID != nil {
+			fmt.Fprintf(bw, " uid=%d gid=%d", *e.UID, *e.GID)
+		}
+		if e.SHA256 != "" {
+			fmt.Fprintf(bw, " sha256digest=%s", e.SHA256)
+		}
+		if e.Type == "link" {
+			fmt.Fprintf(bw, " link=%s", mtreeEscape(e.Link))
+		}
+		fmt.Fprintln(bw)
+	}
+	return bw.Flush()
+}
+
+// mtreeEscape кодирует пробелы, управляющие символы, \, # и = восьмеричными последовательностями
+func mtreeEscape(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c >= 0x7f || c == '\\' || c == '#' || c == '=' {
+			fmt.Fprintf(&sb, "\\%03o", c)
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+// mtreeUnescape раскодирует восьмеричные последовательности \ooo
+func mtreeUnescape(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if c, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				sb.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+// ReadManifest читает манифест; формат определяется по содержимому
+func ReadManifest(r io.Reader) (*Manifest, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var manifest Manifest
+		if err := json.Unmarshal(trimmed, &manifest); err != nil {
+			return nil, fmt.Errorf(i18n.T("manifest_parse_error"), err)
+		}
+		return &manifest, nil
+	}
+	return readMTree(data)
+}
+
+// readMTree разбирает манифест в формате, который записывает writeMTree
+func readMTree(data []byte) (*Manifest, error) {
+	manifest := &Manifest{}
+	for n, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.HasPrefix(line, "# root: ") {
+			manifest.Root = strings.TrimPrefix(line, "# root: ")
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		path := mtreeUnescape(fields[0])
+		if path != "." && !strings.HasPrefix(path, "./") {
+			return nil, fmt.Errorf(i18n.T("manifest_bad_line"), n+1)
+		}
+		entry := ManifestEntry{Path: strings.TrimPrefix(path, "./")}
+		for _, field := range fields[1:] {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				return nil, fmt.Errorf(i18n.T("manifest_bad_line"), n+1)
+			}
+			var err error
+			switch key {
+			case "type":
+				entry.Type = value
+			case "mode":
+				entry.Mode = value
+			case "size":
+				entry.Size, err = strconv.ParseInt(value, 10, 64)
+			case "time":
+				entry.ModTime, err = parseMTreeTime(value)
+			case "uid", "gid":
+				var id uint64
+				id, err = strconv.ParseUint(value, 10, 32)
+				owner := uint32(id)
+				if key == "uid" {
+					entry.UID = &owner
+				} else {
+					entry.GID = &owner
+				}
+			case "sha256digest":
+				entry.SHA256 = value
+			case "link":
+				entry.Link = mtreeUnescape(value)
+			}
+			if err != nil {
+				return nil, fmt.Errorf(i18n.T("manifest_bad_line"), n+1)
+			}
+		}
+		if entry.Type == "" || entry.Mode == "" {
+			return nil, fmt.Errorf(i18n.T("manifest_bad_line"), n+1)
+		}
+		manifest.Entries = append(manifest.Entries, entry)
+	}
+	return manifest, nil
+}
+
+// parseMTreeTime разбирает время в виде «секунды.наносекунды»
+func parseMTreeTime(value string) (time.Time, error) {
+	secText, nsecText, _ := strings.Cut(value, ".")
+	sec, err := strconv.ParseInt(secText, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var nsec int64
+	if nsecText != "" {
+		if nsec, err = strconv.ParseInt(nsecText, 10, 64); err != nil {
+			return time.Time{}, err
+		}
+	}
+	return time.Unix(sec, nsec), nil
+}
+
+// ManifestChangeKind — вид расхождения дерева с манифестом
+type ManifestChangeKind string
+
+const (
+	// ManifestAdded — записи нет в манифесте
+	ManifestAdded ManifestChangeKind = "added"
+	// ManifestRemoved — запись манифеста отсутствует
+	ManifestRemoved ManifestChangeKind = "removed"
+	// ManifestModified — изменились тип, размер, содержимое, цель ссылки или время изменения
+	ManifestModified ManifestChangeKind = "modified"
+	// ManifestPermissions — изменились права или владелец
+	ManifestPermissions ManifestChangeKind = "permissions"
+)
+
+// ManifestChange — расхождение одной записи. Запись, у которой изменились и содержимое,
+// и права, дает два расхождения.
+type ManifestChange struct {
+	Path string
+	Kind ManifestChangeKind
+	// Reasons — что изменилось: type, size, content, target, mtime, mode, owner
+	Reasons []string
+	// Old — запись манифеста, New — текущая запись (nil, если записи нет)
+	Old, New *ManifestEntry
+}
+
+// VerifyManifest сравнивает дерево dir с манифестом. Время изменения сравнивается с точностью
+// до секунды и только у файлов и ссылок: время директории меняется при любом изменении содержимого.
+func VerifyManifest(dir string, manifest *Manifest, options ManifestOptions) ([]ManifestChange, error) {
+	current, err := CreateManifest(dir, options)
+	if err != nil {
+		return nil, err
+	}
+	recorded := make(map[string]*ManifestEntry, len(manifest.Entries))
+	for i := range manifest.Entries {
+		recorded[manifest.Entries[i].Path] = &manifest.Entries[i]
+	}
+	var changes []ManifestChange
+	for i := range current.Entries {
+		now := &current.Entries[i]
+		old, ok := recorded[now.Path]
+		if !ok {
+			changes = append(changes, ManifestChange{Path: now.Path, Kind: ManifestAdded, New: now})
+			continue
+		}
+		delete(recorded, now.Path)
+		if reasons := modifiedReasons(old, now); len(reasons) > 0 {
+			changes = append(changes, ManifestChange{Path: now.Path, Kind: ManifestModified, Reasons: reasons, Old: old, New: now})
+		}
+		var reasons []string
+		if old.Mode != now.Mode && old.Type != "link" {
+			reasons = append(reasons, "mode")
+		}
+		if old.UID != nil && now.UID != nil && (*old.UID != *now.UID || old.GID != nil && now.GID != nil && *old.GID != *now.GID) {
+			reasons = append(reasons, "owner")
+		}
+		if len(reasons) > 0 {
+			changes = append(changes, ManifestChange{Path: now.Path, Kind: ManifestPermissions, Reasons: reasons, Old: old, New: now})
+		}
+	}
+	for path, old := range recorded {
+		changes = append(changes, ManifestChange{Path: path, Kind: ManifestRemoved, Old: old})
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// modifiedReasons сравнивает тип, размер, содержимое, цель ссылки и время изменения записей
+func modifiedReasons(old, now *ManifestEntry) []string {
+	if old.Type != now.Type {
+		return []string{"type"}
+	}
+	var reasons []string
+	if old.Size != now.Size {
+		reasons = append(reasons, "size")
+	}
+	if old.SHA256 != now.SHA256 {
+		reasons = append(reasons, "content")
+	}
+	if old.Link != now.Link {
+		reasons = append(reasons, "target")
+	}
+	if old.Type != "dir" && old.ModTime.Unix() != now.ModTime.Unix() {
+		reasons = append(reasons, "mtime")
+	}
+	return reasons
+}
@@ -0,0 +1,10 @@
+//go:build unix && !freebsd
+
+package fileops
+
+import "golang.org/x/sys/unix"
+
+// mknod создает файл устройства; номер устройства приводится к типу, который ожидает платформа
+func mknod(path string, mode uint32, dev uint64) error {
+	return unix.Mknod(path, mode, int(dev))
+}
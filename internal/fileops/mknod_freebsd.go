@@ -0,0 +1,8 @@
+package fileops
+
+import "golang.org/x/sys/unix"
+
+// mknod создает файл устройства
+func mknod(path string, mode uint32, dev uint64) error {
+	return unix.Mknod(path, mode, dev)
+}
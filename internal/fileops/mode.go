@@ -0,0 +1,180 @@
+package fileops
+
+import (
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"file-manager/internal/i18n"
+)
+
+// Биты прав в записи chmod
+const (
+	modeSetuid = 04000
+	modeSetgid = 02000
+	modeSticky = 01000
+)
+
+// ModeSpec — разобранный режим chmod: восьмеричное число или список символических предложений
+type ModeSpec struct {
+	// octal — режим задан числом и заменяет права целиком
+	octal   bool
+	bits    uint32
+	clauses []modeClause
+}
+
+// modeClause — предложение символического режима: для кого (маска битов) и действия по порядку
+type modeClause struct {
+	who     uint32
+	actions []modeAction
+}
+
+// modeAction — одно действие предложения: + - = и права rwxXst или копия прав класса u, g, o
+type modeAction struct {
+	op    byte
+	perms string
+}
+
+// ParseMode разбирает режим chmod: восьмеричное число из 1–4 цифр (644, 4755) или символические
+// предложения через запятую, например u+x,g-w,o=r, a+X, u+s, +t, g=u. Предложение без u, g, o, a
+// действует для всех (маска umask не учитывается).
+func ParseMode(s string) (*ModeSpec, error) {
+	if s != "" && len(s) <= 4 && strings.Trim(s, "01234567") == "" {
+		bits, err := strconv.ParseUint(s, 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf(i18n.T("permissions_invalid_format_error"), err)
+		}
+		return &ModeSpec{octal: true, bits: uint32(bits)}, nil
+	}
+	spec := &ModeSpec{}
+	for _, text := range strings.Split(s, ",") {
+		clause, ok := parseModeClause(text)
+		if !ok {
+			return nil, fmt.Errorf(i18n.T("permissions_invalid_mode"), s)
+		}
+		spec.clauses = append(spec.clauses, clause)
+	}
+	return spec, nil
+}
+
+// parseModeClause разбирает одно символическое предложение
+func parseModeClause(text string) (modeClause, bool) {
+	var clause modeClause
+	i := 0
+	for ; i < len(text) && strings.IndexByte("ugoa", text[i]) >= 0; i++ {
+		switch text[i] {
+		case 'u':
+			clause.who |= modeSetuid | 0700
+		case 'g':
+			clause.who |= modeSetgid | 0070
+		case 'o':
+			clause.who |= modeSticky | 0007
+		case 'a':
+			clause.who |= 07777
+		}
+	}
+	if clause.who == 0 {
+		clause.who = 07777
+	}
+	for i < len(text) {
+		op := text[i]
+		if op != '+' && op != '-' && op != '=' {
+			return clause, false
+		}
+		i++
+		start := i
+		if i < len(text) && strings.IndexByte("ugo", text[i]) >= 0 {
+			i++
+		} else {
+			for i < len(text) && strings.IndexByte("rwxXst", text[i]) >= 0 {
+				i++
+			}
+		}
+		clause.actions = append(clause.actions, modeAction{op: op, perms: text[start:i]})
+	}
+	return clause, len(clause.actions) > 0
+}
+
+// Apply возвращает права, которые получит запись с текущим режимом mode. X дает право исполнения
+// директориям и файлам, у которых оно уже есть хотя бы для одного класса.
+func (m *ModeSpec) Apply(mode fs.FileMode) fs.FileMode {
+	if m.octal {
+		return fromUnixMode(m.bits)
+	}
+	current := toUnixMode(mode)
+	for _, clause := range m.clauses {
+		for _, action := range clause.actions {
+			bits := actionBits(action.perms, current, mode.IsDir()) & clause.who
+			switch action.op {
+			case '+':
+				current |= bits
+			case '-':
+				current &^= bits
+			case '=':
+				current = current&^clause.who | bits
+			}
+		}
+	}
+	return fromUnixMode(current)
+}
+
+// actionBits переводит права действия в биты для всех классов
+func actionBits(perms string, current uint32, isDir bool) uint32 {
+	var bits uint32
+	for i := 0; i < len(perms); i++ {
+		switch perms[i] {
+		case 'r':
+			bits |= 0444
+		case 'w':
+			bits |= 0222
+		case 'x':
+			bits |= 0111
+		case 'X':
+			if isDir || current&0111 != 0 {
+				bits |= 0111
+			}
+		case 's':
+			bits |= modeSetuid | modeSetgid
+		case 't':
+			bits |= modeSticky
+		case 'u':
+			bits |= (current >> 6 & 7) * 0111
+		case 'g':
+			bits |= (current >> 3 & 7) * 0111
+		case 'o':
+			bits |= (current & 7) * 0111
+		}
+	}
+	return bits
+}
+
+// toUnixMode переводит права fs.FileMode в биты chmod
+func toUnixMode(mode fs.FileMode) uint32 {
+	bits := uint32(mode.Perm())
+	if mode&fs.ModeSetuid != 0 {
+		bits |= modeSetuid
+	}
+	if mode&fs.ModeSetgid != 0 {
+		bits |= modeSetgid
+	}
+	if mode&fs.ModeSticky != 0 {
+		bits |= modeSticky
+	}
+	return bits
+}
+
+// fromUnixMode переводит биты chmod в fs.FileMode
+func fromUnixMode(bits uint32) fs.FileMode {
+	mode := fs.FileMode(bits & 0777)
+	if bits&modeSetuid != 0 {
+		mode |= fs.ModeSetuid
+	}
+	if bits&modeSetgid != 0 {
+		mode |= fs.ModeSetgid
+	}
+	if bits&modeSticky != 0 {
+		mode |= fs.ModeSticky
+	}
+	return mode
+}
@@ -0,0 +1,145 @@
+package fileops
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"file-manager/internal/i18n"
+)
+
+// movePath перемещает source в destination. Если они находятся на разных файловых системах
+// (/tmp на tmpfs, другой диск, USB-накопитель) и переименование невозможно, источник копируется
+// с сохранением атрибутов, копия проверяется и только затем источник удаляется.
+func movePath(source, destination string) error {
+	err := os.Rename(source, destination)
+	if err == nil || !isCrossDevice(err) {
+		return err
+	}
+	return moveByCopy(source, destination)
+}
+
+// moveByCopy перемещает source копированием. Копия сначала создается под временным именем рядом
+// с destination и переименовывается только после проверки, поэтому при ошибке копирования
+// или проверки назначение не меняется, а частичная копия удаляется.
+func moveByCopy(source, destination string) error {
+	temp := filepath.Join(filepath.Dir(destination),
+		fmt.Sprintf(".%s.fm-move-%s", filepath.Base(destination), strconv.FormatInt(time.Now().UnixNano(), 36)))
+	c := newCopier(ArchiveCopyOptions)
+	info, err := os.Lstat(source)
+	if err == nil {
+		err = c.copy(source, temp, info)
+	}
+	if err == nil {
+		err = verifyCopy(source, temp)
+	}
+	if err != nil {
+		_ = removeTree(temp)
+		return fmt.Errorf(i18n.T("fileops_move_copy_error"), err)
+	}
+	if err := os.Rename(temp, destination); err != nil {
+		_ = removeTree(temp)
+		return fmt.Errorf(i18n.T("fileops_move_copy_error"), err)
+	}
+	if err := removeTree(source); err != nil {
+		return fmt.Errorf(i18n.T("fileops_move_cleanup_error"), err)
+	}
+	return nil
+}
+
+// verifyCopy сравнивает дерево source с его копией: типы записей, цели ссылок,
+// размеры и содержимое обычных файлов
+func verifyCopy(source, copied string) error {
+	return filepath.WalkDir(source, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(copied, rel)
+		srcInfo, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		dstInfo, err := os.Lstat(target)
+		if err != nil {
+			return err
+		}
+		if srcInfo.Mode().Type() != dstInfo.Mode().Type() {
+			return fmt.Errorf(i18n.T("fileops_verify_error"), target)
+		}
+		switch {
+		case srcInfo.Mode()&fs.ModeSymlink != 0:
+			srcLink, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			if dstLink, err := os.Readlink(target); err != nil || dstLink != srcLink {
+				return fmt.Errorf(i18n.T("fileops_verify_error"), target)
+			}
+		case srcInfo.Mode().IsRegular():
+			if srcInfo.Size() != dstInfo.Size() {
+				return fmt.Errorf(i18n.T("fileops_verify_error"), target)
+			}
+			same, err := sameContent(path, target)
+			if err != nil {
+				return err
+			}
+			if !same {
+				return fmt.Errorf(i18n.T("fileops_verify_error"), target)
+			}
+		}
+		return nil
+	})
+}
+
+// sameContent сравнивает контрольные суммы SHA-256 двух файлов
+func sameContent(a, b string) (bool, error) {
+	sumA, err := fileChecksum(a)
+	if err != nil {
+		return false, err
+	}
+	sumB, err := fileChecksum(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(sumA, sumB), nil
+}
+
+// fileChecksum вычисляет SHA-256 содержимого файла
+func fileChecksum(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
+
+// removeTree удаляет файл или дерево; директориям без права записи оно возвращается,
+// чтобы можно было удалить их содержимое (копия с сохраненными правами может быть такой)
+func removeTree(path string) error {
+	err := os.RemoveAll(path)
+	if err == nil {
+		return nil
+	}
+	_ = filepath.WalkDir(path, func(p string, entry fs.DirEntry, err error) error {
+		if err == nil && entry.IsDir() {
+			_ = os.Chmod(p, 0700)
+		}
+		return nil
+	})
+	return os.RemoveAll(path)
+}
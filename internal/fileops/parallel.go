@@ -0,0 +1,286 @@
+package fileops
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"file-manager/internal/i18n"
+)
+
+const (
+	// smallFileSize — файлы меньше этого размера копируются пакетами
+	smallFileSize = 256 << 10
+	// batchFiles и batchBytes ограничивают пакет мелких файлов
+	batchFiles = 64
+	batchBytes = 4 << 20
+)
+
+// fileJob — файл, содержимое которого копирует рабочий
+type fileJob struct {
+	source, destination string
+	info                fs.FileInfo
+}
+
+// linkJob — жесткая ссылка, которая создается после копирования файлов
+type linkJob struct {
+	first, destination string
+}
+
+// treeCopy — параллельное копирование дерева директорий. Обход идет в одном потоке: он создает
+// директории, разрешает конфликты (в том числе вопросы пользователю), воссоздает ссылки
+// и специальные файлы, а обычные файлы передает ограниченному числу рабочих. Мелкие файлы
+// одной директории объединяются в пакеты и сбрасываются на диск одним вызовом. Жесткие ссылки
+// создаются после копирования всех файлов, атрибуты директорий — последними, от вложенных к внешним.
+type treeCopy struct {
+	*copier
+	jobs chan []fileJob
+	// batch — накапливаемый пакет мелких файлов одной директории
+	batch     []fileJob
+	batchSize int64
+	links     []linkJob
+	// dirs — созданные директории в порядке обхода
+	dirs []fileJob
+
+	// syncs объединяет сброс на диск пакетов, записанных разными рабочими
+	syncs syncGroup
+
+	mu  sync.Mutex
+	err error
+}
+
+// syncGroup объединяет вызовы syncfs: если сброс файловой системы начался после того, как
+// рабочий записал свой пакет, и успешно завершился, повторный вызов не нужен
+type syncGroup struct {
+	mu sync.Mutex
+	// epoch — число начатых сбросов
+	epoch atomic.Uint64
+	// synced — номер последнего успешного сброса
+	synced uint64
+}
+
+// ticket возвращает номер последнего начатого сброса; его читают сразу после записи данных
+func (g *syncGroup) ticket() uint64 {
+	return g.epoch.Load()
+}
+
+// sync сбрасывает на диск файловую систему dir, если после получения ticket
+// не было успешного сброса
+func (g *syncGroup) sync(dir string, ticket uint64) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.synced > ticket {
+		return true
+	}
+	epoch := g.epoch.Add(1)
+	if !syncFilesystem(dir) {
+		return false
+	}
+	g.synced = epoch
+	return true
+}
+
+// copyTree копирует директорию со всем содержимым
+func (c *copier) copyTree(source, destination string, info fs.FileInfo) error {
+	workers := c.workers()
+	t := &treeCopy{copier: c, jobs: make(chan []fileJob, workers)}
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			t.work()
+		}()
+	}
+	t.fail(t.plan(source, destination, info))
+	t.flush()
+	close(t.jobs)
+	wg.Wait()
+	if t.err != nil {
+		return t.err
+	}
+
+	for _, link := range t.links {
+		if err := linkTo(link.first, link.destination); err != nil {
+			return err
+		}
+	}
+	for i := len(t.dirs) - 1; i >= 0; i-- {
+		dir := t.dirs[i]
+		if err := c.copyAttributes(dir.source, dir.destination, dir.info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fail запоминает первую ошибку копирования
+func (t *treeCopy) fail(err error) {
+	if err == nil {
+		return
+	}
+	t.mu.Lock()
+	if t.err == nil {
+		t.err = err
+	}
+	t.mu.Unlock()
+}
+
+// failed возвращает первую ошибку копирования, после которой обход и рабочие останавливаются
+func (t *treeCopy) failed() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+// plan обходит запись source: директории создаются сразу, файлы ставятся в очередь
+func (t *treeCopy) plan(source, destination string, info fs.FileInfo) error {
+	if err := t.failed(); err != nil {
+		return err
+	}
+	destination, err := t.options.Conflicts.resolve(source, destination, info, true)
+	if err != nil || destination == "" {
+		return err
+	}
+	switch {
+	case info.IsDir():
+		return t.planDir(source, destination, info)
+	case !t.copiesContents(info):
+		return t.copySpecial(source, destination, info)
+	}
+	if first := t.linkTarget(destination, info); first != "" {
+		t.links = append(t.links, linkJob{first: first, destination: destination})
+		return nil
+	}
+	t.enqueue(fileJob{source: source, destination: destination, info: info})
+	return nil
+}
+
+// planDir создает директорию, доступную владельцу на запись до конца копирования, и обходит ее
+func (t *treeCopy) planDir(source, destination string, info fs.FileInfo) error {
+	if err := os.MkdirAll(destination, info.Mode().Perm()|0700); err != nil {
+		return fmt.Errorf(i18n.T("fileops_create_dir_error"), destination, err)
+	}
+	t.dirs = append(t.dirs, fileJob{source: source, destination: destination, info: info})
+	entries, err := os.ReadDir(source)
+	if err != nil {
+		return fmt.Errorf(i18n.T("fileops_read_dir_error"), source, err)
+	}
+	for _, entry := range entries {
+		sourcePath := filepath.Join(source, entry.Name())
+		entryInfo, err := t.stat(sourcePath)
+		if err != nil {
+			return fmt.Errorf(i18n.T("fileops_stat_error"), sourcePath, err)
+		}
+		if err := t.plan(sourcePath, filepath.Join(destination, entry.Name()), entryInfo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enqueue передает файл рабочим: крупные файлы по одному, мелкие — пакетами в пределах директории.
+// Файлы с журналом продолжения в пакеты не попадают.
+func (t *treeCopy) enqueue(job fileJob) {
+	size := job.info.Size()
+	if size >= smallFileSize || size >= resumeThreshold {
+		t.jobs <- []fileJob{job}
+		return
+	}
+	if len(t.batch) > 0 && filepath.Dir(t.batch[0].destination) != filepath.Dir(job.destination) {
+		t.flush()
+	}
+	t.batch = append(t.batch, job)
+	t.batchSize += size
+	if len(t.batch) >= batchFiles || t.batchSize >= batchBytes {
+		t.flush()
+	}
+}
+
+// flush передает накопленный пакет рабочим
+func (t *treeCopy) flush() {
+	if len(t.batch) == 0 {
+		return
+	}
+	t.jobs <- t.batch
+	t.batch = nil
+	t.batchSize = 0
+}
+
+// work копирует пакеты из очереди; после первой ошибки оставшиеся пакеты пропускаются
+func (t *treeCopy) work() {
+	buf := t.buffer()
+	for batch := range t.jobs {
+		if t.failed() != nil {
+			continue
+		}
+		t.fail(t.copyBatch(batch, buf, &t.syncs))
+	}
+}
+
+// copyBatch копирует пакет файлов одной директории. Временные копии всех файлов сбрасываются
+// на диск вместе (на Linux — одним вызовом syncfs, общим для рабочих), затем атомарно переименовываются.
+func (c *copier) copyBatch(batch []fileJob, buf []byte, syncs *syncGroup) error {
+	if len(batch) == 1 {
+		job := batch[0]
+		return c.copyFile(job.source, job.destination, job.info, buf)
+	}
+	for i, job := range batch {
+		if err := writePartial(job.source, job.destination, job.info, false, false, buf); err != nil {
+			removePartials(batch[:i])
+			return err
+		}
+	}
+	if err := syncPartials(batch, syncs, syncs.ticket()); err != nil {
+		removePartials(batch)
+		return err
+	}
+	for i, job := range batch {
+		if err := commitPartial(job.destination); err != nil {
+			removePartials(batch[i:])
+			return err
+		}
+	}
+	syncDir(filepath.Dir(batch[0].destination))
+	for _, job := range batch {
+		if c.options.Verify {
+			if err := verifyCopy(job.source, job.destination); err != nil {
+				return err
+			}
+		}
+		if err := c.copyAttributes(job.source, job.destination, job.info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncPartials сбрасывает на диск временные копии пакета; ticket получен после их записи
+func syncPartials(batch []fileJob, syncs *syncGroup, ticket uint64) error {
+	if syncs.sync(filepath.Dir(batch[0].destination), ticket) {
+		return nil
+	}
+	for _, job := range batch {
+		file, err := os.OpenFile(partialPath(job.destination), os.O_WRONLY, 0)
+		if err == nil {
+			err = file.Sync()
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err != nil {
+			return fmt.Errorf(i18n.T("fileops_copy_content_error"), err)
+		}
+	}
+	return nil
+}
+
+// removePartials удаляет временные копии пакета, который не удалось завершить
+func removePartials(batch []fileJob) {
+	for _, job := range batch {
+		_ = os.Remove(partialPath(job.destination))
+	}
+}
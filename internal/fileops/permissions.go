@@ -2,10 +2,13 @@ package fileops
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
-	"strconv"
+	"path/filepath"
+	"strings"
 
 	"file-manager/internal/i18n"
+	"file-manager/internal/sysinfo"
 )
 
 // PermissionsManager предоставляет функции для управления правами доступа к файлам
@@ -16,34 +19,102 @@ func NewPermissionsManager() *PermissionsManager {
 	return &PermissionsManager{}
 }
 
-// ChangePermissions изменяет права доступа к файлу или директории
+// ChangePermissions изменяет права доступа к файлу или директории. Режим задается восьмеричным
+// числом (644, 4755) или символически, как в chmod: u+x,g-w,o=r, a+X, u+s, +t.
 func (p *PermissionsManager) ChangePermissions(path string, permissions string) error {
-	// Преобразование строки с восьмеричным числом в uint32
-	mode, err := strconv.ParseUint(permissions, 8, 32)
+	spec, err := ParseMode(permissions)
 	if err != nil {
-		return fmt.Errorf(i18n.T("permissions_invalid_format_error"), err)
+		return err
 	}
-
-	// Применение новых прав доступа
-	err = os.Chmod(path, os.FileMode(mode))
+	info, err := os.Stat(path)
 	if err != nil {
-		return fmt.Errorf(i18n.T("permissions_chmod_error"), path, err)
+		return fmt.Errorf(i18n.T("permissions_stat_error"), path, err)
 	}
+	return p.chmod(path, spec, info.Mode())
+}
 
+// chmod применяет режим к записи с текущими правами mode
+func (p *PermissionsManager) chmod(path string, spec *ModeSpec, mode fs.FileMode) error {
+	if err := os.Chmod(path, spec.Apply(mode)); err != nil {
+		return fmt.Errorf(i18n.T("permissions_chmod_error"), path, err)
+	}
 	return nil
 }
 
+// ChmodOptions задает изменение прав для дерева
+type ChmodOptions struct {
+	// Mode применяется ко всем записям, если для их типа не задан отдельный режим
+	Mode *ModeSpec
+	// FileMode и DirMode — отдельные режимы для файлов и директорий
+	FileMode, DirMode *ModeSpec
+	// Recursive изменяет права всего содержимого директорий
+	Recursive bool
+}
+
+// ChangePermissionsTree изменяет права записи path, а с options.Recursive — и всего ее содержимого.
+// Права директории меняются до обхода ее содержимого. Символические ссылки внутри дерева
+// пропускаются: права ссылок не используются. Ошибка на одной записи не останавливает обход;
+// возвращается первая ошибка.
+func (p *PermissionsManager) ChangePermissionsTree(path string, options ChmodOptions) error {
+	var first error
+	apply := func(path string, mode fs.FileMode) {
+		spec := options.Mode
+		if mode.IsDir() && options.DirMode != nil {
+			spec = options.DirMode
+		} else if !mode.IsDir() && options.FileMode != nil {
+			spec = options.FileMode
+		}
+		if spec == nil || mode&fs.ModeSymlink != 0 {
+			return
+		}
+		if err := p.chmod(path, spec, mode); err != nil && first == nil {
+			first = err
+		}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf(i18n.T("permissions_stat_error"), path, err)
+	}
+	if !options.Recursive || !info.IsDir() {
+		apply(path, info.Mode())
+		return first
+	}
+	err = filepath.WalkDir(path, func(entryPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if first == nil {
+				first = fmt.Errorf(i18n.T("permissions_stat_error"), entryPath, err)
+			}
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		if entryPath == path {
+			// Корень задан явно: ссылка на директорию уже разыменована os.Stat
+			info, err = os.Stat(path)
+			if err != nil {
+				return err
+			}
+		}
+		apply(entryPath, info.Mode())
+		return nil
+	})
+	if err != nil && first == nil {
+		first = err
+	}
+	return first
+}
+
 // GetPermissions возвращает текущие права доступа к файлу или директории
+// в виде восьмеричного числа вместе с setuid, setgid и sticky, например 0644 или 4755
 func (p *PermissionsManager) GetPermissions(path string) (string, error) {
 	// Получение информации о файле
 	fileInfo, err := os.Stat(path)
 	if err != nil {
 		return "", fmt.Errorf(i18n.T("permissions_stat_error"), path, err)
 	}
-
-	// Преобразование прав доступа в строку восьмеричного числа
-	mode := fileInfo.Mode().Perm()
-	return fmt.Sprintf("%o", mode), nil
+	return formatUnixMode(fileInfo.Mode()), nil
 }
 
 // ChangeOwner изменяет владельца файла или директории (не работает в Windows)
@@ -56,6 +127,66 @@ func (p *PermissionsManager) ChangeOwner(path string, uid, gid int) error {
 	return nil
 }
 
+// ChangeOwnerTree изменяет владельца записи path, а с recursive — и всего ее содержимого.
+// uid или gid, равный -1, не меняется. Символические ссылки внутри дерева меняют владельца
+// сами, без разыменования. Ошибка на одной записи не останавливает обход; возвращается первая ошибка.
+func (p *PermissionsManager) ChangeOwnerTree(path string, uid, gid int, recursive bool) error {
+	if !recursive {
+		return p.ChangeOwner(path, uid, gid)
+	}
+	var first error
+	err := filepath.WalkDir(path, func(entryPath string, entry fs.DirEntry, err error) error {
+		if err == nil {
+			if entryPath == path {
+				err = os.Chown(entryPath, uid, gid)
+			} else {
+				err = os.Lchown(entryPath, uid, gid)
+			}
+		}
+		if err != nil && first == nil {
+			first = fmt.Errorf(i18n.T("permissions_chown_error"), entryPath, err)
+		}
+		return nil
+	})
+	if err != nil && first == nil {
+		first = err
+	}
+	return first
+}
+
+// ParseOwner разбирает владельца в виде пользователь[:группа] по именам или числовым
+// идентификаторам. «пользователь:» назначает основную группу пользователя, «:группа» меняет
+// только группу. Неизменяемая часть возвращается как -1.
+func ParseOwner(spec string) (uid, gid int, err error) {
+	userName, groupName, hasGroup := strings.Cut(spec, ":")
+	if userName == "" && groupName == "" {
+		return -1, -1, fmt.Errorf(i18n.T("permissions_invalid_owner"), spec)
+	}
+	uid, gid = -1, -1
+	if userName != "" {
+		id, err := sysinfo.LookupUser(userName)
+		if err != nil {
+			return -1, -1, err
+		}
+		uid = int(id)
+	}
+	switch {
+	case groupName != "":
+		id, err := sysinfo.LookupGroup(groupName)
+		if err != nil {
+			return -1, -1, err
+		}
+		gid = int(id)
+	case hasGroup && userName != "":
+		id, err := sysinfo.LookupPrimaryGroup(userName)
+		if err != nil {
+			return -1, -1, err
+		}
+		gid = int(id)
+	}
+	return uid, gid, nil
+}
+
 // FormatPermissions форматирует права доступа в удобочитаемом виде (как в ls -l)
 func (p *PermissionsManager) FormatPermissions(mode os.FileMode) string {
 	result := ""
@@ -69,28 +200,29 @@ func (p *PermissionsManager) FormatPermissions(mode os.FileMode) string {
 		result += "p"
 	} else if mode&os.ModeSocket != 0 {
 		result += "s"
-	} else if mode&os.ModeDevice != 0 {
-		result += "b"
 	} else if mode&os.ModeCharDevice != 0 {
 		result += "c"
+	} else if mode&os.ModeDevice != 0 {
+		result += "b"
 	} else {
 		result += "-"
 	}
 
 	// Права для владельца
-	result += p.formatPermissionBits(mode, 6)
+	result += p.formatPermissionBits(mode, 6, mode&os.ModeSetuid != 0, 's')
 
 	// Права для группы
-	result += p.formatPermissionBits(mode, 3)
+	result += p.formatPermissionBits(mode, 3, mode&os.ModeSetgid != 0, 's')
 
 	// Права для остальных
-	result += p.formatPermissionBits(mode, 0)
+	result += p.formatPermissionBits(mode, 0, mode&os.ModeSticky != 0, 't')
 
 	return result
 }
 
-// formatPermissionBits форматирует биты прав доступа
-func (p *PermissionsManager) formatPermissionBits(mode os.FileMode, shift uint) string {
+// formatPermissionBits форматирует биты прав доступа. Установленный специальный бит (setuid, setgid,
+// sticky) показывается на месте исполнения символом special, а без права исполнения — заглавным, как в ls -l.
+func (p *PermissionsManager) formatPermissionBits(mode os.FileMode, shift uint, isSpecial bool, special byte) string {
 	var result string
 
 	// Чтение
@@ -108,9 +240,15 @@ func (p *PermissionsManager) formatPermissionBits(mode os.FileMode, shift uint)
 	}
 
 	// Исполнение
-	if mode&(1<<shift) != 0 {
+	executable := mode&(1<<shift) != 0
+	switch {
+	case isSpecial && executable:
+		result += string(special)
+	case isSpecial:
+		result += strings.ToUpper(string(special))
+	case executable:
 		result += "x"
-	} else {
+	default:
 		result += "-"
 	}
 
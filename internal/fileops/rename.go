@@ -0,0 +1,474 @@
+package fileops
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"file-manager/internal/i18n"
+)
+
+// RenameFile — сведения о переименовываемом файле, доступные правилам
+type RenameFile struct {
+	Path string
+	// Index — порядковый номер файла в пакете, начиная с 0
+	Index int
+	Info  fs.FileInfo
+}
+
+// RenameRule преобразует имя файла (без директории). Правила применяются по очереди:
+// каждое получает имя, полученное предыдущим.
+type RenameRule func(name string, file RenameFile) (string, error)
+
+// substitutionPattern — выражение вида s/шаблон/замена/флаги с любым разделителем
+var substitutionPattern = regexp.MustCompile(`^s([^\w\s\\])`)
+
+// IsSubstitution проверяет, похоже ли выражение на замену вида s/шаблон/замена/флаги
+func IsSubstitution(expr string) bool {
+	m := substitutionPattern.FindStringSubmatch(expr)
+	if m == nil {
+		return false
+	}
+	parts, _ := splitSubstitution(expr[2:], m[1])
+	return len(parts) == 3
+}
+
+// splitSubstitution делит тело выражения по разделителю с учетом экранирования \<разделитель>
+func splitSubstitution(body, delim string) ([]string, error) {
+	var parts []string
+	var current strings.Builder
+	for i := 0; i < len(body); i++ {
+		switch {
+		case body[i] == '\\' && strings.HasPrefix(body[i+1:], delim):
+			current.WriteString(delim)
+			i += len(delim)
+		case strings.HasPrefix(body[i:], delim):
+			parts = append(parts, current.String())
+			current.Reset()
+			i += len(delim) - 1
+		default:
+			current.WriteByte(body[i])
+		}
+	}
+	parts = append(parts, current.String())
+	if len(parts) != 3 {
+		return nil, fmt.Errorf(i18n.T("rename_bad_substitution"), "s"+delim+body)
+	}
+	return parts, nil
+}
+
+// perlGroup — ссылки на группы $1 и \1 в замене
+var perlGroup = regexp.MustCompile(`\$(\d+)|\\(\d+)`)
+
+// ParseSubstitution разбирает замену s/шаблон/замена/флаги. Флаг g заменяет все совпадения
+// (без него — только первое), i отключает учет регистра. В замене $1 и \1 — группы шаблона.
+func ParseSubstitution(expr string) (RenameRule, error) {
+	m := substitutionPattern.FindStringSubmatch(expr)
+	if m == nil {
+		return nil, fmt.Errorf(i18n.T("rename_bad_substitution"), expr)
+	}
+	parts, err := splitSubstitution(expr[2:], m[1])
+	if err != nil {
+		return nil, err
+	}
+	pattern, replacement, flags := parts[0], parts[1], parts[2]
+	global := false
+	for _, flag := range flags {
+		switch flag {
+		case 'g':
+			global = true
+		case 'i':
+			pattern = "(?i)" + pattern
+		default:
+			return nil, fmt.Errorf(i18n.T("rename_bad_flag"), string(flag), expr)
+		}
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("rename_bad_regex"), pattern, err)
+	}
+	// $1abc в Go означает группу «1abc», поэтому номера групп заключаются в скобки
+	replacement = perlGroup.ReplaceAllString(replacement, "$${$1$2}")
+
+	return func(name string, _ RenameFile) (string, error) {
+		if global {
+			return re.ReplaceAllString(name, replacement), nil
+		}
+		match := re.FindStringSubmatchIndex(name)
+		if match == nil {
+			return name, nil
+		}
+		result := re.ExpandString(nil, replacement, name, match)
+		return name[:match[0]] + string(result) + name[match[1]:], nil
+	}, nil
+}
+
+// templateField — подстановка {поле} или {поле:параметр} в шаблоне имени
+var templateField = regexp.MustCompile(`\{(\w+)(?::([^}]*))?\}`)
+
+// ParseTemplate разбирает шаблон имени. Подстановки: {name} — имя без расширения, {ext} —
+// расширение с точкой, {n} или {n:3} — номер файла начиная со start (с дополнением нулями
+// до ширины), {mtime} или {mtime:2006-01-02} — время изменения в формате Go, {size} — размер в байтах.
+func ParseTemplate(template string, start int) (RenameRule, error) {
+	for _, m := range templateField.FindAllStringSubmatch(template, -1) {
+		switch m[1] {
+		case "name", "ext", "size", "mtime":
+		case "n":
+			if m[2] != "" {
+				if width, err := strconv.Atoi(m[2]); err != nil || width < 1 || width > 20 {
+					return nil, fmt.Errorf(i18n.T("rename_bad_width"), m[0])
+				}
+			}
+		default:
+			return nil, fmt.Errorf(i18n.T("rename_unknown_field"), m[0])
+		}
+	}
+
+	return func(name string, file RenameFile) (string, error) {
+		ext := filepath.Ext(name)
+		if ext == name {
+			ext = ""
+		}
+		stem := strings.TrimSuffix(name, ext)
+		return templateField.ReplaceAllStringFunc(template, func(field string) string {
+			m := templateField.FindStringSubmatch(field)
+			switch m[1] {
+			case "name":
+				return stem
+			case "ext":
+				return ext
+			case "size":
+				return strconv.FormatInt(file.Info.Size(), 10)
+			case "mtime":
+				layout := m[2]
+				if layout == "" {
+					layout = time.DateOnly
+				}
+				return file.Info.ModTime().Format(layout)
+			default:
+				width, _ := strconv.Atoi(m[2])
+				return fmt.Sprintf("%0*d", width, start+file.Index)
+			}
+		}), nil
+	}, nil
+}
+
+// caseNames — режимы смены регистра
+var caseNames = map[string]func(string) string{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"title": titleCase,
+}
+
+// ParseCase возвращает правило смены регистра: lower, upper или title (заглавная первая буква каждого слова)
+func ParseCase(mode string) (RenameRule, error) {
+	convert, ok := caseNames[strings.ToLower(mode)]
+	if !ok {
+		return nil, fmt.Errorf(i18n.T("rename_unknown_case"), mode)
+	}
+	return func(name string, _ RenameFile) (string, error) {
+		return convert(name), nil
+	}, nil
+}
+
+// titleCase делает заглавной первую букву каждого слова и строчными остальные
+func titleCase(s string) string {
+	runes := []rune(strings.ToLower(s))
+	for i, r := range runes {
+		if i == 0 || !unicode.IsLetter(runes[i-1]) && !unicode.IsDigit(runes[i-1]) {
+			runes[i] = unicode.ToUpper(r)
+		}
+	}
+	return string(runes)
+}
+
+// ExtensionRule заменяет расширение имени на ext; пустое ext удаляет расширение
+func ExtensionRule(ext string) RenameRule {
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return func(name string, _ RenameFile) (string, error) {
+		current := filepath.Ext(name)
+		if current == name {
+			current = ""
+		}
+		return strings.TrimSuffix(name, current) + ext, nil
+	}
+}
+
+// RenameItem — переименование одного файла
+type RenameItem struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// RenamePlan — проверенный пакет переименований
+type RenamePlan struct {
+	// Items — переименования в порядке файлов; файлы, имя которых не меняется, не включаются
+	Items []RenameItem
+	// Steps — порядок перемещений: цепочки выполняются с конца, а циклы (a → b, b → a)
+	// разрываются через временное имя
+	Steps []RenameItem
+	// Cycles — число циклов в пакете
+	Cycles int
+}
+
+// PlanRename применяет правила к именам файлов и проверяет результат
+func PlanRename(paths []string, rules []RenameRule) (*RenamePlan, error) {
+	items := make([]RenameItem, 0, len(paths))
+	for i, path := range paths {
+		info, err := os.Lstat(path)
+		if err != nil {
+			return nil, fmt.Errorf(i18n.T("fileops_stat_error"), path, err)
+		}
+		name := filepath.Base(path)
+		for _, rule := range rules {
+			if name, err = rule(name, RenameFile{Path: path, Index: i, Info: info}); err != nil {
+				return nil, err
+			}
+		}
+		if name == filepath.Base(path) {
+			continue
+		}
+		items = append(items, RenameItem{From: path, To: filepath.Join(filepath.Dir(path), name)})
+	}
+	return NewRenamePlan(items)
+}
+
+// NewRenamePlan проверяет пакет переименований и определяет порядок перемещений. Ничего
+// не меняется, если два файла получают одно имя, новое имя занято файлом вне пакета
+// или имя недопустимо.
+func NewRenamePlan(items []RenameItem) (*RenamePlan, error) {
+	if err := checkRename(items); err != nil {
+		return nil, err
+	}
+	plan := &RenamePlan{Items: items}
+	plan.Steps, plan.Cycles = renameSteps(items)
+	return plan, nil
+}
+
+// checkRename проверяет имена, совпадения новых имен и занятость назначений
+func checkRename(items []RenameItem) error {
+	sources := make(map[string]bool, len(items))
+	for _, item := range items {
+		sources[filepath.Clean(item.From)] = true
+	}
+	targets := make(map[string]string, len(items))
+	for _, item := range items {
+		name := filepath.Base(item.To)
+		if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/`+string(filepath.Separator)) ||
+			filepath.Dir(item.To) != filepath.Dir(item.From) {
+			return fmt.Errorf(i18n.T("rename_bad_name"), item.From, name)
+		}
+		source, err := os.Lstat(item.From)
+		if err != nil {
+			return fmt.Errorf(i18n.T("fileops_stat_error"), item.From, err)
+		}
+		to := filepath.Clean(item.To)
+		if other, taken := targets[to]; taken {
+			return fmt.Errorf(i18n.T("rename_collision"), other, item.From, item.To)
+		}
+		targets[to] = item.From
+		if sources[to] {
+			continue
+		}
+		// Смена только регистра на нечувствительной к регистру файловой системе — тот же файл
+		if existing, err := os.Lstat(item.To); err == nil && !os.SameFile(source, existing) {
+			return fmt.Errorf(i18n.T("rename_exists"), item.From, item.To)
+		}
+	}
+	return nil
+}
+
+// renameSteps упорядочивает перемещения: файл переименовывается, когда его новое имя освобождено.
+// Оставшиеся переименования образуют циклы; каждый разрывается перемещением одного файла
+// под временное имя.
+func renameSteps(items []RenameItem) ([]RenameItem, int) {
+	pending := make(map[string]int, len(items))
+	for i, item := range items {
+		pending[filepath.Clean(item.From)] = i
+	}
+	// waiting — переименование, ожидающее освобождения имени
+	waiting := make(map[string]int, len(items))
+	current := make([]string, len(items))
+	var ready []int
+	for i, item := range items {
+		current[i] = item.From
+		if _, blocked := pending[filepath.Clean(item.To)]; blocked {
+			waiting[filepath.Clean(item.To)] = i
+		} else {
+			ready = append(ready, i)
+		}
+	}
+
+	steps := make([]RenameItem, 0, len(items))
+	cycles := 0
+	for len(pending) > 0 {
+		for len(ready) > 0 {
+			i := ready[0]
+			ready = ready[1:]
+			steps = append(steps, RenameItem{From: current[i], To: items[i].To})
+			freed := filepath.Clean(current[i])
+			delete(pending, freed)
+			if next, ok := waiting[freed]; ok {
+				delete(waiting, freed)
+				ready = append(ready, next)
+			}
+		}
+		if len(pending) == 0 {
+			break
+		}
+		// Все оставшиеся файлы ждут друг друга: цикл разрывается временным именем
+		cycles++
+		var from string
+		for path := range pending {
+			if from == "" || path < from {
+				from = path
+			}
+		}
+		i := pending[from]
+		temp := tempRenameName(current[i], items)
+		steps = append(steps, RenameItem{From: current[i], To: temp})
+		current[i] = temp
+		delete(pending, from)
+		if next, ok := waiting[from]; ok {
+			delete(waiting, from)
+			ready = append(ready, next)
+		}
+		// Сам файл по-прежнему ждет освобождения своего нового имени
+		pending[temp] = i
+	}
+	return steps, cycles
+}
+
+// tempRenameName возвращает свободное временное имя рядом с path, не совпадающее с именами пакета
+func tempRenameName(path string, items []RenameItem) string {
+	dir, name := filepath.Split(path)
+	for n := 1; ; n++ {
+		candidate := filepath.Join(dir, fmt.Sprintf(".%s.fm-rename-%d", name, n))
+		used := false
+		for _, item := range items {
+			if item.To == candidate || item.From == candidate {
+				used = true
+				break
+			}
+		}
+		if _, err := os.Lstat(candidate); !used && os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
+
+// Rename выполняет пакет переименований через MoveFile. Перед выполнением пакет проверяется
+// повторно; если перемещение не удалось, выполненные шаги отменяются в обратном порядке.
+func (f *FileOperator) Rename(plan *RenamePlan) error {
+	if err := checkRename(plan.Items); err != nil {
+		return err
+	}
+	for i, step := range plan.Steps {
+		if err := f.MoveFile(step.From, step.To); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				_ = f.MoveFile(plan.Steps[j].To, plan.Steps[j].From)
+			}
+			return fmt.Errorf(i18n.T("rename_failed"), err)
+		}
+	}
+	return nil
+}
+
+// Undo возвращает план, отменяющий пакет переименований
+func (p *RenamePlan) Undo() (*RenamePlan, error) {
+	items := make([]RenameItem, len(p.Items))
+	for i, item := range p.Items {
+		items[i] = RenameItem{From: item.To, To: item.From}
+	}
+	return NewRenamePlan(items)
+}
+
+// maxRenameHistory ограничивает число запоминаемых пакетов переименований
+const maxRenameHistory = 20
+
+// RenameHistory хранит выполненные пакеты переименований для отмены
+type RenameHistory struct {
+	Batches     [][]RenameItem
+	HistoryFile string
+}
+
+// NewRenameHistory загружает историю переименований из ~/.filemanager/rename-history.json
+func NewRenameHistory() (*RenameHistory, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("bm_home"), err)
+	}
+	configDir := filepath.Join(homeDir, ".filemanager")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return nil, fmt.Errorf(i18n.T("bm_dir"), err)
+	}
+	history := &RenameHistory{HistoryFile: filepath.Join(configDir, "rename-history.json")}
+	if err := history.LoadHistory(); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// Push запоминает выполненный пакет
+func (h *RenameHistory) Push(plan *RenamePlan) error {
+	h.Batches = append(h.Batches, plan.Items)
+	if len(h.Batches) > maxRenameHistory {
+		h.Batches = h.Batches[len(h.Batches)-maxRenameHistory:]
+	}
+	return h.SaveHistory()
+}
+
+// Last возвращает последний выполненный пакет
+func (h *RenameHistory) Last() (*RenamePlan, error) {
+	if len(h.Batches) == 0 {
+		return nil, errors.New(i18n.T("rename_history_empty"))
+	}
+	return &RenamePlan{Items: h.Batches[len(h.Batches)-1]}, nil
+}
+
+// Pop удаляет последний пакет после его отмены
+func (h *RenameHistory) Pop() error {
+	if len(h.Batches) == 0 {
+		return nil
+	}
+	h.Batches = h.Batches[:len(h.Batches)-1]
+	return h.SaveHistory()
+}
+
+// SaveHistory сохраняет историю в файл
+func (h *RenameHistory) SaveHistory() error {
+	data, err := json.MarshalIndent(h.Batches, "", "  ")
+	if err != nil {
+		return fmt.Errorf(i18n.T("rename_history_write"), err)
+	}
+	if err := os.WriteFile(h.HistoryFile, data, 0644); err != nil {
+		return fmt.Errorf(i18n.T("rename_history_write"), err)
+	}
+	return nil
+}
+
+// LoadHistory загружает историю из файла; отсутствующий файл означает пустую историю
+func (h *RenameHistory) LoadHistory() error {
+	data, err := os.ReadFile(h.HistoryFile)
+	if os.IsNotExist(err) {
+		h.Batches = nil
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf(i18n.T("rename_history_read"), err)
+	}
+	if err := json.Unmarshal(data, &h.Batches); err != nil {
+		return fmt.Errorf(i18n.T("rename_history_read"), err)
+	}
+	return nil
+}
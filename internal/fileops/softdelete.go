@@ -9,138 +9,106 @@ import (
 	"time"
 
 	"errors"
+	"file-manager/internal/glob"
 	"file-manager/internal/i18n"
 )
 
 // SoftDeleter определяет интерфейс для soft-delete (корзины)
 type SoftDeleter interface {
 	MoveToTrash(path string) error
-	RestoreFromTrash(fileName string) error
+	// RestoreFromTrash возвращает запись в destination (пусто — на исходное место), разрешая
+	// конфликт с занятым местом по правилам conflicts. Возвращает путь восстановленной записи
+	// или пустую строку, если она пропущена.
+	RestoreFromTrash(entry TrashEntry, destination string, conflicts *Conflicts) (string, error)
 	EmptyTrash() error
-	ListTrash() ([]string, error)
+	ListTrash() ([]TrashEntry, error)
 }
 
-// GetSoftDeleter возвращает платформозависимую реализацию soft-delete
-func GetSoftDeleter() SoftDeleter {
-	switch runtime.GOOS {
-	case "windows":
-		return &windowsSoftDeleter{}
-	case "darwin":
-		return &macSoftDeleter{}
-	default:
-		return &linuxSoftDeleter{}
-	}
+// TrashEntry — запись корзины
+type TrashEntry struct {
+	// Name — имя записи в корзине
+	Name string
+	// OriginalPath — исходный абсолютный путь; пуст, если корзина его не хранит
+	OriginalPath string
+	// DeletionDate — время удаления; нулевое, если корзина его не хранит
+	DeletionDate time.Time
+	// Size — размер файла или место, занятое директорией
+	Size  int64
+	IsDir bool
+	// Trash — директория корзины, в которой лежит запись
+	Trash string
 }
 
-// --- Linux ---
-type linuxSoftDeleter struct{}
-
-func (l *linuxSoftDeleter) MoveToTrash(path string) error {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf(i18n.T("softdelete_home_error"), err)
-	}
-	trashDir := filepath.Join(home, ".local", "share", "Trash", "files")
-	infoDir := filepath.Join(home, ".local", "share", "Trash", "info")
-	if err := os.MkdirAll(trashDir, 0755); err != nil {
-		return fmt.Errorf(i18n.T("softdelete_trashdir_error"), err)
-	}
-	if err := os.MkdirAll(infoDir, 0755); err != nil {
-		return fmt.Errorf(i18n.T("softdelete_infodir_error"), err)
+// DisplayPath возвращает исходный путь записи, а если он неизвестен — имя в корзине
+func (e TrashEntry) DisplayPath() string {
+	if e.OriginalPath != "" {
+		return e.OriginalPath
 	}
-	fileName := filepath.Base(path)
-	baseName := fileName
-	suffix := 1
-	for {
-		if _, err := os.Stat(filepath.Join(trashDir, fileName)); os.IsNotExist(err) {
-			break
-		}
-		fileName = fmt.Sprintf("%s_%d", baseName, suffix)
-		suffix++
-	}
-	dest := filepath.Join(trashDir, fileName)
-	if err := os.Rename(path, dest); err != nil {
-		return fmt.Errorf(i18n.T("softdelete_move_error"), err)
-	}
-	// Создаём .trashinfo
-	trashInfo := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n", path, time.Now().Format("2006-01-02T15:04:05"))
-	infoPath := filepath.Join(infoDir, fileName+".trashinfo")
-	if err := os.WriteFile(infoPath, []byte(trashInfo), 0644); err != nil {
-		return fmt.Errorf(i18n.T("softdelete_trashinfo_error"), err)
-	}
-	return nil
+	return e.Name
 }
 
-func (l *linuxSoftDeleter) RestoreFromTrash(fileName string) error {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return err
-	}
-	trashDir := filepath.Join(home, ".local", "share", "Trash", "files")
-	infoDir := filepath.Join(home, ".local", "share", "Trash", "info")
-	infoPath := filepath.Join(infoDir, fileName+".trashinfo")
-	data, err := os.ReadFile(infoPath)
-	if err != nil {
-		return fmt.Errorf(i18n.T("softdelete_read_trashinfo_error"), err)
-	}
-	lines := strings.Split(string(data), "\n")
-	var origPath string
-	for _, line := range lines {
-		if strings.HasPrefix(line, "Path=") {
-			origPath = strings.TrimPrefix(line, "Path=")
-			break
-		}
-	}
-	if origPath == "" {
-		return errors.New(i18n.T("softdelete_origpath_not_found"))
-	}
-	filePath := filepath.Join(trashDir, fileName)
-	if err := os.Rename(filePath, origPath); err != nil {
-		return fmt.Errorf(i18n.T("softdelete_restore_error"), err)
-	}
-	if err := os.Remove(infoPath); err != nil {
-		return err
-	}
-	return nil
+// TrashFilter отбирает записи корзины; пустые поля выбор не ограничивают
+type TrashFilter struct {
+	// Prefix — исходный путь записи или одной из ее родительских директорий
+	Prefix string
+	// Pattern проверяется по исходному пути: шаблон без / — по последнему элементу
+	Pattern *glob.Pattern
+	// After и Before ограничивают время удаления
+	After, Before time.Time
 }
 
-func (l *linuxSoftDeleter) EmptyTrash() error {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return err
-	}
-	trashDir := filepath.Join(home, ".local", "share", "Trash", "files")
-	infoDir := filepath.Join(home, ".local", "share", "Trash", "info")
-	if err := os.RemoveAll(trashDir); err != nil {
-		return err
+// Match проверяет, подходит ли запись под все условия фильтра
+func (f TrashFilter) Match(entry TrashEntry) bool {
+	path := entry.DisplayPath()
+	if f.Prefix != "" {
+		prefix := filepath.Clean(f.Prefix)
+		if path != prefix && !strings.HasPrefix(path, strings.TrimSuffix(prefix, string(filepath.Separator))+string(filepath.Separator)) {
+			return false
+		}
 	}
-	if err := os.RemoveAll(infoDir); err != nil {
-		return err
+	if f.Pattern != nil && !f.Pattern.MatchPath(filepath.ToSlash(path)) {
+		return false
 	}
-	if err := os.MkdirAll(trashDir, 0755); err != nil {
-		return err
+	if !f.After.IsZero() && entry.DeletionDate.Before(f.After) {
+		return false
 	}
-	if err := os.MkdirAll(infoDir, 0755); err != nil {
-		return err
+	if !f.Before.IsZero() && (entry.DeletionDate.IsZero() || entry.DeletionDate.After(f.Before)) {
+		return false
 	}
-	return nil
+	return true
 }
 
-func (l *linuxSoftDeleter) ListTrash() ([]string, error) {
-	home, err := os.UserHomeDir()
+// listTrashDir возвращает записи корзины без сведений об исходном пути и времени удаления
+func listTrashDir(trashDir string) ([]TrashEntry, error) {
+	items, err := os.ReadDir(trashDir)
 	if err != nil {
 		return nil, err
 	}
-	trashDir := filepath.Join(home, ".local", "share", "Trash", "files")
-	entries, err := os.ReadDir(trashDir)
-	if err != nil {
-		return nil, err
+	var entries []TrashEntry
+	for _, item := range items {
+		info, err := item.Info()
+		if err != nil {
+			continue
+		}
+		entry := TrashEntry{Name: item.Name(), Size: info.Size(), IsDir: info.IsDir(), Trash: trashDir}
+		if entry.IsDir {
+			entry.Size = diskUsageBytes(filepath.Join(trashDir, item.Name()))
+		}
+		entries = append(entries, entry)
 	}
-	var files []string
-	for _, entry := range entries {
-		files = append(files, entry.Name())
+	return entries, nil
+}
+
+// GetSoftDeleter возвращает платформозависимую реализацию soft-delete
+func GetSoftDeleter() SoftDeleter {
+	switch runtime.GOOS {
+	case "windows":
+		return &windowsSoftDeleter{}
+	case "darwin":
+		return &macSoftDeleter{}
+	default:
+		return &linuxSoftDeleter{}
 	}
-	return files, nil
 }
 
 // --- macOS ---
@@ -166,11 +134,11 @@ func (m *macSoftDeleter) MoveToTrash(path string) error {
 		suffix++
 	}
 	dest := filepath.Join(trashDir, fileName)
-	return os.Rename(path, dest)
+	return movePath(path, dest)
 }
 
-func (m *macSoftDeleter) RestoreFromTrash(_ string) error {
-	return errors.New(i18n.T("softdelete_restore_unsupported_mac"))
+func (m *macSoftDeleter) RestoreFromTrash(_ TrashEntry, _ string, _ *Conflicts) (string, error) {
+	return "", errors.New(i18n.T("softdelete_restore_unsupported_mac"))
 }
 
 func (m *macSoftDeleter) EmptyTrash() error {
@@ -191,21 +159,12 @@ func (m *macSoftDeleter) EmptyTrash() error {
 	return nil
 }
 
-func (m *macSoftDeleter) ListTrash() ([]string, error) {
+func (m *macSoftDeleter) ListTrash() ([]TrashEntry, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return nil, err
 	}
-	trashDir := filepath.Join(home, ".Trash")
-	entries, err := os.ReadDir(trashDir)
-	if err != nil {
-		return nil, err
-	}
-	var files []string
-	for _, entry := range entries {
-		files = append(files, entry.Name())
-	}
-	return files, nil
+	return listTrashDir(filepath.Join(home, ".Trash"))
 }
 
 // --- Windows ---
@@ -231,11 +190,11 @@ func (w *windowsSoftDeleter) MoveToTrash(path string) error {
 		suffix++
 	}
 	dest := filepath.Join(trashDir, fileName)
-	return os.Rename(path, dest)
+	return movePath(path, dest)
 }
 
-func (w *windowsSoftDeleter) RestoreFromTrash(_ string) error {
-	return fmt.Errorf(i18n.T("softdelete_restore_unsupported_win"))
+func (w *windowsSoftDeleter) RestoreFromTrash(_ TrashEntry, _ string, _ *Conflicts) (string, error) {
+	return "", fmt.Errorf(i18n.T("softdelete_restore_unsupported_win"))
 }
 
 func (w *windowsSoftDeleter) EmptyTrash() error {
@@ -256,19 +215,10 @@ func (w *windowsSoftDeleter) EmptyTrash() error {
 	return nil
 }
 
-func (w *windowsSoftDeleter) ListTrash() ([]string, error) {
+func (w *windowsSoftDeleter) ListTrash() ([]TrashEntry, error) {
 	userProfile := os.Getenv("USERPROFILE")
 	if userProfile == "" {
 		return nil, fmt.Errorf(i18n.T("softdelete_userprofile_error"))
 	}
-	trashDir := filepath.Join(userProfile, "Recycle.Bin")
-	entries, err := os.ReadDir(trashDir)
-	if err != nil {
-		return nil, err
-	}
-	var files []string
-	for _, entry := range entries {
-		files = append(files, entry.Name())
-	}
-	return files, nil
+	return listTrashDir(filepath.Join(userProfile, "Recycle.Bin"))
 }
@@ -0,0 +1,295 @@
+package fileops
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"file-manager/internal/glob"
+	"file-manager/internal/i18n"
+)
+
+// SyncAction — действие над записью назначения при синхронизации
+type SyncAction int
+
+const (
+	// SyncCreate — запись отсутствует в назначении и копируется
+	SyncCreate SyncAction = iota
+	// SyncUpdate — запись в назначении отличается от источника и заменяется
+	SyncUpdate
+	// SyncDelete — запись назначения удаляется: ее нет в источнике или она другого типа
+	SyncDelete
+)
+
+// SyncItem — одно изменение назначения
+type SyncItem struct {
+	Action SyncAction
+	// Path — путь относительно корня синхронизации ("." — сам корень)
+	Path        string
+	Source      string
+	Destination string
+	IsDir       bool
+	// Size — объем копируемых данных (для удаления — 0)
+	Size int64
+}
+
+// SyncPlan — список изменений, после которых назначение совпадет с источником.
+// Записи идут в порядке обхода: директория раньше своего содержимого.
+type SyncPlan struct {
+	Source      string
+	Destination string
+	Items       []SyncItem
+}
+
+// SyncSummary — итог синхронизации
+type SyncSummary struct {
+	Created, Updated, Deleted int
+	// Bytes — объем копируемых данных
+	Bytes int64
+}
+
+// SyncOptions задает режим синхронизации
+type SyncOptions struct {
+	// Delete удаляет записи назначения, которых нет в источнике
+	Delete bool
+	// Checksum сравнивает файлы одинакового размера по SHA-256, а не по времени изменения
+	Checksum bool
+	// Exclude — записи, подходящие под шаблон, не копируются и не удаляются. Шаблон без /
+	// сравнивается с именем, шаблон с / — с путем относительно корня синхронизации.
+	Exclude []*glob.Pattern
+	// Trash отправляет удаляемые записи в корзину, а не удаляет безвозвратно
+	Trash bool
+}
+
+// syncCopyOptions — копирование при синхронизации: время изменения сохраняется, иначе следующая
+// синхронизация сочла бы каждый файл измененным; ссылки копируются как ссылки
+var syncCopyOptions = CopyOptions{Preserve: PreserveMode | PreserveTimes | PreserveLinks | PreserveSpecial}
+
+// Summary подсчитывает изменения плана
+func (p *SyncPlan) Summary() SyncSummary {
+	var summary SyncSummary
+	for _, item := range p.Items {
+		switch item.Action {
+		case SyncCreate:
+			summary.Created++
+		case SyncUpdate:
+			summary.Updated++
+		case SyncDelete:
+			summary.Deleted++
+		}
+		summary.Bytes += item.Size
+	}
+	return summary
+}
+
+// PlanSync сравнивает source с destination и составляет список изменений. Обычные файлы
+// считаются изменившимися, если отличаются размер или время изменения (с точностью до секунды),
+// а с options.Checksum — размер или содержимое.
+func PlanSync(source, destination string, options SyncOptions) (*SyncPlan, error) {
+	source, destination = filepath.Clean(source), filepath.Clean(destination)
+	if within(destination, source) {
+		return nil, fmt.Errorf(i18n.T("sync_nested"), destination, source)
+	}
+	// При удалении лишних файлов назначения был бы удален и сам источник
+	if options.Delete && within(source, destination) {
+		return nil, fmt.Errorf(i18n.T("sync_ancestor"), source, destination)
+	}
+	info, err := os.Lstat(source)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("fileops_stat_error"), source, err)
+	}
+	p := &syncPlanner{plan: &SyncPlan{Source: source, Destination: destination}, options: options}
+	if err := p.entry(".", info); err != nil {
+		return nil, err
+	}
+	return p.plan, nil
+}
+
+// within проверяет, совпадает ли path с dir или находится внутри нее
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && (rel == "." || rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// syncPlanner составляет план синхронизации
+type syncPlanner struct {
+	plan    *SyncPlan
+	options SyncOptions
+}
+
+// excluded проверяет, исключена ли запись шаблонами
+func (p *syncPlanner) excluded(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range p.options.Exclude {
+		if pattern.MatchPath(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// add добавляет изменение в план
+func (p *syncPlanner) add(action SyncAction, rel string, info fs.FileInfo) {
+	item := SyncItem{
+		Action:      action,
+		Path:        rel,
+		Source:      filepath.Join(p.plan.Source, rel),
+		Destination: filepath.Join(p.plan.Destination, rel),
+		IsDir:       info.IsDir(),
+	}
+	if action != SyncDelete && info.Mode().IsRegular() {
+		item.Size = info.Size()
+	}
+	p.plan.Items = append(p.plan.Items, item)
+}
+
+// entry сравнивает запись источника с одноименной записью назначения
+func (p *syncPlanner) entry(rel string, info fs.FileInfo) error {
+	destination := filepath.Join(p.plan.Destination, rel)
+	existing, err := os.Lstat(destination)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf(i18n.T("fileops_stat_error"), destination, err)
+	}
+	if err == nil && existing.Mode().Type() != info.Mode().Type() {
+		// Запись другого типа нельзя обновить на месте: она удаляется и создается заново
+		p.add(SyncDelete, rel, existing)
+		existing = nil
+	}
+	if existing == nil {
+		p.add(SyncCreate, rel, info)
+		if info.IsDir() {
+			return p.dir(rel, false)
+		}
+		return nil
+	}
+	if info.IsDir() {
+		return p.dir(rel, true)
+	}
+	changed, err := p.changed(filepath.Join(p.plan.Source, rel), destination, info, existing)
+	if err != nil {
+		return err
+	}
+	if changed {
+		p.add(SyncUpdate, rel, info)
+	}
+	return nil
+}
+
+// dir сравнивает содержимое директорий; exists — директория назначения уже существует
+func (p *syncPlanner) dir(rel string, exists bool) error {
+	source := filepath.Join(p.plan.Source, rel)
+	entries, err := os.ReadDir(source)
+	if err != nil {
+		return fmt.Errorf(i18n.T("fileops_read_dir_error"), source, err)
+	}
+	names := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		names[entry.Name()] = true
+		child := filepath.Join(rel, entry.Name())
+		if p.excluded(child) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return fmt.Errorf(i18n.T("fileops_stat_error"), filepath.Join(source, entry.Name()), err)
+		}
+		if err := p.entry(child, info); err != nil {
+			return err
+		}
+	}
+	if !exists || !p.options.Delete {
+		return nil
+	}
+
+	destination := filepath.Join(p.plan.Destination, rel)
+	extra, err := os.ReadDir(destination)
+	if err != nil {
+		return fmt.Errorf(i18n.T("fileops_read_dir_error"), destination, err)
+	}
+	for _, entry := range extra {
+		child := filepath.Join(rel, entry.Name())
+		if names[entry.Name()] || p.excluded(child) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return fmt.Errorf(i18n.T("fileops_stat_error"), filepath.Join(destination, entry.Name()), err)
+		}
+		p.add(SyncDelete, child, info)
+	}
+	return nil
+}
+
+// changed сравнивает записи одного типа
+func (p *syncPlanner) changed(source, destination string, info, existing fs.FileInfo) (bool, error) {
+	switch {
+	case info.Mode()&fs.ModeSymlink != 0:
+		target, err := os.Readlink(source)
+		if err != nil {
+			return false, fmt.Errorf(i18n.T("fileops_symlink_error"), source, err)
+		}
+		current, err := os.Readlink(destination)
+		return err != nil || current != target, nil
+	case !info.Mode().IsRegular():
+		// Специальные файлы одного типа не сравниваются
+		return false, nil
+	case info.Size() != existing.Size():
+		return true, nil
+	case p.options.Checksum:
+		same, err := sameContent(source, destination)
+		if err != nil {
+			return false, fmt.Errorf(i18n.T("fileops_checksum_error"), err)
+		}
+		return !same, nil
+	default:
+		return info.ModTime().Unix() != existing.ModTime().Unix(), nil
+	}
+}
+
+// Sync выполняет план синхронизации. Файлы копируются тем же механизмом, что и CopyFile,
+// но с сохранением времени изменения и ссылок. Права и время директорий устанавливаются
+// после копирования их содержимого.
+func (f *FileOperator) Sync(plan *SyncPlan, options SyncOptions) error {
+	c := newCopier(syncCopyOptions)
+	var dirs []SyncItem
+	for _, item := range plan.Items {
+		switch {
+		case item.Action == SyncDelete:
+			if err := f.syncDelete(item.Destination, options.Trash); err != nil {
+				return err
+			}
+		case item.IsDir:
+			if err := os.MkdirAll(item.Destination, 0700); err != nil {
+				return fmt.Errorf(i18n.T("fileops_create_dir_error"), item.Destination, err)
+			}
+			dirs = append(dirs, item)
+		default:
+			if err := f.Copy(item.Source, item.Destination, syncCopyOptions); err != nil {
+				return err
+			}
+		}
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		info, err := os.Lstat(dirs[i].Source)
+		if err == nil {
+			err = c.copyAttributes(dirs[i].Source, dirs[i].Destination, info)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncDelete удаляет запись назначения или отправляет ее в корзину
+func (f *FileOperator) syncDelete(path string, trash bool) error {
+	if trash {
+		return f.DeleteFile(path)
+	}
+	if err := removeTree(path); err != nil {
+		return fmt.Errorf(i18n.T("fileops_delete_dir_error"), path, err)
+	}
+	return nil
+}
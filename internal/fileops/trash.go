@@ -0,0 +1,592 @@
+package fileops
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"file-manager/internal/i18n"
+	"file-manager/internal/sysinfo"
+)
+
+// Корзина Linux и других Unix, кроме macOS, устроена по спецификации FreeDesktop.org Trash 1.0,
+// поэтому ее содержимое видят GNOME, KDE и другие файловые менеджеры, а их удаленные файлы видны здесь.
+
+const (
+	// trashInfoGroup — заголовок группы в файле .trashinfo
+	trashInfoGroup = "[Trash Info]"
+	// trashDateLayout — формат DeletionDate: местное время без часового пояса
+	trashDateLayout = "2006-01-02T15:04:05"
+	// trashSizesFile — кэш размеров удаленных директорий
+	trashSizesFile = "directorysizes"
+)
+
+// trashDateLayouts — принимаемые форматы DeletionDate; второй встречается в ранних версиях спецификации
+var trashDateLayouts = []string{trashDateLayout, "20060102T15:04:05"}
+
+type linuxSoftDeleter struct{}
+
+// trashDir — одна корзина: в files лежат удаленные записи, в info — их файлы .trashinfo
+type trashDir struct {
+	path string
+	// topdir — корень тома для корзин $topdir/.Trash/$uid и $topdir/.Trash-$uid. У домашней корзины
+	// он пуст, и пути в ней абсолютные.
+	topdir string
+}
+
+func (t trashDir) files() string { return filepath.Join(t.path, "files") }
+
+func (t trashDir) info() string { return filepath.Join(t.path, "info") }
+
+// infoPath возвращает путь к файлу .trashinfo записи name
+func (t trashDir) infoPath(name string) string {
+	return filepath.Join(t.info(), name+".trashinfo")
+}
+
+// ensure создает поддиректории files и info
+func (t trashDir) ensure() error {
+	if err := os.MkdirAll(t.files(), 0700); err != nil {
+		return fmt.Errorf(i18n.T("softdelete_trashdir_error"), err)
+	}
+	if err := os.MkdirAll(t.info(), 0700); err != nil {
+		return fmt.Errorf(i18n.T("softdelete_infodir_error"), err)
+	}
+	return nil
+}
+
+// homeTrash возвращает домашнюю корзину $XDG_DATA_HOME/Trash (по умолчанию ~/.local/share/Trash)
+func homeTrash() (trashDir, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if !filepath.IsAbs(dataHome) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return trashDir{}, fmt.Errorf(i18n.T("softdelete_home_error"), err)
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return trashDir{path: filepath.Join(dataHome, "Trash")}, nil
+}
+
+// volumeTrashes возвращает корзины тома topdir в порядке предпочтения. $topdir/.Trash/$uid
+// используется, только если общая $topdir/.Trash — директория с битом sticky, а не ссылка;
+// запасная корзина — $topdir/.Trash-$uid. При create недостающая корзина создается с правами 0700,
+// иначе возвращаются только существующие. Ссылки и чужие директории не используются.
+func volumeTrashes(topdir string, create bool) []trashDir {
+	uid := strconv.Itoa(os.Getuid())
+	var candidates []string
+	if info, err := os.Lstat(filepath.Join(topdir, ".Trash")); err == nil && info.IsDir() && info.Mode()&fs.ModeSticky != 0 {
+		candidates = append(candidates, filepath.Join(topdir, ".Trash", uid))
+	}
+	candidates = append(candidates, filepath.Join(topdir, ".Trash-"+uid))
+	var trashes []trashDir
+	for _, dir := range candidates {
+		if create {
+			if err := os.Mkdir(dir, 0700); err != nil && !os.IsExist(err) {
+				continue
+			}
+		}
+		info, err := os.Lstat(dir)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		if stat, ok := sysinfo.Stat(info); ok && int(stat.UID) != os.Getuid() {
+			continue
+		}
+		trashes = append(trashes, trashDir{path: dir, topdir: topdir})
+	}
+	return trashes
+}
+
+// mountRoot возвращает корень тома с устройством dev, на котором лежит path: самую верхнюю
+// из родительских директорий на том же устройстве
+func mountRoot(path string, dev uint64) string {
+	dir := filepath.Dir(path)
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		info, err := os.Lstat(parent)
+		if err != nil {
+			return dir
+		}
+		if stat, ok := sysinfo.Stat(info); !ok || stat.Dev != dev {
+			return dir
+		}
+		dir = parent
+	}
+}
+
+// mountPoints возвращает точки монтирования из /proc/self/mounts; где его нет, список пуст
+func mountPoints() []string {
+	data, err := os.ReadFile("/proc/self/mounts")
+	if err != nil {
+		return nil
+	}
+	var mounts []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		// Пробелы и другие специальные символы записаны восьмеричными последовательностями: \040
+		mount, err := strconv.Unquote(`"` + strings.ReplaceAll(fields[1], `"`, `\"`) + `"`)
+		if err != nil {
+			mount = fields[1]
+		}
+		mounts = append(mounts, mount)
+	}
+	return mounts
+}
+
+// trashFor выбирает корзину для записи path: домашнюю, если запись на том же томе, иначе корзину
+// тома. Если корзину тома использовать нельзя, запись попадает в домашнюю копированием.
+func trashFor(path string, info fs.FileInfo) (trashDir, error) {
+	home, err := homeTrash()
+	if err != nil {
+		return trashDir{}, err
+	}
+	if err := home.ensure(); err != nil {
+		return trashDir{}, err
+	}
+	stat, ok := sysinfo.Stat(info)
+	homeInfo, err := os.Stat(home.path)
+	if !ok || err != nil {
+		return home, nil
+	}
+	if homeStat, ok := sysinfo.Stat(homeInfo); !ok || homeStat.Dev == stat.Dev {
+		return home, nil
+	}
+	for _, trash := range volumeTrashes(mountRoot(path, stat.Dev), true) {
+		if trash.ensure() == nil {
+			return trash, nil
+		}
+	}
+	return home, nil
+}
+
+// trashDirs возвращает существующие корзины: домашнюю и корзины смонтированных томов
+func trashDirs() ([]trashDir, error) {
+	home, err := homeTrash()
+	if err != nil {
+		return nil, err
+	}
+	dirs := []trashDir{home}
+	for _, mount := range mountPoints() {
+		for _, trash := range volumeTrashes(mount, false) {
+			if trash.path != home.path {
+				dirs = append(dirs, trash)
+			}
+		}
+	}
+	return dirs, nil
+}
+
+// MoveToTrash перемещает запись в корзину ее тома. Сначала под свободным именем атомарно создается
+// файл .trashinfo, затем переносится сама запись; для директорий обновляется кэш directorysizes.
+func (l *linuxSoftDeleter) MoveToTrash(path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf(i18n.T("softdelete_move_error"), err)
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return fmt.Errorf(i18n.T("softdelete_move_error"), err)
+	}
+	trash, err := trashFor(path, info)
+	if err != nil {
+		return err
+	}
+	// В корзине тома путь записывается относительно его корня, чтобы он не зависел от точки монтирования
+	original := path
+	if trash.topdir != "" {
+		if rel, err := filepath.Rel(trash.topdir, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			original = rel
+		}
+	}
+	name, err := trash.reserve(filepath.Base(path), formatTrashInfo(original, time.Now()))
+	if err != nil {
+		return err
+	}
+	if err := movePath(path, filepath.Join(trash.files(), name)); err != nil {
+		_ = os.Remove(trash.infoPath(name))
+		return fmt.Errorf(i18n.T("softdelete_move_error"), err)
+	}
+	if info.IsDir() {
+		// Кэш только ускоряет подсчет размера корзины, поэтому ошибка его записи не отменяет удаление
+		_ = trash.updateDirectorySizes(name, true)
+	}
+	return nil
+}
+
+// reserve подбирает в корзине свободное имя для записи base и создает для него файл .trashinfo
+// с флагом O_EXCL, поэтому одновременные удаления не займут одно имя. Имя свободно, если нет ни
+// файла в info, ни записи в files. Повторы получают номер перед расширением: report.2.txt.
+func (t trashDir) reserve(base string, content []byte) (string, error) {
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	if stem == "" {
+		stem, ext = base, ""
+	}
+	for n := 1; ; n++ {
+		name := base
+		if n > 1 {
+			name = fmt.Sprintf("%s.%d%s", stem, n, ext)
+		}
+		file, err := os.OpenFile(t.infoPath(name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf(i18n.T("softdelete_trashinfo_error"), err)
+		}
+		if _, err := os.Lstat(filepath.Join(t.files(), name)); err == nil {
+			// Запись без .trashinfo осталась от прерванного удаления, ее имя тоже занято
+			_ = file.Close()
+			_ = os.Remove(t.infoPath(name))
+			continue
+		}
+		_, err = file.Write(content)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			_ = os.Remove(t.infoPath(name))
+			return "", fmt.Errorf(i18n.T("softdelete_trashinfo_error"), err)
+		}
+		return name, nil
+	}
+}
+
+// trashInfo — сведения файла .trashinfo
+type trashInfo struct {
+	// Path — исходный абсолютный путь записи
+	Path         string
+	DeletionDate time.Time
+}
+
+// formatTrashInfo формирует содержимое файла .trashinfo
+func formatTrashInfo(path string, deleted time.Time) []byte {
+	return []byte(fmt.Sprintf("%s\nPath=%s\nDeletionDate=%s\n", trashInfoGroup, escapeTrashPath(path), deleted.Format(trashDateLayout)))
+}
+
+// parseTrashInfo разбирает файл .trashinfo: ключи Path и DeletionDate группы [Trash Info], другие
+// группы и ключи пропускаются. Относительный путь отсчитывается от корня тома topdir.
+func parseTrashInfo(data []byte, topdir string) (trashInfo, error) {
+	var info trashInfo
+	inGroup := false
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.HasPrefix(line, "[") {
+			inGroup = line == trashInfoGroup
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !inGroup || !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "Path":
+			if info.Path == "" {
+				info.Path = unescapeTrashPath(strings.TrimSpace(value))
+			}
+		case "DeletionDate":
+			for _, layout := range trashDateLayouts {
+				if date, err := time.ParseInLocation(layout, strings.TrimSpace(value), time.Local); err == nil {
+					info.DeletionDate = date
+					break
+				}
+			}
+		}
+	}
+	if info.Path == "" {
+		return info, errors.New(i18n.T("softdelete_origpath_not_found"))
+	}
+	if !filepath.IsAbs(info.Path) {
+		root := topdir
+		if root == "" {
+			root = string(filepath.Separator)
+		}
+		info.Path = filepath.Join(root, info.Path)
+	}
+	return info, nil
+}
+
+// escapeTrashPath кодирует путь для ключа Path и файла directorysizes: все байты, кроме
+// незарезервированных символов RFC 2396 и /, записываются как %XX
+func escapeTrashPath(path string) string {
+	const hexDigits = "0123456789ABCDEF"
+	var sb strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("/-_.!~*'()", c) >= 0 {
+			sb.WriteByte(c)
+			continue
+		}
+		sb.WriteByte('%')
+		sb.WriteByte(hexDigits[c>>4])
+		sb.WriteByte(hexDigits[c&15])
+	}
+	return sb.String()
+}
+
+// unescapeTrashPath декодирует путь из .trashinfo; неверно закодированный путь возвращается как есть
+func unescapeTrashPath(path string) string {
+	if decoded, err := url.PathUnescape(path); err == nil {
+		return decoded
+	}
+	return path
+}
+
+// directorySize — строка файла directorysizes
+type directorySize struct {
+	// Size — место, занятое директорией, в байтах
+	Size int64
+	// MTime — время изменения ее файла .trashinfo в секундах Unix
+	MTime int64
+	Name  string
+}
+
+// parseDirectorySizes разбирает файл directorysizes: строки «размер время имя», имя закодировано
+// как путь в .trashinfo. Неверные строки пропускаются.
+func parseDirectorySizes(data []byte) []directorySize {
+	var sizes []directorySize
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.SplitN(strings.TrimRight(line, "\r"), " ", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		mtime, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		sizes = append(sizes, directorySize{Size: size, MTime: mtime, Name: unescapeTrashPath(fields[2])})
+	}
+	return sizes
+}
+
+// formatDirectorySizes формирует содержимое файла directorysizes
+func formatDirectorySizes(sizes []directorySize) []byte {
+	var buf bytes.Buffer
+	for _, size := range sizes {
+		fmt.Fprintf(&buf, "%d %d %s\n", size.Size, size.MTime, escapeTrashPath(size.Name))
+	}
+	return buf.Bytes()
+}
+
+// updateDirectorySizes переписывает кэш directorysizes: убирает строку записи name и строки
+// записей без .trashinfo, а при add добавляет name с текущим размером. Файл заменяется атомарно.
+func (t trashDir) updateDirectorySizes(name string, add bool) error {
+	sizesPath := filepath.Join(t.path, trashSizesFile)
+	data, err := os.ReadFile(sizesPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	var sizes []directorySize
+	for _, size := range parseDirectorySizes(data) {
+		if _, err := os.Stat(t.infoPath(size.Name)); size.Name != name && err == nil {
+			sizes = append(sizes, size)
+		}
+	}
+	if add {
+		info, err := os.Stat(t.infoPath(name))
+		if err != nil {
+			return err
+		}
+		sizes = append(sizes, directorySize{Size: diskUsageBytes(filepath.Join(t.files(), name)), MTime: info.ModTime().Unix(), Name: name})
+	}
+	if len(sizes) == 0 {
+		if err := os.Remove(sizesPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	temp, err := os.CreateTemp(t.path, trashSizesFile+".")
+	if err != nil {
+		return err
+	}
+	_, err = temp.Write(formatDirectorySizes(sizes))
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(temp.Name(), sizesPath)
+	}
+	if err != nil {
+		_ = os.Remove(temp.Name())
+	}
+	return err
+}
+
+// diskUsageBytes подсчитывает место, занятое деревом root, как du -B1: блоки всех записей, включая
+// директории; файл с несколькими жесткими ссылками учитывается один раз. Где блоки неизвестны,
+// берется размер файла.
+func diskUsageBytes(root string) int64 {
+	var total int64
+	seen := make(map[[2]uint64]bool)
+	_ = filepath.WalkDir(root, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		stat, ok := sysinfo.Stat(info)
+		if !ok {
+			total += info.Size()
+			return nil
+		}
+		if stat.Nlink > 1 && !info.IsDir() {
+			key := [2]uint64{stat.Dev, stat.Ino}
+			if seen[key] {
+				return nil
+			}
+			seen[key] = true
+		}
+		total += stat.Blocks * 512
+		return nil
+	})
+	return total
+}
+
+// RestoreFromTrash возвращает запись корзины в destination или на исходное место. Недостающие
+// родительские директории создаются заново, занятое место разрешается по правилам conflicts
+// (директории не объединяются). Файл .trashinfo удаляется только после переноса записи.
+func (l *linuxSoftDeleter) RestoreFromTrash(entry TrashEntry, destination string, conflicts *Conflicts) (string, error) {
+	trash := trashDir{path: entry.Trash}
+	source := filepath.Join(trash.files(), entry.Name)
+	info, err := os.Lstat(source)
+	if entry.Name != filepath.Base(entry.Name) || err != nil {
+		return "", fmt.Errorf(i18n.T("softdelete_not_in_trash"), entry.Name)
+	}
+	if destination == "" {
+		destination = entry.OriginalPath
+	}
+	if destination == "" {
+		return "", errors.New(i18n.T("softdelete_origpath_not_found"))
+	}
+	target, err := conflicts.resolve(source, destination, info, false)
+	if err != nil || target == "" {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return "", fmt.Errorf(i18n.T("softdelete_restore_error"), err)
+	}
+	if err := movePath(source, target); err != nil {
+		return "", fmt.Errorf(i18n.T("softdelete_restore_error"), err)
+	}
+	if err := os.Remove(trash.infoPath(entry.Name)); err != nil && !os.IsNotExist(err) {
+		return target, err
+	}
+	_ = trash.updateDirectorySizes(entry.Name, false)
+	return target, nil
+}
+
+// EmptyTrash удаляет записи всех корзин по одной: сначала запись из files, затем ее .trashinfo,
+// потом оставшиеся без записей .trashinfo и кэш directorysizes. Сами директории корзин не удаляются.
+func (l *linuxSoftDeleter) EmptyTrash() error {
+	dirs, err := trashDirs()
+	if err != nil {
+		return err
+	}
+	for _, trash := range dirs {
+		entries, err := os.ReadDir(trash.files())
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		for _, entry := range entries {
+			if err := removeTree(filepath.Join(trash.files(), entry.Name())); err != nil {
+				return err
+			}
+			if err := os.Remove(trash.infoPath(entry.Name())); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		infos, err := os.ReadDir(trash.info())
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		for _, entry := range infos {
+			if strings.HasSuffix(entry.Name(), ".trashinfo") {
+				if err := os.Remove(filepath.Join(trash.info(), entry.Name())); err != nil {
+					return err
+				}
+			}
+		}
+		if err := os.Remove(filepath.Join(trash.path, trashSizesFile)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListTrash возвращает записи домашней корзины и корзин томов в порядке удаления. Запись без
+// .trashinfo попадает в список без исходного пути. Размер директории берется из кэша directorysizes,
+// если кэш соответствует ее .trashinfo, иначе подсчитывается заново.
+func (l *linuxSoftDeleter) ListTrash() ([]TrashEntry, error) {
+	dirs, err := trashDirs()
+	if err != nil {
+		return nil, err
+	}
+	var entries []TrashEntry
+	for _, trash := range dirs {
+		items, err := os.ReadDir(trash.files())
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sizesData, _ := os.ReadFile(filepath.Join(trash.path, trashSizesFile))
+		cached := make(map[string]directorySize)
+		for _, size := range parseDirectorySizes(sizesData) {
+			cached[size.Name] = size
+		}
+		for _, item := range items {
+			info, err := item.Info()
+			if err != nil {
+				continue
+			}
+			entry := TrashEntry{Name: item.Name(), Size: info.Size(), IsDir: info.IsDir(), Trash: trash.path}
+			infoStat, statErr := os.Stat(trash.infoPath(entry.Name))
+			if data, err := os.ReadFile(trash.infoPath(entry.Name)); err == nil {
+				if parsed, err := parseTrashInfo(data, trash.topdir); err == nil {
+					entry.OriginalPath, entry.DeletionDate = parsed.Path, parsed.DeletionDate
+				}
+			}
+			if entry.IsDir {
+				if size, ok := cached[entry.Name]; ok && statErr == nil && size.MTime == infoStat.ModTime().Unix() {
+					entry.Size = size.Size
+				} else {
+					entry.Size = diskUsageBytes(filepath.Join(trash.files(), entry.Name))
+				}
+			}
+			entries = append(entries, entry)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].DeletionDate.Equal(entries[j].DeletionDate) {
+			return entries[i].DeletionDate.Before(entries[j].DeletionDate)
+		}
+		if entries[i].DisplayPath() != entries[j].DisplayPath() {
+			return entries[i].DisplayPath() < entries[j].DisplayPath()
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries, nil
+}
@@ -0,0 +1,89 @@
+package fileops
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"file-manager/internal/i18n"
+	"file-manager/internal/ignore"
+	"file-manager/internal/sysinfo"
+)
+
+// DirUsage описывает суммарный размер директории вместе с вложенными
+type DirUsage struct {
+	Path  string
+	Size  int64
+	Files int
+	// Depth — глубина директории относительно корня подсчета (0 — сам корень)
+	Depth int
+}
+
+// usageCounter хранит состояние одного подсчета
+type usageCounter struct {
+	maxDepth int
+	rules    *ignore.Matcher
+	// seen — уже учтенные файлы с несколькими жесткими ссылками
+	seen   map[[2]uint64]bool
+	result []DirUsage
+}
+
+// DiskUsage подсчитывает размер файлов в дереве root. Возвращает записи для директорий
+// не глубже maxDepth (-1 — без ограничения) в порядке обхода, корень — последним, как в du.
+// Символические ссылки не разыменовываются, файл с несколькими жесткими ссылками учитывается один раз,
+// недоступные поддиректории пропускаются. rules (может быть nil) исключает записи по правилам ignore-файлов.
+func (f *FileOperator) DiskUsage(root string, maxDepth int, rules *ignore.Matcher) ([]DirUsage, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("du_error"), root, err)
+	}
+	if !info.IsDir() {
+		return []DirUsage{{Path: root, Size: info.Size(), Files: 1}}, nil
+	}
+	counter := &usageCounter{maxDepth: maxDepth, rules: rules, seen: make(map[[2]uint64]bool)}
+	if _, err := counter.count(root, 0); err != nil {
+		return nil, fmt.Errorf(i18n.T("du_error"), root, err)
+	}
+	return counter.result, nil
+}
+
+// count подсчитывает размер директории dir и добавляет ее в результат, если она не глубже maxDepth
+func (c *usageCounter) count(dir string, depth int) (DirUsage, error) {
+	usage := DirUsage{Path: dir, Depth: depth}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return usage, err
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if c.rules != nil && c.rules.Ignored(path, entry.IsDir()) {
+			continue
+		}
+		if entry.IsDir() {
+			sub, err := c.count(path, depth+1)
+			if err != nil {
+				continue // Пропускаем директории, к которым нет доступа
+			}
+			usage.Size += sub.Size
+			usage.Files += sub.Files
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if stat, ok := sysinfo.Stat(info); ok && stat.Nlink > 1 {
+			key := [2]uint64{stat.Dev, stat.Ino}
+			if c.seen[key] {
+				continue
+			}
+			c.seen[key] = true
+		}
+		usage.Size += info.Size()
+		usage.Files++
+	}
+	if c.maxDepth < 0 || depth <= c.maxDepth {
+		c.result = append(c.result, usage)
+	}
+	return usage, nil
+}
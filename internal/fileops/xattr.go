@@ -0,0 +1,75 @@
+//go:build linux || darwin || freebsd || netbsd
+
+package fileops
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// copyXattrs переносит расширенные атрибуты source на destination, не переходя по ссылкам.
+// Атрибуты, которые файловая система назначения не поддерживает или которые нельзя
+// установить без прав суперпользователя (trusted.*, security.*), пропускаются.
+func copyXattrs(source, destination string) error {
+	names, err := listXattrs(source)
+	if err != nil {
+		if skippableXattrError(err) {
+			return nil
+		}
+		return err
+	}
+	for _, name := range names {
+		value, err := getXattr(source, name)
+		if err != nil {
+			if skippableXattrError(err) {
+				continue
+			}
+			return err
+		}
+		if err := unix.Lsetxattr(destination, name, value, 0); err != nil && !skippableXattrError(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// listXattrs возвращает имена расширенных атрибутов файла
+func listXattrs(path string) ([]string, error) {
+	size, err := unix.Llistxattr(path, nil)
+	if err != nil || size == 0 {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	size, err = unix.Llistxattr(path, buf)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, name := range strings.Split(string(buf[:size]), "\x00") {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// getXattr возвращает значение расширенного атрибута
+func getXattr(path, name string) ([]byte, error) {
+	size, err := unix.Lgetxattr(path, name, nil)
+	if err != nil || size == 0 {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	size, err = unix.Lgetxattr(path, name, buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:size], nil
+}
+
+// skippableXattrError сообщает, что атрибут нельзя перенести по независящим от пользователя причинам
+func skippableXattrError(err error) bool {
+	return errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EOPNOTSUPP) || errors.Is(err, unix.EPERM)
+}
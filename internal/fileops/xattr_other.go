@@ -0,0 +1,8 @@
+//go:build !(linux || darwin || freebsd || netbsd)
+
+package fileops
+
+// copyXattrs на этой платформе ничего не делает: расширенные атрибуты не поддерживаются
+func copyXattrs(_, _ string) error {
+	return nil
+}
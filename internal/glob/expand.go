@@ -0,0 +1,137 @@
+package glob
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Split делит шаблон пути на начальную часть без специальных символов и остаток с первого
+// сегмента, содержащего шаблон. Для шаблона без специальных символов остаток пуст.
+func Split(pattern string) (string, string) {
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		if !HasMeta(segment) {
+			continue
+		}
+		dir := strings.Join(segments[:i], "/")
+		if dir == "" && strings.HasPrefix(pattern, "/") {
+			dir = "/"
+		}
+		return dir, strings.Join(segments[i:], "/")
+	}
+	return pattern, ""
+}
+
+// Expand возвращает пути внутри dir, подходящие под шаблон pattern с разделителями /.
+// Результаты отсортированы и заданы относительно dir. Как и в оболочке, записи, имя которых
+// начинается с точки, совпадают только с сегментом шаблона, который сам начинается с точки;
+// ** не заходит в символические ссылки на директории. Завершающий / оставляет только директории.
+func Expand(pattern, dir string) ([]string, error) {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	var segments []*segment
+	for _, text := range strings.Split(strings.Trim(pattern, "/"), "/") {
+		if text == "" {
+			continue
+		}
+		seg := &segment{text: text}
+		if text != "**" && HasMeta(text) {
+			p, err := Compile(text)
+			if err != nil {
+				return nil, err
+			}
+			seg.pattern = p
+		}
+		segments = append(segments, seg)
+	}
+
+	e := &expander{dirOnly: dirOnly, seen: make(map[string]bool)}
+	e.expand(dir, "", segments)
+	sort.Strings(e.matches)
+	return e.matches, nil
+}
+
+// segment — один сегмент шаблона пути
+type segment struct {
+	text string
+	// pattern — скомпилированный шаблон сегмента (nil для буквального сегмента и **)
+	pattern *Pattern
+}
+
+// expander хранит состояние одного раскрытия шаблона
+type expander struct {
+	dirOnly bool
+	matches []string
+	seen    map[string]bool
+}
+
+// add добавляет найденный путь, если он подходит по типу и еще не добавлен
+func (e *expander) add(full, rel string) {
+	if rel == "" || e.seen[rel] {
+		return
+	}
+	if e.dirOnly {
+		if info, err := os.Stat(full); err != nil || !info.IsDir() {
+			return
+		}
+	}
+	e.seen[rel] = true
+	e.matches = append(e.matches, rel)
+}
+
+// expand сопоставляет сегменты с содержимым директории full (rel — ее путь относительно начала)
+func (e *expander) expand(full, rel string, segments []*segment) {
+	if len(segments) == 0 {
+		e.add(full, rel)
+		return
+	}
+	seg, rest := segments[0], segments[1:]
+
+	switch {
+	case seg.text == "**":
+		// ** — ноль или больше директорий
+		e.expand(full, rel, rest)
+		entries, err := os.ReadDir(full)
+		if err != nil {
+			return
+		}
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			childFull, childRel := filepath.Join(full, entry.Name()), joinRel(rel, entry.Name())
+			if entry.IsDir() {
+				e.expand(childFull, childRel, segments)
+			} else if len(rest) == 0 {
+				e.add(childFull, childRel)
+			}
+		}
+	case seg.pattern == nil:
+		name := Unescape(seg.text)
+		childFull := filepath.Join(full, name)
+		if _, err := os.Lstat(childFull); err == nil {
+			e.expand(childFull, joinRel(rel, name), rest)
+		}
+	default:
+		entries, err := os.ReadDir(full)
+		if err != nil {
+			return
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if strings.HasPrefix(name, ".") && !strings.HasPrefix(seg.text, ".") || !seg.pattern.Match(name) {
+				continue
+			}
+			e.expand(filepath.Join(full, name), joinRel(rel, name), rest)
+		}
+	}
+}
+
+// joinRel добавляет имя к относительному пути
+func joinRel(rel, name string) string {
+	if rel == "" {
+		return name
+	}
+	return rel + "/" + name
+}
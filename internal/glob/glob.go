@@ -0,0 +1,309 @@
+// Package glob реализует шаблоны имен и путей, общие для поиска, фильтров, архивов и ignore-файлов.
+//
+// Синтаксис:
+//   - * — любая последовательность символов, кроме /
+//   - ? — один символ, кроме /
+//   - [abc], [a-z], [!a-z] или [^a-z] — символ из набора (или не из набора)
+//   - ** — любое число директорий: **/ в начале сегмента, /** в конце шаблона или ** как весь шаблон
+//   - {a,b,c} — одна из альтернатив (альтернативы могут содержать шаблоны и вложенные скобки)
+//   - \x — символ x буквально
+//
+// Непарные [ и { означают сами себя.
+//
+// Шаблон компилируется один раз и затем проверяется без повторного разбора.
+package glob
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"file-manager/internal/i18n"
+)
+
+// Pattern — скомпилированный шаблон
+type Pattern struct {
+	source     string
+	ignoreCase bool
+	// literal — шаблон без специальных символов, сравнивается как строка
+	literal string
+	isLit   bool
+	re      *regexp.Regexp
+	// hasSlash — шаблон описывает путь, а не имя
+	hasSlash bool
+}
+
+// Compile компилирует шаблон с учетом регистра
+func Compile(pattern string) (*Pattern, error) {
+	return compile(pattern, false)
+}
+
+// CompileIgnoreCase компилирует шаблон без учета регистра
+func CompileIgnoreCase(pattern string) (*Pattern, error) {
+	return compile(pattern, true)
+}
+
+// MustCompile компилирует шаблон и паникует при ошибке; предназначена для шаблонов-констант
+func MustCompile(pattern string) *Pattern {
+	p, err := Compile(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Match проверяет строку на соответствие шаблону без предварительной компиляции
+func Match(pattern, s string) (bool, error) {
+	p, err := Compile(pattern)
+	if err != nil {
+		return false, err
+	}
+	return p.Match(s), nil
+}
+
+func compile(pattern string, ignoreCase bool) (*Pattern, error) {
+	p := &Pattern{
+		source:     pattern,
+		ignoreCase: ignoreCase,
+		hasSlash:   strings.Contains(pattern, "/"),
+	}
+	if !HasMeta(pattern) {
+		p.isLit = true
+		p.literal = Unescape(pattern)
+		return p, nil
+	}
+
+	t := &translator{pattern: pattern}
+	expr, err := t.translate(0, false)
+	if err != nil {
+		return nil, err
+	}
+	prefix := "^"
+	if ignoreCase {
+		prefix = "(?i)^"
+	}
+	re, err := regexp.Compile(prefix + expr + "$")
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("glob_bad_pattern"), pattern, err)
+	}
+	p.re = re
+	return p, nil
+}
+
+// String возвращает исходный текст шаблона
+func (p *Pattern) String() string {
+	return p.source
+}
+
+// Match проверяет строку целиком
+func (p *Pattern) Match(s string) bool {
+	if p.isLit {
+		if p.ignoreCase {
+			return strings.EqualFold(p.literal, s)
+		}
+		return p.literal == s
+	}
+	return p.re.MatchString(s)
+}
+
+// MatchPath проверяет путь с разделителями /: шаблон без / сравнивается с последним
+// элементом пути, шаблон с / — со всем путем
+func (p *Pattern) MatchPath(name string) bool {
+	if !p.hasSlash {
+		name = path.Base(name)
+	}
+	return p.Match(name)
+}
+
+// HasMeta проверяет, содержит ли строка специальные символы шаблона (включая экранирование)
+func HasMeta(s string) bool {
+	return strings.ContainsAny(s, `*?[{\`)
+}
+
+// QuoteMeta экранирует специальные символы, чтобы строка совпадала только сама с собой
+func QuoteMeta(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(`*?[]{},\`, s[i]) >= 0 {
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+// Unescape снимает экранирование \x
+func Unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+// translator переводит шаблон в регулярное выражение
+type translator struct {
+	pattern string
+	pos     int
+	// unclosed — позиции {, для которых не нашлась закрывающая скобка
+	unclosed map[int]bool
+}
+
+// errorf формирует ошибку разбора шаблона
+func (t *translator) errorf(key string) error {
+	return fmt.Errorf(i18n.T("glob_bad_pattern"), t.pattern, i18n.T(key))
+}
+
+// translate переводит шаблон с позиции start; inBraces — разбор альтернативы внутри {},
+// тогда разбор останавливается на , или } верхнего уровня
+func (t *translator) translate(start int, inBraces bool) (string, error) {
+	var sb strings.Builder
+	s := t.pattern
+	t.pos = start
+	for t.pos < len(s) {
+		c := s[t.pos]
+		// Начало сегмента: начало шаблона, после / или в начале альтернативы
+		segmentStart := t.pos == 0 || s[t.pos-1] == '/' || inBraces && (s[t.pos-1] == '{' || s[t.pos-1] == ',')
+		switch {
+		case inBraces && (c == ',' || c == '}'):
+			return sb.String(), nil
+		case c == '\\':
+			if t.pos+1 >= len(s) {
+				return "", t.errorf("glob_trailing_escape")
+			}
+			sb.WriteString(regexp.QuoteMeta(s[t.pos+1 : t.pos+2]))
+			t.pos += 2
+		case strings.HasPrefix(s[t.pos:], "**/") && segmentStart:
+			// **/ — ноль или больше директорий
+			sb.WriteString("(?:.*/)?")
+			t.pos += 3
+		case strings.HasPrefix(s[t.pos:], "**") && segmentStart && t.atSegmentEnd(t.pos+2, inBraces):
+			// ** как весь сегмент в конце — любое содержимое, включая вложенные директории
+			sb.WriteString(".*")
+			t.pos += 2
+		case c == '*':
+			sb.WriteString("[^/]*")
+			for t.pos < len(s) && s[t.pos] == '*' {
+				t.pos++
+			}
+		case c == '?':
+			sb.WriteString("[^/]")
+			t.pos++
+		case c == '[':
+			class, err := t.class()
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(class)
+		case c == '{':
+			alternatives, err := t.braces()
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(alternatives)
+		default:
+			sb.WriteString(regexp.QuoteMeta(s[t.pos : t.pos+1]))
+			t.pos++
+		}
+	}
+	return sb.String(), nil
+}
+
+// atSegmentEnd проверяет, заканчивается ли сегмент на позиции i
+func (t *translator) atSegmentEnd(i int, inBraces bool) bool {
+	if i >= len(t.pattern) {
+		return true
+	}
+	return inBraces && (t.pattern[i] == ',' || t.pattern[i] == '}')
+}
+
+// class переводит набор символов [...]; набор не совпадает с /.
+// Скобка без закрывающей ] означает сама себя.
+func (t *translator) class() (string, error) {
+	s := t.pattern
+	i := t.pos + 1
+	var sb strings.Builder
+	sb.WriteByte('[')
+	negate := i < len(s) && (s[i] == '!' || s[i] == '^')
+	if negate {
+		sb.WriteByte('^')
+		i++
+	}
+	first := true
+	for ; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == ']' && !first:
+			if negate {
+				sb.WriteByte('/')
+			}
+			sb.WriteByte(']')
+			t.pos = i + 1
+			return sb.String(), nil
+		case c == '\\':
+			if i+1 >= len(s) {
+				// Набор не закрыт: \ в конце шаблона разберет translate
+				break
+			}
+			_, size := utf8.DecodeRuneInString(s[i+1:])
+			sb.WriteString(regexp.QuoteMeta(s[i+1 : i+1+size]))
+			i += size
+		case c == '-' && (first || i+1 < len(s) && s[i+1] == ']'):
+			// Дефис в начале или в конце набора — обычный символ
+			sb.WriteString(`\-`)
+		default:
+			// Символ набора берется целиком, чтобы не разбить многобайтовый символ
+			_, size := utf8.DecodeRuneInString(s[i:])
+			sb.WriteString(regexp.QuoteMeta(s[i : i+size]))
+			i += size - 1
+		}
+		first = false
+	}
+	t.pos++
+	return regexp.QuoteMeta("["), nil
+}
+
+// braces переводит альтернативы {a,b}; скобки без запятой и { без закрывающей }
+// означают сами себя
+func (t *translator) braces() (string, error) {
+	open := t.pos
+	if t.unclosed[open] {
+		t.pos++
+		return regexp.QuoteMeta("{"), nil
+	}
+	var alternatives []string
+	next := open + 1
+	for {
+		alt, err := t.translate(next, true)
+		if err != nil {
+			return "", err
+		}
+		if t.pos >= len(t.pattern) {
+			// Запоминаем скобку, чтобы при повторном разборе не искать пару заново
+			if t.unclosed == nil {
+				t.unclosed = make(map[int]bool)
+			}
+			t.unclosed[open] = true
+			t.pos = open + 1
+			return regexp.QuoteMeta("{"), nil
+		}
+		alternatives = append(alternatives, alt)
+		if t.pattern[t.pos] == '}' {
+			t.pos++
+			break
+		}
+		next = t.pos + 1
+	}
+	if len(alternatives) == 1 {
+		return regexp.QuoteMeta("{") + alternatives[0] + regexp.QuoteMeta("}"), nil
+	}
+	return "(?:" + strings.Join(alternatives, "|") + ")", nil
+}
@@ -0,0 +1,164 @@
+package glob
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		match   bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "main.go.bak", false},
+		{"*.go", "src/main.go", false},
+		{"file?.txt", "file1.txt", true},
+		{"file?.txt", "file10.txt", false},
+		{"[a-c]*", "beta", true},
+		{"[!a-c]*", "beta", false},
+		{"[^a-c]*", "delta", true},
+		{"[]]", "]", true},
+		{"[-a]", "-", true},
+		{"[ая]", "я", true},
+		{"*.{go,md}", "README.md", true},
+		{"*.{go,md}", "main.rs", false},
+		{"{src,lib}/**/*.go", "lib/a/b/c.go", true},
+		{"{a,b{c,d}}", "bd", true},
+		{"file{1}.txt", "file{1}.txt", true},
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "a/b/main.go", true},
+		{"src/**", "src/a/b", true},
+		{"src/**", "src", false},
+		{"**", "a/b/c", true},
+		{"a**b", "axxb", true},
+		{"a**b", "a/b", false},
+		{`\*.go`, "*.go", true},
+		{`\*.go`, "main.go", false},
+		{"plain.txt", "plain.txt", true},
+		{"plain.txt", "Plain.txt", false},
+		{"[abc", "[abc", true},
+		{"[a-", "[a-", true},
+		{"a[b*", "a[bcd", true},
+		{"*.{go", "main.{go", true},
+		{"{a,b", "{a,b", true},
+		{"{a,{b,c}", "{a,c", true},
+		{"{{{{{{{{{{{{{{{{{{{{{{{{x", "{{{{{{{{{{{{{{{{{{{{{{{{x", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			got, err := Match(tt.pattern, tt.name)
+			if err != nil {
+				t.Fatalf("ошибка компиляции %q: %v", tt.pattern, err)
+			}
+			if got != tt.match {
+				t.Errorf("Match(%q, %q) = %v, ожидалось %v", tt.pattern, tt.name, got, tt.match)
+			}
+		})
+	}
+}
+
+func TestCompileIgnoreCase(t *testing.T) {
+	for _, pattern := range []string{"readme.MD", "*.md", "READ{ME,THIS}.*"} {
+		p, err := CompileIgnoreCase(pattern)
+		if err != nil {
+			t.Fatalf("ошибка компиляции %q: %v", pattern, err)
+		}
+		if !p.Match("README.md") {
+			t.Errorf("%q без учета регистра должен совпадать с README.md", pattern)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, pattern := range []string{`abc\`, `[abc\`, `{a,b\`} {
+		if _, err := Compile(pattern); err == nil {
+			t.Errorf("ожидалась ошибка для шаблона %q", pattern)
+		}
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	name := MustCompile("*.go")
+	if !name.MatchPath("a/b/main.go") {
+		t.Error("шаблон без / должен сравниваться с именем")
+	}
+	path := MustCompile("a/*.go")
+	if path.MatchPath("x/a/main.go") || !path.MatchPath("a/main.go") {
+		t.Error("шаблон с / должен сравниваться со всем путем")
+	}
+}
+
+func TestQuoteMeta(t *testing.T) {
+	name := "weird[1]{a,b}*?.txt"
+	p, err := Compile(QuoteMeta(name))
+	if err != nil {
+		t.Fatalf("ошибка компиляции: %v", err)
+	}
+	if !p.Match(name) || p.Match("weird1a.txt") {
+		t.Error("экранированный шаблон должен совпадать только с исходной строкой")
+	}
+	if Unescape(QuoteMeta(name)) != name {
+		t.Error("Unescape должен снимать экранирование QuoteMeta")
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct{ pattern, dir, rest string }{
+		{"*.go", "", "*.go"},
+		{"src/pkg/*.go", "src/pkg", "*.go"},
+		{"/tmp/*/x", "/tmp", "*/x"},
+		{"/*", "/", "*"},
+		{"plain/path", "plain/path", ""},
+	}
+	for _, tt := range tests {
+		dir, rest := Split(tt.pattern)
+		if dir != tt.dir || rest != tt.rest {
+			t.Errorf("Split(%q) = %q, %q; ожидалось %q, %q", tt.pattern, dir, rest, tt.dir, tt.rest)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"a.go", "b.md", ".hidden.go", "src/c.go", "src/deep/d.go", "src/.git/e.go"} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("не удалось создать директорию: %v", err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("не удалось создать файл %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"*.go", "a.go"},
+		{".*.go", ".hidden.go"},
+		{"*.{go,md}", "a.go,b.md"},
+		{"**/*.go", "a.go,src/c.go,src/deep/d.go"},
+		{"src/*/", "src/deep"},
+		{"s*/d*/*.go", "src/deep/d.go"},
+		{"*.rs", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			matches, err := Expand(tt.pattern, root)
+			if err != nil {
+				t.Fatalf("ошибка раскрытия: %v", err)
+			}
+			if got := strings.Join(matches, ","); got != tt.want {
+				t.Errorf("Expand(%q) = %s, ожидалось %s", tt.pattern, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Expand(`*\`, root); err == nil {
+		t.Error("ожидалась ошибка для некорректного шаблона")
+	}
+}
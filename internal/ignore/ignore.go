@@ -0,0 +1,200 @@
+// Package ignore реализует правила игнорирования в формате .gitignore для обхода деревьев директорий.
+// Шаблоны правил разбираются пакетом glob.
+package ignore
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"file-manager/internal/glob"
+)
+
+// FileNames — имена ignore-файлов в порядке возрастания приоритета:
+// правила из .fmignore переопределяют .ignore, а те — .gitignore
+var FileNames = []string{".gitignore", ".ignore", ".fmignore"}
+
+// GlobalFile возвращает путь к глобальному ignore-файлу (~/.filemanager/ignore)
+func GlobalFile() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".filemanager", "ignore")
+}
+
+// rule — одно правило ignore-файла
+type rule struct {
+	// base — директория, относительно которой задано правило
+	base    string
+	pattern *glob.Pattern
+	// negate — правило с префиксом !, возвращающее ранее исключенные записи
+	negate bool
+	// dirOnly — правило с завершающим /, применяется только к директориям
+	dirOnly bool
+	// anchored — шаблон содержит /, поэтому сравнивается с путем относительно base, а не с именем
+	anchored bool
+}
+
+// matches проверяет, подходит ли запись path под правило
+func (r *rule) matches(path string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if !r.anchored {
+		return r.pattern.Match(filepath.Base(path))
+	}
+	rel, err := filepath.Rel(r.base, path)
+	if err != nil {
+		return false
+	}
+	return r.pattern.Match(filepath.ToSlash(rel))
+}
+
+// Matcher проверяет пути внутри дерева root по глобальным правилам и ignore-файлам,
+// найденным в директориях дерева. Ignore-файлы читаются при первом обращении к директории.
+type Matcher struct {
+	root    string
+	global  []rule
+	dirs    map[string][]rule
+	ignored map[string]bool
+}
+
+// NewMatcher создает Matcher для дерева root с глобальным файлом ~/.filemanager/ignore
+func NewMatcher(root string) *Matcher {
+	return NewMatcherWithGlobal(root, GlobalFile())
+}
+
+// NewMatcherWithGlobal создает Matcher для дерева root с указанным глобальным ignore-файлом.
+// Правила глобального файла задаются относительно root; отсутствующий файл не является ошибкой.
+func NewMatcherWithGlobal(root, globalFile string) *Matcher {
+	root = filepath.Clean(root)
+	m := &Matcher{
+		root:    root,
+		dirs:    make(map[string][]rule),
+		ignored: make(map[string]bool),
+	}
+	if globalFile != "" {
+		m.global = readRules(globalFile, root)
+	}
+	return m
+}
+
+// Ignored проверяет, исключена ли запись path правилами. Как и в git, запись внутри
+// исключенной директории исключена всегда, даже если для нее есть правило с !.
+// Директории .git исключаются всегда. Пути вне дерева root не исключаются.
+func (m *Matcher) Ignored(path string, isDir bool) bool {
+	path = filepath.Clean(path)
+	rel, err := filepath.Rel(m.root, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	if isDir {
+		if ignored, ok := m.ignored[path]; ok {
+			return ignored
+		}
+	}
+
+	parent := filepath.Dir(path)
+	ignored := parent != m.root && m.Ignored(parent, true) || m.match(path, isDir)
+	if isDir {
+		m.ignored[path] = ignored
+	}
+	return ignored
+}
+
+// match применяет правила к записи без учета родительских директорий; побеждает последнее подошедшее правило
+func (m *Matcher) match(path string, isDir bool) bool {
+	if isDir && filepath.Base(path) == ".git" {
+		return true
+	}
+	ignored := false
+	apply := func(rules []rule) {
+		for i := range rules {
+			if rules[i].matches(path, isDir) {
+				ignored = !rules[i].negate
+			}
+		}
+	}
+	apply(m.global)
+	// Правила ближайших к записи директорий применяются последними
+	var chain []string
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		chain = append(chain, dir)
+		if dir == m.root || dir == filepath.Dir(dir) {
+			break
+		}
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		apply(m.dirRules(chain[i]))
+	}
+	return ignored
+}
+
+// dirRules возвращает правила ignore-файлов директории dir
+func (m *Matcher) dirRules(dir string) []rule {
+	if rules, ok := m.dirs[dir]; ok {
+		return rules
+	}
+	var rules []rule
+	for _, name := range FileNames {
+		rules = append(rules, readRules(filepath.Join(dir, name), dir)...)
+	}
+	m.dirs[dir] = rules
+	return rules
+}
+
+// readRules читает правила из файла; недоступный файл не содержит правил
+func readRules(path, base string) []rule {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = file.Close() }()
+
+	var rules []rule
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if r, ok := parseRule(scanner.Text(), base); ok {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// parseRule разбирает строку ignore-файла. Пустые строки, комментарии и некорректные шаблоны пропускаются.
+func parseRule(line, base string) (rule, bool) {
+	line = strings.TrimRight(line, "\r")
+	// Завершающие пробелы игнорируются, если не экранированы
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule{}, false
+	}
+
+	r := rule{base: base}
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		r.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return rule{}, false
+	}
+
+	pattern, err := glob.Compile(line)
+	if err != nil {
+		return rule{}, false
+	}
+	r.pattern = pattern
+	return r, true
+}
@@ -0,0 +1,158 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTree создает файлы с указанным содержимым; пути с завершающим / создаются как директории
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		fullPath := filepath.Join(root, path)
+		if path[len(path)-1] == '/' {
+			if err := os.MkdirAll(fullPath, 0755); err != nil {
+				t.Fatalf("не удалось создать директорию %s: %v", fullPath, err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("не удалось создать директорию для %s: %v", fullPath, err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("не удалось создать файл %s: %v", fullPath, err)
+		}
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".gitignore": "# комментарий\n" +
+			"*.log\n" +
+			"!keep.log\n" +
+			"/build\n" +
+			"node_modules/\n" +
+			"docs/**/*.tmp\n" +
+			"cache/**\n" +
+			"\\#literal\n",
+		"src/.fmignore":          "generated.go\n!*.log\n",
+		"src/.ignore":            "*.bak\n",
+		"src/app.go":             "",
+		"src/generated.go":       "",
+		"src/debug.log":          "",
+		"src/old.bak":            "",
+		"src/build/out":          "",
+		"build/out":              "",
+		"app.log":                "",
+		"keep.log":               "",
+		"web/node_modules/x.js":  "",
+		"web/node_modules.txt":   "",
+		"docs/a/b/c.tmp":         "",
+		"docs/c.tmp":             "",
+		"cache/data/blob":        "",
+		"#literal":               "",
+		"node_modules/dep/!x.js": "",
+		".git/":                  "",
+	})
+	m := NewMatcherWithGlobal(root, "")
+
+	tests := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"app.log", false, true},
+		{"keep.log", false, false},
+		{"build", true, true},
+		{"build/out", false, true},
+		{"src/build", true, false},
+		{"web/node_modules", true, true},
+		{"web/node_modules/x.js", false, true},
+		{"web/node_modules.txt", false, false},
+		{"docs/a/b/c.tmp", false, true},
+		{"docs/c.tmp", false, true},
+		{"cache", true, false},
+		{"cache/data/blob", false, true},
+		{"#literal", false, true},
+		{"src/app.go", false, false},
+		{"src/generated.go", false, true},
+		{"src/old.bak", false, true},
+		{"src/debug.log", false, false},
+		{".git", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := m.Ignored(filepath.Join(root, tt.path), tt.isDir); got != tt.ignored {
+				t.Errorf("Ignored(%s) = %v, ожидалось %v", tt.path, got, tt.ignored)
+			}
+		})
+	}
+
+	t.Run("Пути вне дерева не исключаются", func(t *testing.T) {
+		if m.Ignored(filepath.Join(filepath.Dir(root), "app.log"), false) || m.Ignored(root, true) {
+			t.Error("пути вне дерева и сам корень не должны исключаться")
+		}
+	})
+}
+
+func TestGlobalFile(t *testing.T) {
+	root := t.TempDir()
+	global := filepath.Join(t.TempDir(), "ignore")
+	writeTree(t, root, map[string]string{
+		".fmignore": "!important.swp\n",
+		"a.swp":     "",
+	})
+	if err := os.WriteFile(global, []byte("*.swp\n/vendor/\n"), 0644); err != nil {
+		t.Fatalf("не удалось создать глобальный файл: %v", err)
+	}
+	m := NewMatcherWithGlobal(root, global)
+
+	if !m.Ignored(filepath.Join(root, "a.swp"), false) {
+		t.Error("a.swp должен исключаться глобальным правилом")
+	}
+	if m.Ignored(filepath.Join(root, "important.swp"), false) {
+		t.Error("правила дерева должны переопределять глобальные")
+	}
+	if !m.Ignored(filepath.Join(root, "vendor"), true) || m.Ignored(filepath.Join(root, "lib", "vendor"), true) {
+		t.Error("глобальное правило /vendor/ должно привязываться к корню дерева")
+	}
+	if NewMatcherWithGlobal(root, filepath.Join(root, "missing")).Ignored(filepath.Join(root, "a.swp"), false) {
+		t.Error("отсутствующий глобальный файл не должен добавлять правил")
+	}
+}
+
+func TestRulePatterns(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "dir/main.go", false},
+		{"a/**/b", "a/b", true},
+		{"a/**/b", "a/x/y/b", true},
+		{"**/b", "x/b", true},
+		{"a/**", "a/x/y", true},
+		{"a/**", "a", false},
+		{"file?.txt", "file1.txt", true},
+		{"file?.txt", "file/.txt", false},
+		{"[!a]bc", "xbc", true},
+		{"[!a]bc", "abc", false},
+		{"\\[abc", "[abc", true},
+		{"*.{js,ts}", "app.ts", true},
+		{"a.b", "axb", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			r, ok := parseRule(tt.pattern, "")
+			if !ok {
+				t.Fatalf("шаблон %q не разобран", tt.pattern)
+			}
+			if got := r.pattern.Match(tt.path); got != tt.match {
+				t.Errorf("%q ~ %q = %v, ожидалось %v", tt.pattern, tt.path, got, tt.match)
+			}
+		})
+	}
+}
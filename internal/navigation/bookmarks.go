@@ -7,12 +7,28 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
 // Bookmark представляет одну закладку
 type Bookmark struct {
-	Name string `json:"name"`
-	Path string `json:"path"`
+	Name        string    `json:"name"`
+	Path        string    `json:"path"`
+	Description string    `json:"description,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	CreatedAt   time.Time `json:"created_at,omitempty"`
+	UseCount    int       `json:"use_count,omitempty"`
+}
+
+// HasTag проверяет, отмечена ли закладка указанным тегом
+func (b *Bookmark) HasTag(tag string) bool {
+	for _, t := range b.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
 }
 
 // BookmarkManager управляет закладками директорий
@@ -57,6 +73,10 @@ func NewBookmarkManager() (*BookmarkManager, error) {
 
 // AddBookmark добавляет новую закладку
 func (bm *BookmarkManager) AddBookmark(name, path string) error {
+	if err := ValidateBookmarkName(name); err != nil {
+		return err
+	}
+
 	// Проверяем, существует ли директория
 	info, err := os.Stat(path)
 	if err != nil {
@@ -76,8 +96,9 @@ func (bm *BookmarkManager) AddBookmark(name, path string) error {
 
 	// Добавляем закладку
 	bm.Bookmarks = append(bm.Bookmarks, Bookmark{
-		Name: name,
-		Path: path,
+		Name:      name,
+		Path:      path,
+		CreatedAt: time.Now(),
 	})
 
 	// Сохраняем изменения
@@ -121,6 +142,139 @@ func (bm *BookmarkManager) ListBookmarks() []Bookmark {
 	return bm.Bookmarks
 }
 
+// ListBookmarksByTag возвращает закладки, отмеченные указанным тегом
+func (bm *BookmarkManager) ListBookmarksByTag(tag string) []Bookmark {
+	var result []Bookmark
+	for _, bookmark := range bm.Bookmarks {
+		if bookmark.HasTag(tag) {
+			result = append(result, bookmark)
+		}
+	}
+	return result
+}
+
+// RenameBookmark переименовывает закладку
+func (bm *BookmarkManager) RenameBookmark(oldName, newName string) error {
+	if err := ValidateBookmarkName(newName); err != nil {
+		return err
+	}
+	if oldName != newName && bm.find(newName) != nil {
+		return fmt.Errorf(i18n.T("bm_exists"), newName)
+	}
+	bookmark := bm.find(oldName)
+	if bookmark == nil {
+		return fmt.Errorf(i18n.T("bm_not_found"), oldName)
+	}
+	bookmark.Name = newName
+	return bm.SaveBookmarks()
+}
+
+// SetDescription задает описание закладки
+func (bm *BookmarkManager) SetDescription(name, description string) error {
+	bookmark := bm.find(name)
+	if bookmark == nil {
+		return fmt.Errorf(i18n.T("bm_not_found"), name)
+	}
+	bookmark.Description = description
+	return bm.SaveBookmarks()
+}
+
+// TagBookmark добавляет закладке теги
+func (bm *BookmarkManager) TagBookmark(name string, tags ...string) error {
+	bookmark := bm.find(name)
+	if bookmark == nil {
+		return fmt.Errorf(i18n.T("bm_not_found"), name)
+	}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !bookmark.HasTag(tag) {
+			bookmark.Tags = append(bookmark.Tags, tag)
+		}
+	}
+	return bm.SaveBookmarks()
+}
+
+// UntagBookmark удаляет у закладки теги
+func (bm *BookmarkManager) UntagBookmark(name string, tags ...string) error {
+	bookmark := bm.find(name)
+	if bookmark == nil {
+		return fmt.Errorf(i18n.T("bm_not_found"), name)
+	}
+	var kept []string
+	for _, existing := range bookmark.Tags {
+		remove := false
+		for _, tag := range tags {
+			if strings.EqualFold(existing, tag) {
+				remove = true
+				break
+			}
+		}
+		if !remove {
+			kept = append(kept, existing)
+		}
+	}
+	bookmark.Tags = kept
+	return bm.SaveBookmarks()
+}
+
+// UpdateBookmarkPath изменяет путь закладки на существующую директорию
+func (bm *BookmarkManager) UpdateBookmarkPath(name, path string) error {
+	bookmark := bm.find(name)
+	if bookmark == nil {
+		return fmt.Errorf(i18n.T("bm_not_found"), name)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf(i18n.T("bm_path"), err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf(i18n.T("bm_dir_not"), path)
+	}
+	bookmark.Path = path
+	return bm.SaveBookmarks()
+}
+
+// RecordUse увеличивает счетчик использований закладки
+func (bm *BookmarkManager) RecordUse(name string) error {
+	bookmark := bm.find(name)
+	if bookmark == nil {
+		return fmt.Errorf(i18n.T("bm_not_found"), name)
+	}
+	bookmark.UseCount++
+	return bm.SaveBookmarks()
+}
+
+// CheckBookmarks возвращает закладки, директории которых больше не существуют
+func (bm *BookmarkManager) CheckBookmarks() []Bookmark {
+	var broken []Bookmark
+	for _, bookmark := range bm.Bookmarks {
+		info, err := os.Stat(bookmark.Path)
+		if err != nil || !info.IsDir() {
+			broken = append(broken, bookmark)
+		}
+	}
+	return broken
+}
+
+// ValidateBookmarkName проверяет, что имя закладки можно использовать в ссылках вида @имя/путь
+func ValidateBookmarkName(name string) error {
+	if name == "" || strings.ContainsAny(name, "/\\ \t") || strings.HasPrefix(name, "@") ||
+		strings.HasPrefix(name, "~") || strings.HasPrefix(name, "-") {
+		return fmt.Errorf(i18n.T("bm_invalid_name"), name)
+	}
+	return nil
+}
+
+// find возвращает указатель на закладку по имени или nil
+func (bm *BookmarkManager) find(name string) *Bookmark {
+	for i := range bm.Bookmarks {
+		if bm.Bookmarks[i].Name == name {
+			return &bm.Bookmarks[i]
+		}
+	}
+	return nil
+}
+
 // SaveBookmarks сохраняет закладки в файл
 func (bm *BookmarkManager) SaveBookmarks() error {
 	// Сериализуем закладки в JSON
@@ -0,0 +1,190 @@
+package navigation
+
+import (
+	"bufio"
+	"encoding/json"
+	"file-manager/internal/i18n"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultGTKBookmarksFile возвращает путь к файлу закладок GTK (~/.config/gtk-3.0/bookmarks)
+func DefaultGTKBookmarksFile() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf(i18n.T("bm_home"), err)
+	}
+	return filepath.Join(configDir, "gtk-3.0", "bookmarks"), nil
+}
+
+// ExportJSON сохраняет закладки в JSON-файл в формате bookmarks.json
+func (bm *BookmarkManager) ExportJSON(path string) error {
+	data, err := json.MarshalIndent(bm.Bookmarks, "", "  ")
+	if err != nil {
+		return fmt.Errorf(i18n.T("bm_marshal"), err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf(i18n.T("bm_write"), err)
+	}
+	return nil
+}
+
+// ImportJSON добавляет закладки из JSON-файла и возвращает количество добавленных.
+// Закладки на уже известные пути пропускаются, конфликтующие имена получают суффикс.
+func (bm *BookmarkManager) ImportJSON(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf(i18n.T("bm_read"), err)
+	}
+	var imported []Bookmark
+	if err := json.Unmarshal(data, &imported); err != nil {
+		return 0, fmt.Errorf(i18n.T("bm_unmarshal"), err)
+	}
+	return bm.merge(imported)
+}
+
+// ExportGTK добавляет закладки в файл закладок GTK, сохраняя уже имеющиеся в нем записи
+func (bm *BookmarkManager) ExportGTK(path string) error {
+	lines, err := readGTKLines(path)
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]bool)
+	for _, line := range lines {
+		if p, _, ok := parseGTKLine(line); ok {
+			known[filepath.Clean(p)] = true
+		}
+	}
+
+	for _, bookmark := range bm.Bookmarks {
+		if known[filepath.Clean(bookmark.Path)] {
+			continue
+		}
+		u := url.URL{Scheme: "file", Path: filepath.ToSlash(bookmark.Path)}
+		lines = append(lines, u.String()+" "+bookmark.Name)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf(i18n.T("bm_dir"), err)
+	}
+	data := strings.Join(lines, "\n")
+	if data != "" {
+		data += "\n"
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		return fmt.Errorf(i18n.T("bm_write"), err)
+	}
+	return nil
+}
+
+// ImportGTK добавляет закладки из файла закладок GTK и возвращает количество добавленных.
+// Нелокальные адреса (sftp://, smb:// и т.п.) пропускаются.
+func (bm *BookmarkManager) ImportGTK(path string) (int, error) {
+	lines, err := readGTKLines(path)
+	if err != nil {
+		return 0, err
+	}
+	var imported []Bookmark
+	for _, line := range lines {
+		p, label, ok := parseGTKLine(line)
+		if !ok {
+			continue
+		}
+		if label == "" {
+			label = filepath.Base(p)
+		}
+		imported = append(imported, Bookmark{Name: label, Path: p})
+	}
+	return bm.merge(imported)
+}
+
+// merge добавляет закладки, пропуская уже известные пути
+func (bm *BookmarkManager) merge(imported []Bookmark) (int, error) {
+	known := make(map[string]bool)
+	for _, bookmark := range bm.Bookmarks {
+		known[filepath.Clean(bookmark.Path)] = true
+	}
+
+	added := 0
+	for _, bookmark := range imported {
+		if bookmark.Path == "" || known[filepath.Clean(bookmark.Path)] {
+			continue
+		}
+		bookmark.Name = bm.uniqueName(sanitizeBookmarkName(bookmark.Name))
+		if bookmark.CreatedAt.IsZero() {
+			bookmark.CreatedAt = time.Now()
+		}
+		bm.Bookmarks = append(bm.Bookmarks, bookmark)
+		known[filepath.Clean(bookmark.Path)] = true
+		added++
+	}
+
+	if added == 0 {
+		return 0, nil
+	}
+	return added, bm.SaveBookmarks()
+}
+
+// uniqueName добавляет к имени числовой суффикс, если закладка с таким именем уже есть
+func (bm *BookmarkManager) uniqueName(name string) string {
+	candidate := name
+	for i := 2; bm.find(candidate) != nil; i++ {
+		candidate = fmt.Sprintf("%s-%d", name, i)
+	}
+	return candidate
+}
+
+// sanitizeBookmarkName заменяет недопустимые символы в имени закладки
+func sanitizeBookmarkName(name string) string {
+	name = strings.TrimLeft(strings.TrimSpace(name), "@~-")
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ' ', '\t':
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "" {
+		return "bookmark"
+	}
+	return name
+}
+
+// readGTKLines читает непустые строки файла закладок GTK (отсутствующий файл считается пустым)
+func readGTKLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("bm_read"), err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf(i18n.T("bm_read"), err)
+	}
+	return lines, nil
+}
+
+// parseGTKLine разбирает строку вида "file:///путь Метка" и возвращает локальный путь и метку
+func parseGTKLine(line string) (string, string, bool) {
+	uri, label, _ := strings.Cut(line, " ")
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" || (u.Host != "" && u.Host != "localhost") {
+		return "", "", false
+	}
+	return filepath.FromSlash(u.Path), strings.TrimSpace(label), true
+}
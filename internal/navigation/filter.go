@@ -1,8 +1,11 @@
 package navigation
 
 import (
+	"file-manager/internal/glob"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -10,23 +13,54 @@ import (
 // FilterOptions содержит опции для фильтрации файлов
 type FilterOptions struct {
 	// Фильтр по расширению
-	Extensions []string
+	Extensions []string `json:"extensions,omitempty"`
 
-	// Фильтр по имени (поддерживает шаблоны * и ?)
-	NamePattern string
+	// Фильтр по имени (шаблон пакета glob: *, ?, [...], {a,b}) и сравнение без учета регистра
+	NamePattern    string `json:"name_pattern,omitempty"`
+	NameIgnoreCase bool   `json:"name_ignore_case,omitempty"`
 
 	// Фильтр по размеру
-	MinSize int64
-	MaxSize int64
+	MinSize int64 `json:"min_size"`
+	MaxSize int64 `json:"max_size"`
 
 	// Фильтр по дате изменения
-	ModifiedAfter  time.Time
-	ModifiedBefore time.Time
+	ModifiedAfter  time.Time `json:"modified_after,omitempty"`
+	ModifiedBefore time.Time `json:"modified_before,omitempty"`
 
 	// Тип файла
-	ShowDirs   bool
-	ShowFiles  bool
-	ShowHidden bool
+	ShowDirs   bool `json:"show_dirs"`
+	ShowFiles  bool `json:"show_files"`
+	ShowHidden bool `json:"show_hidden"`
+
+	// Права доступа: исполняемый, доступный на запись всем, setuid, setgid
+	Executable    bool `json:"executable,omitempty"`
+	WorldWritable bool `json:"world_writable,omitempty"`
+	Setuid        bool `json:"setuid,omitempty"`
+	Setgid        bool `json:"setgid,omitempty"`
+
+	// Владелец и группа (имя или числовой идентификатор)
+	Owner string `json:"owner,omitempty"`
+	Group string `json:"group,omitempty"`
+
+	// Символические ссылки: любые или только битые
+	Symlink       bool `json:"symlink,omitempty"`
+	BrokenSymlink bool `json:"broken_symlink,omitempty"`
+
+	// Пустой файл или пустая директория
+	Empty bool `json:"empty,omitempty"`
+
+	// Изменен позже указанного файла
+	NewerThan string `json:"newer_than,omitempty"`
+
+	// Число жестких ссылок (0 — граница не задана)
+	MinLinks int64 `json:"min_links,omitempty"`
+	MaxLinks int64 `json:"max_links,omitempty"`
+
+	// Выражение фильтра (см. ParseQuery), применяется вместе с остальными опциями
+	Expression string `json:"expression,omitempty"`
+
+	// Разобранное выражение, кэшируется при первом применении фильтра
+	query *Query
 }
 
 // NewFilterOptions создает новый экземпляр FilterOptions с значениями по умолчанию
@@ -42,106 +76,250 @@ func NewFilterOptions() *FilterOptions {
 	}
 }
 
-// Filter фильтрует список записей директории согласно опциям фильтрации
-func Filter(entries []os.DirEntry, _ string, options *FilterOptions) ([]os.DirEntry, error) {
-	var result []os.DirEntry
+// Clone возвращает независимую копию опций фильтрации
+func (options *FilterOptions) Clone() *FilterOptions {
+	clone := *options
+	clone.Extensions = append([]string{}, options.Extensions...)
+	clone.query = nil
+	return &clone
+}
 
-	for _, entry := range entries {
-		// Проверка скрытых файлов
-		if !options.ShowHidden && isHidden(entry.Name()) {
-			continue
-		}
+// IsActive проверяет, отличается ли фильтр от фильтра по умолчанию,
+// то есть может ли он скрыть или добавить записи в выводе ls
+func (options *FilterOptions) IsActive() bool {
+	return len(options.Extensions) > 0 || options.NamePattern != "" ||
+		options.MinSize >= 0 || options.MaxSize >= 0 ||
+		!options.ModifiedAfter.IsZero() || !options.ModifiedBefore.IsZero() ||
+		!options.ShowDirs || !options.ShowFiles || options.ShowHidden ||
+		options.hasMetadataCriteria() || options.Expression != ""
+}
 
-		// Проверка типа (файл/директория)
-		if entry.IsDir() && !options.ShowDirs {
-			continue
+// String возвращает описание фильтра в виде аргументов команды filter
+func (options *FilterOptions) String() string {
+	var parts []string
+	if options.Expression != "" {
+		parts = append(parts, options.Expression)
+	}
+	if len(options.Extensions) > 0 {
+		parts = append(parts, "--ext="+strings.Join(options.Extensions, ","))
+	}
+	if options.NamePattern != "" && options.NameIgnoreCase {
+		parts = append(parts, "--iname="+options.NamePattern)
+	} else if options.NamePattern != "" {
+		parts = append(parts, "--name="+options.NamePattern)
+	}
+	if options.MinSize >= 0 || options.MaxSize >= 0 {
+		parts = append(parts, "--size="+formatBound(options.MinSize)+".."+formatBound(options.MaxSize))
+	}
+	if !options.ModifiedAfter.IsZero() || !options.ModifiedBefore.IsZero() {
+		parts = append(parts, "--date="+formatDateBound(options.ModifiedAfter)+".."+formatDateBound(options.ModifiedBefore))
+	}
+	parts = append(parts, options.metadataArgs()...)
+	if !options.ShowDirs || !options.ShowFiles || options.ShowHidden {
+		types := ""
+		if options.ShowFiles {
+			types += "f"
+		}
+		if options.ShowDirs {
+			types += "d"
 		}
+		if options.ShowHidden {
+			types += "h"
+		}
+		parts = append(parts, "--type="+types)
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatBound форматирует границу диапазона размеров (-1 — граница не задана)
+func formatBound(size int64) string {
+	if size < 0 {
+		return ""
+	}
+	return strconv.FormatInt(size, 10)
+}
 
-		if !entry.IsDir() && !options.ShowFiles {
-			continue
+// formatDateBound форматирует границу диапазона дат (нулевое время — граница не задана)
+func formatDateBound(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(dateLayout)
+}
+
+// Filter фильтрует список записей директории basePath согласно опциям фильтрации
+func Filter(entries []os.DirEntry, basePath string, options *FilterOptions) ([]os.DirEntry, error) {
+	matcher, err := options.Matcher()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []os.DirEntry
+	for _, entry := range entries {
+		if matcher.Match(entry, basePath) {
+			result = append(result, entry)
 		}
+	}
+	return result, nil
+}
 
-		// Проверка имени файла
-		if options.NamePattern != "" {
-			match, err := filepath.Match(options.NamePattern, entry.Name())
-			if err != nil {
-				return nil, err
-			}
-			if !match {
-				continue
-			}
+// Matcher проверяет отдельные записи на соответствие фильтру. Выражение, владелец и группа
+// разбираются один раз при создании, поэтому один Matcher подходит для обхода целого дерева.
+type Matcher struct {
+	options *FilterOptions
+	name    *glob.Pattern
+	query   *Query
+	meta    *metaCriteria
+
+	// Выражение само проверяет поле hidden, поэтому скрытые файлы не отсекаются заранее
+	queryHidden bool
+}
+
+// Matcher подготавливает фильтр к проверке записей
+func (options *FilterOptions) Matcher() (*Matcher, error) {
+	var name *glob.Pattern
+	if options.NamePattern != "" {
+		var err error
+		if options.NameIgnoreCase {
+			name, err = glob.CompileIgnoreCase(options.NamePattern)
+		} else {
+			name, err = glob.Compile(options.NamePattern)
 		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	query, err := options.compiledQuery()
+	if err != nil {
+		return nil, err
+	}
+	meta, err := options.compileMetadata()
+	if err != nil {
+		return nil, err
+	}
+	return &Matcher{
+		options:     options,
+		name:        name,
+		query:       query,
+		meta:        meta,
+		queryHidden: query != nil && query.References("hidden"),
+	}, nil
+}
 
-		// Проверка расширения (только для файлов)
-		if len(options.Extensions) > 0 {
-			if entry.IsDir() {
-				continue // Пропускаем директории при фильтрации по расширению
-			}
+// Match проверяет, проходит ли запись директории basePath через фильтр
+func (m *Matcher) Match(entry fs.DirEntry, basePath string) bool {
+	options := m.options
 
-			ext := strings.ToLower(filepath.Ext(entry.Name()))
-			if ext != "" {
-				ext = ext[1:] // Убираем точку в начале
-			}
+	// Проверка скрытых файлов (если выражение само проверяет hidden, решает оно)
+	if !options.ShowHidden && isHidden(entry.Name()) && !m.queryHidden {
+		return false
+	}
 
-			matchExtension := false
-			for _, allowedExt := range options.Extensions {
-				if ext == strings.ToLower(allowedExt) {
-					matchExtension = true
-					break
-				}
-			}
+	// Проверка типа (файл/директория)
+	if entry.IsDir() && !options.ShowDirs {
+		return false
+	}
 
-			if !matchExtension {
-				continue
-			}
+	if !entry.IsDir() && !options.ShowFiles {
+		return false
+	}
+
+	// Проверка имени файла
+	if m.name != nil && !m.name.Match(entry.Name()) {
+		return false
+	}
+
+	// Проверка расширения (только для файлов)
+	if len(options.Extensions) > 0 {
+		if entry.IsDir() {
+			return false // Пропускаем директории при фильтрации по расширению
 		}
 
-		// Проверка размера (только для файлов)
-		if options.MinSize >= 0 || options.MaxSize >= 0 {
-			// Пропускаем директории при фильтрации по размеру
-			if entry.IsDir() {
-				continue
-			}
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if ext != "" {
+			ext = ext[1:] // Убираем точку в начале
+		}
 
-			info, err := entry.Info()
-			if err != nil {
-				// Пропускаем файлы, к которым нет доступа
-				continue
+		matchExtension := false
+		for _, allowedExt := range options.Extensions {
+			if ext == strings.ToLower(allowedExt) {
+				matchExtension = true
+				break
 			}
+		}
 
-			size := info.Size()
-			// Размер должен быть >= MinSize, если MinSize указан
-			if options.MinSize >= 0 && size < options.MinSize {
-				continue
-			}
-			// Размер должен быть <= MaxSize, если MaxSize указан
-			if options.MaxSize >= 0 && size > options.MaxSize {
-				continue
-			}
+		if !matchExtension {
+			return false
 		}
+	}
 
-		// Проверка даты изменения
-		if !options.ModifiedAfter.IsZero() || !options.ModifiedBefore.IsZero() {
-			info, err := entry.Info()
-			if err != nil {
-				// Пропускаем файлы, к которым нет доступа
-				continue
-			}
+	// Проверка размера (только для файлов)
+	if options.MinSize >= 0 || options.MaxSize >= 0 {
+		// Пропускаем директории при фильтрации по размеру
+		if entry.IsDir() {
+			return false
+		}
 
-			modTime := info.ModTime()
-			if !options.ModifiedAfter.IsZero() && modTime.Before(options.ModifiedAfter) {
-				continue
-			}
-			if !options.ModifiedBefore.IsZero() && modTime.After(options.ModifiedBefore) {
-				continue
-			}
+		info, err := entry.Info()
+		if err != nil {
+			// Пропускаем файлы, к которым нет доступа
+			return false
 		}
 
-		// Если прошли все фильтры, добавляем запись в результат
-		result = append(result, entry)
+		size := info.Size()
+		// Размер должен быть >= MinSize, если MinSize указан
+		if options.MinSize >= 0 && size < options.MinSize {
+			return false
+		}
+		// Размер должен быть <= MaxSize, если MaxSize указан
+		if options.MaxSize >= 0 && size > options.MaxSize {
+			return false
+		}
 	}
 
-	return result, nil
+	// Проверка даты изменения
+	if !options.ModifiedAfter.IsZero() || !options.ModifiedBefore.IsZero() {
+		info, err := entry.Info()
+		if err != nil {
+			// Пропускаем файлы, к которым нет доступа
+			return false
+		}
+
+		modTime := info.ModTime()
+		if !options.ModifiedAfter.IsZero() && modTime.Before(options.ModifiedAfter) {
+			return false
+		}
+		if !options.ModifiedBefore.IsZero() && modTime.After(options.ModifiedBefore) {
+			return false
+		}
+	}
+
+	// Проверка метаданных (права, владелец, ссылки, пустота)
+	if m.meta != nil && !m.meta.match(entry, basePath) {
+		return false
+	}
+
+	// Проверка выражения фильтра
+	if m.query != nil && !m.query.MatchEntry(entry) {
+		return false
+	}
+
+	return true
+}
+
+// compiledQuery возвращает разобранное выражение фильтра или nil, если выражение не задано
+func (options *FilterOptions) compiledQuery() (*Query, error) {
+	if options.Expression == "" {
+		return nil, nil
+	}
+	if options.query == nil || options.query.String() != options.Expression {
+		query, err := ParseQuery(options.Expression)
+		if err != nil {
+			return nil, err
+		}
+		options.query = query
+	}
+	return options.query, nil
 }
 
 // isHidden проверяет, является ли файл скрытым
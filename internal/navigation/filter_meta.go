@@ -0,0 +1,183 @@
+package navigation
+
+import (
+	"file-manager/internal/i18n"
+	"file-manager/internal/sysinfo"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// metaCriteria — подготовленные к проверке критерии по метаданным файла
+type metaCriteria struct {
+	options   *FilterOptions
+	uid, gid  uint32
+	newerThan time.Time
+}
+
+// hasMetadataCriteria проверяет, задан ли хотя бы один критерий по метаданным
+func (options *FilterOptions) hasMetadataCriteria() bool {
+	return options.Executable || options.WorldWritable || options.Setuid || options.Setgid ||
+		options.Owner != "" || options.Group != "" ||
+		options.Symlink || options.BrokenSymlink || options.Empty ||
+		options.NewerThan != "" || options.MinLinks > 0 || options.MaxLinks > 0
+}
+
+// metadataArgs возвращает критерии по метаданным в виде аргументов команды filter
+func (options *FilterOptions) metadataArgs() []string {
+	var args []string
+	flags := []struct {
+		set  bool
+		name string
+	}{
+		{options.Executable, "--exec"},
+		{options.WorldWritable, "--world-writable"},
+		{options.Setuid, "--setuid"},
+		{options.Setgid, "--setgid"},
+		{options.Symlink, "--symlink"},
+		{options.BrokenSymlink, "--broken-symlink"},
+		{options.Empty, "--empty"},
+	}
+	for _, flag := range flags {
+		if flag.set {
+			args = append(args, flag.name)
+		}
+	}
+	if options.Owner != "" {
+		args = append(args, "--owner="+options.Owner)
+	}
+	if options.Group != "" {
+		args = append(args, "--group="+options.Group)
+	}
+	if options.NewerThan != "" {
+		args = append(args, "--newer="+options.NewerThan)
+	}
+	if options.MinLinks > 0 || options.MaxLinks > 0 {
+		args = append(args, "--links="+formatLinks(options.MinLinks)+".."+formatLinks(options.MaxLinks))
+	}
+	return args
+}
+
+// formatLinks форматирует границу числа ссылок (0 — граница не задана)
+func formatLinks(n int64) string {
+	if n <= 0 {
+		return ""
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+// compileMetadata подготавливает критерии по метаданным: разрешает владельца и группу
+// и читает время изменения файла для сравнения. Возвращает nil, если критериев нет.
+func (options *FilterOptions) compileMetadata() (*metaCriteria, error) {
+	if !options.hasMetadataCriteria() {
+		return nil, nil
+	}
+	meta := &metaCriteria{options: options}
+	var err error
+	if options.Owner != "" {
+		if meta.uid, err = sysinfo.LookupUser(options.Owner); err != nil {
+			return nil, err
+		}
+	}
+	if options.Group != "" {
+		if meta.gid, err = sysinfo.LookupGroup(options.Group); err != nil {
+			return nil, err
+		}
+	}
+	if options.NewerThan != "" {
+		info, err := os.Stat(options.NewerThan)
+		if err != nil {
+			return nil, fmt.Errorf(i18n.T("filter_newer_reference"), options.NewerThan, err)
+		}
+		meta.newerThan = info.ModTime()
+	}
+	return meta, nil
+}
+
+// match проверяет запись директории basePath на соответствие всем критериям по метаданным
+func (meta *metaCriteria) match(entry fs.DirEntry, basePath string) bool {
+	options := meta.options
+	path := filepath.Join(basePath, entry.Name())
+	isLink := entry.Type()&fs.ModeSymlink != 0
+
+	if options.Symlink && !isLink {
+		return false
+	}
+	if options.BrokenSymlink {
+		if !isLink {
+			return false
+		}
+		if _, err := os.Stat(path); err == nil {
+			return false
+		}
+	}
+
+	info, err := entry.Info()
+	if err != nil {
+		// Пропускаем файлы, к которым нет доступа
+		return false
+	}
+	mode := info.Mode()
+
+	if options.Executable && (!mode.IsRegular() || mode.Perm()&0111 == 0) {
+		return false
+	}
+	if options.WorldWritable && (isLink || mode.Perm()&0002 == 0) {
+		return false
+	}
+	if options.Setuid && mode&fs.ModeSetuid == 0 {
+		return false
+	}
+	if options.Setgid && mode&fs.ModeSetgid == 0 {
+		return false
+	}
+	if !meta.newerThan.IsZero() && !info.ModTime().After(meta.newerThan) {
+		return false
+	}
+	if options.Empty && !isEmpty(path, info) {
+		return false
+	}
+
+	if options.Owner != "" || options.Group != "" || options.MinLinks > 0 || options.MaxLinks > 0 {
+		stat, ok := sysinfo.Stat(info)
+		if !ok {
+			// Сведения о владельце и ссылках недоступны на этой платформе
+			return false
+		}
+		if options.Owner != "" && stat.UID != meta.uid {
+			return false
+		}
+		if options.Group != "" && stat.GID != meta.gid {
+			return false
+		}
+		if options.MinLinks > 0 && stat.Nlink < uint64(options.MinLinks) {
+			return false
+		}
+		if options.MaxLinks > 0 && stat.Nlink > uint64(options.MaxLinks) {
+			return false
+		}
+	}
+	return true
+}
+
+// isEmpty проверяет, является ли запись пустым файлом или пустой директорией
+func isEmpty(path string, info fs.FileInfo) bool {
+	switch {
+	case info.Mode().IsRegular():
+		return info.Size() == 0
+	case info.IsDir():
+		dir, err := os.Open(path)
+		if err != nil {
+			return false
+		}
+		defer func() { _ = dir.Close() }()
+		_, err = dir.Readdirnames(1)
+		return err == io.EOF
+	default:
+		return false
+	}
+}
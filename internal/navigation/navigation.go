@@ -1,29 +1,65 @@
 package navigation
 
 import (
+	"errors"
 	"file-manager/internal/i18n"
 	"fmt"
 	"io/fs"
 	"os"
+	"path/filepath"
 	"sort"
 )
 
-// Navigator предоставляет функции для навигации по файловой системе
+// maxHistory ограничивает количество запоминаемых директорий
+const maxHistory = 100
+
+// Navigator предоставляет функции для навигации по файловой системе.
+// Каждый экземпляр хранит собственную текущую директорию и не изменяет
+// рабочую директорию процесса, поэтому несколько навигаторов могут
+// использоваться одновременно.
 type Navigator struct {
 	CurrentDir string
+	history    []string
 }
 
-// NewNavigator создает новый экземпляр Navigator
+// NewNavigator создает новый экземпляр Navigator, начинающий с рабочей директории процесса
 func NewNavigator() (*Navigator, error) {
 	currentDir, err := os.Getwd()
 	if err != nil {
 		return nil, fmt.Errorf("не удалось получить текущую директорию: %w", err)
 	}
+	return NewNavigatorAt(currentDir)
+}
+
+// NewNavigatorAt создает новый экземпляр Navigator, начинающий с указанной директории
+func NewNavigatorAt(dir string) (*Navigator, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("nav_stat"), dir, err)
+	}
+	info, err := os.Stat(absDir)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("nav_stat"), absDir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf(i18n.T("nav_notdir"), absDir)
+	}
 	return &Navigator{
-		CurrentDir: currentDir,
+		CurrentDir: absDir,
 	}, nil
 }
 
+// ResolvePath преобразует путь относительно текущей директории навигатора в абсолютный
+func (n *Navigator) ResolvePath(path string) string {
+	if path == "" {
+		return n.CurrentDir
+	}
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(n.CurrentDir, path)
+}
+
 // ListDirectory отображает содержимое текущей директории
 func (n *Navigator) ListDirectory() ([]fs.DirEntry, error) {
 	entries, err := os.ReadDir(n.CurrentDir)
@@ -45,28 +81,56 @@ func (n *Navigator) ListDirectory() ([]fs.DirEntry, error) {
 	return entries, nil
 }
 
-// ChangeDirectory изменяет текущую директорию
+// ChangeDirectory изменяет текущую директорию навигатора.
+// Относительные пути разрешаются относительно текущей директории навигатора.
 func (n *Navigator) ChangeDirectory(targetPath string) error {
-	info, err := os.Stat(targetPath)
+	resolved := n.ResolvePath(targetPath)
+	info, err := os.Stat(resolved)
 	if err != nil {
 		return fmt.Errorf(i18n.T("nav_stat"), targetPath, err)
 	}
 	if !info.IsDir() {
 		return fmt.Errorf(i18n.T("nav_notdir"), targetPath)
 	}
-	err = os.Chdir(targetPath)
+	if resolved != n.CurrentDir {
+		n.history = append(n.history, n.CurrentDir)
+		if len(n.history) > maxHistory {
+			n.history = n.history[len(n.history)-maxHistory:]
+		}
+	}
+	n.CurrentDir = resolved
+	return nil
+}
+
+// Back возвращает навигатор в предыдущую директорию из истории
+func (n *Navigator) Back() error {
+	if len(n.history) == 0 {
+		return errors.New(i18n.T("nav_history_empty"))
+	}
+	prev := n.history[len(n.history)-1]
+	info, err := os.Stat(prev)
 	if err != nil {
-		return fmt.Errorf(i18n.T("nav_chdir"), targetPath, err)
+		return fmt.Errorf(i18n.T("nav_stat"), prev, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf(i18n.T("nav_notdir"), prev)
 	}
-	n.CurrentDir = targetPath
+	n.history = n.history[:len(n.history)-1]
+	n.CurrentDir = prev
 	return nil
 }
 
+// History возвращает список ранее посещенных директорий (от старых к новым)
+func (n *Navigator) History() []string {
+	result := make([]string, len(n.history))
+	copy(result, n.history)
+	return result
+}
+
 // GetCurrentDirectory возвращает текущую директорию
 func (n *Navigator) GetCurrentDirectory() (string, error) {
-	dir, err := os.Getwd()
-	if err != nil {
-		return "", fmt.Errorf(i18n.T("nav_getwd"), err)
+	if n.CurrentDir == "" {
+		return "", fmt.Errorf(i18n.T("nav_getwd"), errors.New(i18n.T("nav_no_dir")))
 	}
-	return dir, nil
+	return n.CurrentDir, nil
 }
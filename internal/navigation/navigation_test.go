@@ -4,8 +4,11 @@ import (
 	"file-manager/internal/i18n"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestNavigator(t *testing.T) {
@@ -158,6 +161,43 @@ func TestNavigator(t *testing.T) {
 		}
 	})
 
+	// Тест на независимость навигатора от рабочей директории процесса
+	t.Run("ChangeDirectory_ProcessDirUntouched", func(t *testing.T) {
+		processDir, err := os.Getwd()
+		if err != nil {
+			t.Fatalf("не удалось получить рабочую директорию процесса: %v", err)
+		}
+
+		if err := navigator.ChangeDirectory(nestedDir); err != nil {
+			t.Fatalf("не удалось изменить директорию на %s: %v", nestedDir, err)
+		}
+
+		afterDir, err := os.Getwd()
+		if err != nil {
+			t.Fatalf("не удалось получить рабочую директорию процесса: %v", err)
+		}
+		if afterDir != processDir {
+			t.Errorf("рабочая директория процесса изменилась: %s -> %s", processDir, afterDir)
+		}
+	})
+
+	// Тест на возврат в предыдущую директорию
+	t.Run("Back", func(t *testing.T) {
+		if err := navigator.ChangeDirectory(tempDir); err != nil {
+			t.Fatalf("не удалось изменить директорию на %s: %v", tempDir, err)
+		}
+		if err := navigator.ChangeDirectory(nestedDir); err != nil {
+			t.Fatalf("не удалось изменить директорию на %s: %v", nestedDir, err)
+		}
+
+		if err := navigator.Back(); err != nil {
+			t.Fatalf("не удалось вернуться в предыдущую директорию: %v", err)
+		}
+		if navigator.CurrentDir != tempDir {
+			t.Errorf("текущая директория (%s) не соответствует ожидаемой (%s)", navigator.CurrentDir, tempDir)
+		}
+	})
+
 	// Тест на обработку ошибок при изменении директории
 	t.Run("ChangeDirectory_Error", func(t *testing.T) {
 		nonExistentDir := filepath.Join(tempDir, "non_existent")
@@ -168,6 +208,81 @@ func TestNavigator(t *testing.T) {
 	})
 }
 
+func TestTabManager(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "tabs_test")
+	if err != nil {
+		t.Fatalf("не удалось создать временную директорию: %v", err)
+	}
+	defer func() {
+		err := os.RemoveAll(tempDir)
+		if err != nil {
+			t.Errorf("ошибка при удалении временной директории: %v", err)
+		}
+	}()
+
+	nestedDir := filepath.Join(tempDir, "nested")
+	if err := os.Mkdir(nestedDir, 0755); err != nil {
+		t.Fatalf("не удалось создать вложенную директорию: %v", err)
+	}
+
+	navigator, err := NewNavigatorAt(tempDir)
+	if err != nil {
+		t.Fatalf("не удалось создать навигатор: %v", err)
+	}
+	tabs := NewTabManager(navigator)
+
+	// Вкладки имеют независимые директории и фильтры
+	t.Run("IndependentTabs", func(t *testing.T) {
+		second, err := tabs.NewTab(tempDir)
+		if err != nil {
+			t.Fatalf("не удалось открыть вкладку: %v", err)
+		}
+		if tabs.ActiveIndex() != 1 {
+			t.Errorf("новая вкладка должна стать активной, активна %d", tabs.ActiveIndex())
+		}
+
+		if err := second.Navigator.ChangeDirectory("nested"); err != nil {
+			t.Fatalf("не удалось изменить директорию: %v", err)
+		}
+		second.Filter.Extensions = []string{"go"}
+
+		first := tabs.Tabs()[0]
+		if first.Navigator.CurrentDir != tempDir {
+			t.Errorf("директория первой вкладки изменилась: %s", first.Navigator.CurrentDir)
+		}
+		if len(first.Filter.Extensions) != 0 {
+			t.Error("фильтр первой вкладки изменился")
+		}
+		if second.Navigator.CurrentDir != nestedDir {
+			t.Errorf("директория второй вкладки (%s) не соответствует ожидаемой (%s)", second.Navigator.CurrentDir, nestedDir)
+		}
+	})
+
+	// Переключение и закрытие вкладок
+	t.Run("SwitchAndClose", func(t *testing.T) {
+		if err := tabs.Switch(0); err != nil {
+			t.Fatalf("не удалось переключить вкладку: %v", err)
+		}
+		if err := tabs.Switch(5); err == nil {
+			t.Error("ожидалась ошибка при переключении на несуществующую вкладку")
+		}
+
+		if err := tabs.Close(0); err != nil {
+			t.Fatalf("не удалось закрыть вкладку: %v", err)
+		}
+		if tabs.Count() != 1 {
+			t.Errorf("неверное количество вкладок: %d", tabs.Count())
+		}
+		if tabs.Active().Navigator.CurrentDir != nestedDir {
+			t.Errorf("активной должна остаться вторая вкладка, директория: %s", tabs.Active().Navigator.CurrentDir)
+		}
+
+		if err := tabs.Close(0); err == nil {
+			t.Error("ожидалась ошибка при закрытии последней вкладки")
+		}
+	})
+}
+
 func TestBookmarkManager(t *testing.T) {
 	// Создаем временную директорию для тестов
 	tempDir, err := os.MkdirTemp("", "bookmark_test")
@@ -473,4 +588,621 @@ func TestFilter(t *testing.T) {
 			t.Error("скрытый файл не включен в результат фильтрации")
 		}
 	})
+
+	// Тест на фильтрацию по выражению
+	t.Run("FilterByExpression", func(t *testing.T) {
+		cases := []struct {
+			expr     string
+			expected []string
+		}{
+			{"ext = txt and size > 100", []string{"file2.txt"}},
+			{"ext in (jpg, TXT) and not name ~ ^file", []string{"hidden.txt", "image.jpg"}},
+			{"type = d or size < 60", []string{"hidden.txt", "subdir"}},
+			{"name = 'file?.txt'", []string{"file1.txt", "file2.txt"}},
+			{"hidden", []string{".hidden"}},
+			{"mtime < 1h and size >= 100", []string{"file1.txt", "file2.txt", "image.jpg"}},
+		}
+		for _, c := range cases {
+			options := NewFilterOptions()
+			options.Expression = c.expr
+
+			filtered, err := Filter(entries, tempDir, options)
+			if err != nil {
+				t.Errorf("ошибка при фильтрации по выражению %q: %v", c.expr, err)
+				continue
+			}
+			var names []string
+			for _, entry := range filtered {
+				names = append(names, entry.Name())
+			}
+			if strings.Join(names, ",") != strings.Join(c.expected, ",") {
+				t.Errorf("выражение %q: получено %v, ожидалось %v", c.expr, names, c.expected)
+			}
+		}
+	})
+}
+
+func TestParseQuery(t *testing.T) {
+	t.Run("Ошибки с позицией", func(t *testing.T) {
+		cases := []struct {
+			expr   string
+			column int
+		}{
+			{"size >", 7},
+			{"colour = red", 1},
+			{"ext in (go", 11},
+			{"(ext = go", 10},
+			{"size > 10Q", 8},
+			{"mtime = 7d", 9},
+			{"name ~ \"abc", 8},
+			{"ext = go go", 10},
+			{"имя = x", 1},
+			{"ext = го и", 10},
+		}
+		for _, c := range cases {
+			_, err := ParseQuery(c.expr)
+			queryErr, ok := err.(*QueryError)
+			if !ok {
+				t.Errorf("ожидалась QueryError для %q, получено %v", c.expr, err)
+				continue
+			}
+			if queryErr.Column() != c.column {
+				t.Errorf("%q: позиция ошибки %d, ожидалась %d", c.expr, queryErr.Column(), c.column)
+			}
+			if !strings.HasSuffix(queryErr.Caret(), "\n"+strings.Repeat(" ", c.column-1)+"^") {
+				t.Errorf("%q: неверный указатель ошибки:\n%s", c.expr, queryErr.Caret())
+			}
+		}
+	})
+
+	t.Run("Приоритет операторов", func(t *testing.T) {
+		// and связывает сильнее or: a or (b and c)
+		query, err := ParseQuery("NAME = a OR name = b AND name = c")
+		if err != nil {
+			t.Fatalf("ошибка разбора: %v", err)
+		}
+		if _, ok := query.root.(*orNode); !ok {
+			t.Errorf("корнем выражения должен быть or, получено %T", query.root)
+		}
+		if !query.References("name") || query.References("size") {
+			t.Error("неверно определены используемые поля")
+		}
+	})
+
+	t.Run("Единицы измерения", func(t *testing.T) {
+		if size, err := ParseSize("10M"); err != nil || size != 10<<20 {
+			t.Errorf("ParseSize(10M) = %d, %v", size, err)
+		}
+		if age, err := ParseAge("2w"); err != nil || age != 14*24*time.Hour {
+			t.Errorf("ParseAge(2w) = %v, %v", age, err)
+		}
+		if _, err := ParseSize("M"); err == nil {
+			t.Error("ожидалась ошибка для размера без числа")
+		}
+		if _, err := ParseAge("7"); err == nil {
+			t.Error("ожидалась ошибка для возраста без единицы")
+		}
+	})
+}
+
+func TestParseRanges(t *testing.T) {
+	t.Run("Размеры", func(t *testing.T) {
+		sizes := map[string]int64{
+			"512":    512,
+			"10K":    10 << 10,
+			"1.5MiB": 3 << 19,
+			"2G":     2 << 30,
+			"2gb":    2e9,
+			"100B":   100,
+		}
+		for input, expected := range sizes {
+			if size, err := ParseSize(input); err != nil || size != expected {
+				t.Errorf("ParseSize(%q) = %d, %v; ожидалось %d", input, size, err, expected)
+			}
+		}
+		for _, input := range []string{"", "10X", "1.2.3K", "-5"} {
+			if _, err := ParseSize(input); err == nil {
+				t.Errorf("ожидалась ошибка для размера %q", input)
+			}
+		}
+	})
+
+	t.Run("Диапазоны размеров", func(t *testing.T) {
+		cases := []struct {
+			input    string
+			min, max int64
+		}{
+			{">100M", 100<<20 + 1, -1},
+			{">=1K", 1 << 10, -1},
+			{"<=10K", -1, 10 << 10},
+			{"<1K", -1, 1<<10 - 1},
+			{"1K..1M", 1 << 10, 1 << 20},
+			{"100-1000", 100, 1000},
+			{"..1M", -1, 1 << 20},
+			{"1K..", 1 << 10, -1},
+			{"4K", 4 << 10, 4 << 10},
+		}
+		for _, c := range cases {
+			minSize, maxSize, err := ParseSizeRange(c.input)
+			if err != nil || minSize != c.min || maxSize != c.max {
+				t.Errorf("ParseSizeRange(%q) = %d, %d, %v; ожидалось %d, %d", c.input, minSize, maxSize, err, c.min, c.max)
+			}
+		}
+		for _, input := range []string{"..", "1M..1K", "<0", ">abc", "1K..1Q"} {
+			if _, _, err := ParseSizeRange(input); err == nil {
+				t.Errorf("ожидалась ошибка для диапазона %q", input)
+			}
+		}
+	})
+
+	t.Run("Диапазоны дат", func(t *testing.T) {
+		now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.Local)
+		day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.Local) }
+		endOf := func(t time.Time) time.Time { return t.AddDate(0, 0, 1).Add(-time.Nanosecond) }
+
+		cases := []struct {
+			input         string
+			after, before time.Time
+		}{
+			{"2024-01-01..2024-02-01", day(2024, 1, 1), endOf(day(2024, 2, 1))},
+			{"2024-01-01-2024-02-01", day(2024, 1, 1), endOf(day(2024, 2, 1))},
+			{"2024-01-01..", day(2024, 1, 1), time.Time{}},
+			{"..2024-02-01", time.Time{}, endOf(day(2024, 2, 1))},
+			{"7d", now.Add(-7 * 24 * time.Hour), time.Time{}},
+			{"..2w", time.Time{}, now.Add(-14 * 24 * time.Hour)},
+			{"yesterday", day(2024, 3, 14), endOf(day(2024, 3, 14))},
+			{"Today", day(2024, 3, 15), endOf(day(2024, 3, 15))},
+			{"2024-03-10", day(2024, 3, 10), endOf(day(2024, 3, 10))},
+		}
+		for _, c := range cases {
+			after, before, err := ParseDateRange(c.input, now)
+			if err != nil || !after.Equal(c.after) || !before.Equal(c.before) {
+				t.Errorf("ParseDateRange(%q) = %v, %v, %v; ожидалось %v, %v", c.input, after, before, err, c.after, c.before)
+			}
+		}
+		for _, input := range []string{"..", "2024-13-01", "2024-02-01..2024-01-01", "tomorrow", "2024-01-01..soon"} {
+			if _, _, err := ParseDateRange(input, now); err == nil {
+				t.Errorf("ожидалась ошибка для диапазона дат %q", input)
+			}
+		}
+	})
+}
+
+func TestFilterPresets(t *testing.T) {
+	tempDir := t.TempDir()
+	manager := &FilterPresetManager{FiltersFile: filepath.Join(tempDir, "filters.json")}
+
+	options := NewFilterOptions()
+	options.Extensions = []string{"go"}
+	options.Expression = "size > 1K"
+
+	t.Run("IsActive и String", func(t *testing.T) {
+		if NewFilterOptions().IsActive() {
+			t.Error("фильтр по умолчанию не должен считаться активным")
+		}
+		if !options.IsActive() {
+			t.Error("фильтр с условиями должен считаться активным")
+		}
+		if got := options.String(); got != "size > 1K --ext=go" {
+			t.Errorf("неверное описание фильтра: %q", got)
+		}
+	})
+
+	t.Run("Сохранение и загрузка", func(t *testing.T) {
+		if err := manager.SavePreset("go", options); err != nil {
+			t.Fatalf("ошибка при сохранении фильтра: %v", err)
+		}
+		if err := manager.SavePreset("bad name", options); err == nil {
+			t.Error("ожидалась ошибка для недопустимого имени")
+		}
+
+		loaded := &FilterPresetManager{FiltersFile: manager.FiltersFile}
+		if err := loaded.LoadFilters(); err != nil {
+			t.Fatalf("ошибка при загрузке фильтров: %v", err)
+		}
+		preset, err := loaded.GetPreset("go")
+		if err != nil {
+			t.Fatalf("сохраненный фильтр не найден: %v", err)
+		}
+		if preset.String() != options.String() {
+			t.Errorf("загруженный фильтр отличается: %q", preset)
+		}
+
+		// Изменение копии не должно затрагивать сохраненный фильтр
+		preset.Extensions[0] = "md"
+		if again, _ := loaded.GetPreset("go"); again.Extensions[0] != "go" {
+			t.Error("GetPreset должен возвращать копию фильтра")
+		}
+
+		if err := loaded.RemovePreset("go"); err != nil {
+			t.Errorf("ошибка при удалении фильтра: %v", err)
+		}
+		if _, err := loaded.GetPreset("go"); err == nil {
+			t.Error("удаленный фильтр не должен находиться")
+		}
+	})
+
+	t.Run("Закрепление за директорией", func(t *testing.T) {
+		repo := filepath.Join(tempDir, "repo")
+		nested := filepath.Join(repo, "pkg")
+		if _, err := manager.Pin(repo, options); err != nil {
+			t.Fatalf("ошибка при закреплении фильтра: %v", err)
+		}
+		if _, err := manager.Pin(nested, NewFilterOptions()); err != nil {
+			t.Fatalf("ошибка при закреплении фильтра: %v", err)
+		}
+
+		if pin := manager.PinFor(filepath.Join(repo, "cmd")); pin == nil || pin.Dir != repo {
+			t.Errorf("для поддиректории должно действовать закрепление %s, получено %v", repo, pin)
+		}
+		if pin := manager.PinFor(filepath.Join(nested, "x")); pin == nil || pin.Dir != nested {
+			t.Errorf("должно действовать ближайшее закрепление %s, получено %v", nested, pin)
+		}
+		if pin := manager.PinFor(repo + "-other"); pin != nil {
+			t.Errorf("соседняя директория не должна попадать под закрепление: %v", pin)
+		}
+
+		tab := &Tab{Filter: NewFilterOptions()}
+		tab.ApplyPin(manager.PinFor(repo))
+		if tab.Filter.String() != options.String() {
+			t.Errorf("при входе в дерево должен примениться закрепленный фильтр, получено %q", tab.Filter)
+		}
+
+		// Фильтр, заданный вручную, сохраняется внутри дерева и после выхода из него
+		manual := NewFilterOptions()
+		manual.NamePattern = "*.txt"
+		tab.SetFilter(manual, "")
+		tab.ApplyPin(manager.PinFor(filepath.Join(repo, "cmd")))
+		tab.ApplyPin(nil)
+		if tab.Filter != manual {
+			t.Error("фильтр, заданный вручную, не должен сбрасываться")
+		}
+
+		tab.ApplyPin(manager.PinFor(repo))
+		tab.ApplyPin(nil)
+		if tab.Filter.IsActive() {
+			t.Errorf("при выходе из дерева закрепленный фильтр должен сниматься, получено %q", tab.Filter)
+		}
+
+		if err := manager.Unpin(repo); err != nil {
+			t.Errorf("ошибка при снятии закрепления: %v", err)
+		}
+		if err := manager.Unpin(repo); err == nil {
+			t.Error("ожидалась ошибка при повторном снятии закрепления")
+		}
+	})
+}
+
+func TestFilterMetadata(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Права доступа и ссылки Unix не поддерживаются на Windows")
+	}
+	tempDir := t.TempDir()
+	old := time.Now().Add(-48 * time.Hour)
+
+	files := map[string]os.FileMode{
+		"script.sh": 0755,
+		"shared":    0666,
+		"suid":      0755 | os.ModeSetuid,
+		"sgid":      0755 | os.ModeSetgid,
+		"empty":     0644,
+		"data":      0644,
+		"reference": 0644,
+	}
+	for name, mode := range files {
+		path := filepath.Join(tempDir, name)
+		content := []byte("content")
+		if name == "empty" {
+			content = nil
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatalf("не удалось создать файл %s: %v", name, err)
+		}
+		// Права задаются отдельно, чтобы на них не влияла umask
+		if err := os.Chmod(path, mode); err != nil {
+			t.Fatalf("не удалось изменить права %s: %v", name, err)
+		}
+		if name != "data" {
+			if err := os.Chtimes(path, old, old); err != nil {
+				t.Fatalf("не удалось изменить время %s: %v", name, err)
+			}
+		}
+	}
+	if err := os.Mkdir(filepath.Join(tempDir, "emptydir"), 0755); err != nil {
+		t.Fatalf("не удалось создать директорию: %v", err)
+	}
+	if err := os.Link(filepath.Join(tempDir, "data"), filepath.Join(tempDir, "data-link")); err != nil {
+		t.Fatalf("не удалось создать жесткую ссылку: %v", err)
+	}
+	if err := os.Symlink("data", filepath.Join(tempDir, "good-link")); err != nil {
+		t.Fatalf("не удалось создать символическую ссылку: %v", err)
+	}
+	if err := os.Symlink("missing", filepath.Join(tempDir, "bad-link")); err != nil {
+		t.Fatalf("не удалось создать символическую ссылку: %v", err)
+	}
+
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		t.Fatalf("не удалось прочитать содержимое директории: %v", err)
+	}
+
+	cases := []struct {
+		name     string
+		set      func(o *FilterOptions)
+		expected string
+	}{
+		{"Исполняемые", func(o *FilterOptions) { o.Executable = true }, "script.sh,sgid,suid"},
+		{"Доступные на запись всем", func(o *FilterOptions) { o.WorldWritable = true }, "shared"},
+		{"Setuid", func(o *FilterOptions) { o.Setuid = true }, "suid"},
+		{"Setgid", func(o *FilterOptions) { o.Setgid = true }, "sgid"},
+		{"Символические ссылки", func(o *FilterOptions) { o.Symlink = true }, "bad-link,good-link"},
+		{"Битые ссылки", func(o *FilterOptions) { o.BrokenSymlink = true }, "bad-link"},
+		{"Пустые", func(o *FilterOptions) { o.Empty = true }, "empty,emptydir"},
+		{"Новее файла", func(o *FilterOptions) { o.NewerThan = filepath.Join(tempDir, "reference") }, "bad-link,data,data-link,emptydir,good-link"},
+		{"Жесткие ссылки", func(o *FilterOptions) { o.MinLinks = 2; o.ShowDirs = false }, "data,data-link"},
+		{"Владелец", func(o *FilterOptions) { o.Owner = strconv.Itoa(os.Getuid()); o.MaxLinks = 1; o.ShowDirs = false }, "bad-link,empty,good-link,reference,script.sh,sgid,shared,suid"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			options := NewFilterOptions()
+			c.set(options)
+			filtered, err := Filter(entries, tempDir, options)
+			if err != nil {
+				t.Fatalf("ошибка при фильтрации: %v", err)
+			}
+			var names []string
+			for _, entry := range filtered {
+				names = append(names, entry.Name())
+			}
+			if got := strings.Join(names, ","); got != c.expected {
+				t.Errorf("получено %s, ожидалось %s", got, c.expected)
+			}
+			if !options.IsActive() {
+				t.Error("фильтр по метаданным должен считаться активным")
+			}
+		})
+	}
+
+	t.Run("Неизвестный владелец", func(t *testing.T) {
+		options := NewFilterOptions()
+		options.Owner = "no-such-user-for-filter-test"
+		if _, err := Filter(entries, tempDir, options); err == nil {
+			t.Error("ожидалась ошибка для неизвестного пользователя")
+		}
+	})
+
+	t.Run("Диапазон количества", func(t *testing.T) {
+		if minLinks, maxLinks, err := ParseCountRange("2..5"); err != nil || minLinks != 2 || maxLinks != 5 {
+			t.Errorf("ParseCountRange(2..5) = %d, %d, %v", minLinks, maxLinks, err)
+		}
+		for _, input := range []string{"1K", "1.5", "<1", "x"} {
+			if _, _, err := ParseCountRange(input); err == nil {
+				t.Errorf("ожидалась ошибка для %q", input)
+			}
+		}
+	})
+}
+
+// testBookmarks реализует BookmarkLookup для тестов
+type testBookmarks map[string]string
+
+func (b testBookmarks) GetBookmarkPath(name string) (string, error) {
+	path, ok := b[name]
+	if !ok {
+		return "", os.ErrNotExist
+	}
+	return path, nil
+}
+
+func TestExpandPath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatalf("не удалось получить домашнюю директорию: %v", err)
+	}
+	t.Setenv("FM_TEST_DIR", "/srv/data")
+
+	cwd := filepath.FromSlash("/work/project")
+	bookmarks := testBookmarks{"projects": filepath.FromSlash("/home/user/projects")}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"Relative", "src/main.go", filepath.FromSlash("/work/project/src/main.go"), false},
+		{"Absolute", "/etc/hosts", filepath.FromSlash("/etc/hosts"), false},
+		{"ParentChain", "../../tmp/../var", filepath.FromSlash("/var"), false},
+		{"Empty", "", cwd, false},
+		{"Home", "~", home, false},
+		{"HomeSubdir", "~/docs", filepath.Join(home, "docs"), false},
+		{"EnvVar", "$FM_TEST_DIR/file", filepath.FromSlash("/srv/data/file"), false},
+		{"EnvVarBraces", "${FM_TEST_DIR}x", filepath.FromSlash("/srv/datax"), false},
+		{"DollarLiteral", "price$", filepath.FromSlash("/work/project/price$"), false},
+		{"Bookmark", "@projects/src/main.go", filepath.FromSlash("/home/user/projects/src/main.go"), false},
+		{"BookmarkOnly", "@projects", filepath.FromSlash("/home/user/projects"), false},
+		{"UnknownBookmark", "@missing/file", "", true},
+		{"UndefinedVar", "$FM_TEST_UNDEFINED_VAR/x", "", true},
+		{"UnclosedVar", "${FM_TEST_DIR/x", "", true},
+		{"UnknownUser", "~fm_no_such_user_42/x", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandPath(tt.path, cwd, bookmarks)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ожидалась ошибка для %q, получено %q", tt.path, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("неожиданная ошибка для %q: %v", tt.path, err)
+			}
+			if got != tt.want {
+				t.Errorf("ExpandPath(%q) = %q, ожидалось %q", tt.path, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("Буквальные имена", func(t *testing.T) {
+		dir := t.TempDir()
+		for _, name := range []string{"@types", "$FM_TEST_UNDEFINED_VAR"} {
+			if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+				t.Fatalf("не удалось создать файл: %v", err)
+			}
+		}
+		for _, path := range []string{"@types", "./@types", "$FM_TEST_UNDEFINED_VAR"} {
+			got, err := ExpandPath(path, dir, bookmarks)
+			if err != nil {
+				t.Fatalf("неожиданная ошибка для %q: %v", path, err)
+			}
+			if want := filepath.Join(dir, path); got != want {
+				t.Errorf("ExpandPath(%q) = %q, ожидалось %q", path, got, want)
+			}
+		}
+		// Существующая закладка важнее одноименного файла
+		if err := os.WriteFile(filepath.Join(dir, "@projects"), nil, 0644); err != nil {
+			t.Fatalf("не удалось создать файл: %v", err)
+		}
+		if got, err := ExpandPath("@projects", dir, bookmarks); err != nil || got != bookmarks["projects"] {
+			t.Errorf("ожидался путь закладки, получено %q: %v", got, err)
+		}
+	})
+}
+
+func TestBookmarkExtensions(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "bookmark_ext_test")
+	if err != nil {
+		t.Fatalf("не удалось создать временную директорию: %v", err)
+	}
+	defer func() {
+		err := os.RemoveAll(tempDir)
+		if err != nil {
+			t.Errorf("ошибка при удалении временной директории: %v", err)
+		}
+	}()
+
+	projectDir := filepath.Join(tempDir, "my project")
+	goneDir := filepath.Join(tempDir, "gone")
+	for _, dir := range []string{projectDir, goneDir} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatalf("не удалось создать директорию: %v", err)
+		}
+	}
+
+	bookmarkManager := &BookmarkManager{BookmarksFile: filepath.Join(tempDir, "bookmarks.json")}
+	if err := bookmarkManager.AddBookmark("proj", projectDir); err != nil {
+		t.Fatalf("не удалось добавить закладку: %v", err)
+	}
+	if err := bookmarkManager.AddBookmark("gone", goneDir); err != nil {
+		t.Fatalf("не удалось добавить закладку: %v", err)
+	}
+
+	t.Run("InvalidName", func(t *testing.T) {
+		for _, name := range []string{"", "with space", "a/b", "@x", "-x"} {
+			if err := bookmarkManager.AddBookmark(name, projectDir); err == nil {
+				t.Errorf("ожидалась ошибка для имени %q", name)
+			}
+		}
+	})
+
+	t.Run("RenameAndTag", func(t *testing.T) {
+		if err := bookmarkManager.RenameBookmark("proj", "work"); err != nil {
+			t.Fatalf("не удалось переименовать закладку: %v", err)
+		}
+		if err := bookmarkManager.RenameBookmark("work", "gone"); err == nil {
+			t.Error("ожидалась ошибка при переименовании в существующее имя")
+		}
+		if err := bookmarkManager.TagBookmark("work", "go", "daily"); err != nil {
+			t.Fatalf("не удалось добавить теги: %v", err)
+		}
+		if err := bookmarkManager.UntagBookmark("work", "daily"); err != nil {
+			t.Fatalf("не удалось удалить тег: %v", err)
+		}
+		tagged := bookmarkManager.ListBookmarksByTag("GO")
+		if len(tagged) != 1 || tagged[0].Name != "work" {
+			t.Errorf("неверный результат фильтрации по тегу: %+v", tagged)
+		}
+		if len(bookmarkManager.ListBookmarksByTag("daily")) != 0 {
+			t.Error("удаленный тег остался у закладки")
+		}
+		if tagged[0].CreatedAt.IsZero() {
+			t.Error("не задана дата создания закладки")
+		}
+	})
+
+	t.Run("Check", func(t *testing.T) {
+		if err := os.Remove(goneDir); err != nil {
+			t.Fatalf("не удалось удалить директорию: %v", err)
+		}
+		broken := bookmarkManager.CheckBookmarks()
+		if len(broken) != 1 || broken[0].Name != "gone" {
+			t.Errorf("неверный список недействительных закладок: %+v", broken)
+		}
+		if err := bookmarkManager.UpdateBookmarkPath("gone", projectDir); err != nil {
+			t.Fatalf("не удалось исправить путь закладки: %v", err)
+		}
+		if len(bookmarkManager.CheckBookmarks()) != 0 {
+			t.Error("закладка осталась недействительной после исправления")
+		}
+	})
+
+	t.Run("GTKRoundTrip", func(t *testing.T) {
+		gtkFile := filepath.Join(tempDir, "gtk", "bookmarks")
+		if err := os.MkdirAll(filepath.Dir(gtkFile), 0755); err != nil {
+			t.Fatalf("не удалось создать директорию: %v", err)
+		}
+		existing := "sftp://server/home Server\nfile:///usr/share Shared Data\n"
+		if err := os.WriteFile(gtkFile, []byte(existing), 0644); err != nil {
+			t.Fatalf("не удалось создать файл закладок GTK: %v", err)
+		}
+
+		if err := bookmarkManager.ExportGTK(gtkFile); err != nil {
+			t.Fatalf("ошибка экспорта в GTK: %v", err)
+		}
+		data, err := os.ReadFile(gtkFile)
+		if err != nil {
+			t.Fatalf("не удалось прочитать файл закладок GTK: %v", err)
+		}
+		content := string(data)
+		if !strings.HasPrefix(content, existing) {
+			t.Errorf("экспорт не сохранил существующие записи GTK:\n%s", content)
+		}
+		if !strings.Contains(content, "my%20project work") {
+			t.Errorf("экспорт не содержит закладку с экранированным путем:\n%s", content)
+		}
+
+		imported := &BookmarkManager{BookmarksFile: filepath.Join(tempDir, "imported.json")}
+		added, err := imported.ImportGTK(gtkFile)
+		if err != nil {
+			t.Fatalf("ошибка импорта из GTK: %v", err)
+		}
+		if added != 2 {
+			t.Errorf("неверное количество импортированных закладок: %d", added)
+		}
+		path, err := imported.GetBookmarkPath("Shared_Data")
+		if err != nil || path != "/usr/share" {
+			t.Errorf("закладка из метки с пробелом импортирована неверно: %q, %v", path, err)
+		}
+		path, err = imported.GetBookmarkPath("work")
+		if err != nil || path != projectDir {
+			t.Errorf("путь с пробелом импортирован неверно: %q, %v", path, err)
+		}
+	})
+
+	t.Run("JSONRoundTrip", func(t *testing.T) {
+		exportFile := filepath.Join(tempDir, "export.json")
+		if err := bookmarkManager.ExportJSON(exportFile); err != nil {
+			t.Fatalf("ошибка экспорта в JSON: %v", err)
+		}
+		imported := &BookmarkManager{BookmarksFile: filepath.Join(tempDir, "imported_json.json")}
+		if _, err := imported.ImportJSON(exportFile); err != nil {
+			t.Fatalf("ошибка импорта из JSON: %v", err)
+		}
+		bookmarks := imported.ListBookmarks()
+		if len(bookmarks) != 1 || !bookmarks[0].HasTag("go") {
+			t.Errorf("импорт из JSON потерял данные закладок: %+v", bookmarks)
+		}
+	})
 }
@@ -0,0 +1,175 @@
+package navigation
+
+import (
+	"encoding/json"
+	"file-manager/internal/i18n"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FilterPreset представляет сохраненный именованный фильтр
+type FilterPreset struct {
+	Name    string        `json:"name"`
+	Options FilterOptions `json:"options"`
+}
+
+// PinnedFilter представляет фильтр, закрепленный за деревом директорий
+type PinnedFilter struct {
+	Dir     string        `json:"dir"`
+	Options FilterOptions `json:"options"`
+}
+
+// filtersFileData описывает формат файла filters.json
+type filtersFileData struct {
+	Presets []FilterPreset `json:"presets"`
+	Pins    []PinnedFilter `json:"pins"`
+}
+
+// FilterPresetManager управляет именованными фильтрами и закреплениями фильтров за директориями
+type FilterPresetManager struct {
+	Presets     []FilterPreset
+	Pins        []PinnedFilter
+	FiltersFile string
+}
+
+// NewFilterPresetManager создает новый экземпляр FilterPresetManager
+func NewFilterPresetManager() (*FilterPresetManager, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("bm_home"), err)
+	}
+
+	configDir := filepath.Join(homeDir, ".filemanager")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return nil, fmt.Errorf(i18n.T("bm_dir"), err)
+	}
+
+	manager := &FilterPresetManager{
+		Presets:     []FilterPreset{},
+		Pins:        []PinnedFilter{},
+		FiltersFile: filepath.Join(configDir, "filters.json"),
+	}
+	if err := manager.LoadFilters(); err != nil {
+		return nil, err
+	}
+	return manager, nil
+}
+
+// SavePreset сохраняет фильтр под указанным именем, заменяя существующий
+func (fm *FilterPresetManager) SavePreset(name string, options *FilterOptions) error {
+	if err := ValidateBookmarkName(name); err != nil {
+		return fmt.Errorf(i18n.T("filter_invalid_name"), name)
+	}
+	preset := FilterPreset{Name: name, Options: *options.Clone()}
+	for i := range fm.Presets {
+		if fm.Presets[i].Name == name {
+			fm.Presets[i] = preset
+			return fm.SaveFilters()
+		}
+	}
+	fm.Presets = append(fm.Presets, preset)
+	sort.Slice(fm.Presets, func(i, j int) bool { return fm.Presets[i].Name < fm.Presets[j].Name })
+	return fm.SaveFilters()
+}
+
+// GetPreset возвращает копию фильтра с указанным именем
+func (fm *FilterPresetManager) GetPreset(name string) (*FilterOptions, error) {
+	for i := range fm.Presets {
+		if fm.Presets[i].Name == name {
+			return fm.Presets[i].Options.Clone(), nil
+		}
+	}
+	return nil, fmt.Errorf(i18n.T("filter_preset_not_found"), name)
+}
+
+// RemovePreset удаляет фильтр с указанным именем
+func (fm *FilterPresetManager) RemovePreset(name string) error {
+	for i := range fm.Presets {
+		if fm.Presets[i].Name == name {
+			fm.Presets = append(fm.Presets[:i], fm.Presets[i+1:]...)
+			return fm.SaveFilters()
+		}
+	}
+	return fmt.Errorf(i18n.T("filter_preset_not_found"), name)
+}
+
+// Pin закрепляет фильтр за директорией и всеми ее поддиректориями
+func (fm *FilterPresetManager) Pin(dir string, options *FilterOptions) (*PinnedFilter, error) {
+	dir = filepath.Clean(dir)
+	for i := range fm.Pins {
+		if fm.Pins[i].Dir == dir {
+			fm.Pins[i].Options = *options.Clone()
+			return &fm.Pins[i], fm.SaveFilters()
+		}
+	}
+	fm.Pins = append(fm.Pins, PinnedFilter{Dir: dir, Options: *options.Clone()})
+	return &fm.Pins[len(fm.Pins)-1], fm.SaveFilters()
+}
+
+// Unpin снимает закрепление фильтра с директории
+func (fm *FilterPresetManager) Unpin(dir string) error {
+	dir = filepath.Clean(dir)
+	for i := range fm.Pins {
+		if fm.Pins[i].Dir == dir {
+			fm.Pins = append(fm.Pins[:i], fm.Pins[i+1:]...)
+			return fm.SaveFilters()
+		}
+	}
+	return fmt.Errorf(i18n.T("filter_pin_not_found"), dir)
+}
+
+// PinFor возвращает закрепление, действующее для директории (ближайшее по дереву), или nil
+func (fm *FilterPresetManager) PinFor(dir string) *PinnedFilter {
+	var best *PinnedFilter
+	for i := range fm.Pins {
+		pin := &fm.Pins[i]
+		if isWithin(dir, pin.Dir) && (best == nil || len(pin.Dir) > len(best.Dir)) {
+			best = pin
+		}
+	}
+	return best
+}
+
+// isWithin проверяет, находится ли путь внутри директории root (или совпадает с ней)
+func isWithin(path, root string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// SaveFilters сохраняет фильтры и закрепления в файл
+func (fm *FilterPresetManager) SaveFilters() error {
+	data, err := json.MarshalIndent(filtersFileData{Presets: fm.Presets, Pins: fm.Pins}, "", "  ")
+	if err != nil {
+		return fmt.Errorf(i18n.T("filter_marshal"), err)
+	}
+	if err := os.WriteFile(fm.FiltersFile, data, 0644); err != nil {
+		return fmt.Errorf(i18n.T("filter_write"), err)
+	}
+	return nil
+}
+
+// LoadFilters загружает фильтры и закрепления из файла (отсутствующий файл не является ошибкой)
+func (fm *FilterPresetManager) LoadFilters() error {
+	data, err := os.ReadFile(fm.FiltersFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf(i18n.T("filter_read"), err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	var file filtersFileData
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf(i18n.T("filter_unmarshal"), err)
+	}
+	fm.Presets, fm.Pins = file.Presets, file.Pins
+	return nil
+}
@@ -0,0 +1,624 @@
+package navigation
+
+import (
+	"file-manager/internal/glob"
+	"file-manager/internal/i18n"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Query представляет разобранное выражение фильтра, например:
+//
+//	ext in (go,md) and (size > 10M or mtime < 7d) and not name ~ "_test"
+//
+// Поддерживаемые поля:
+//   - name  — имя файла: = и != сравнивают с шаблоном glob (*, ?, [...], {a,b}), ~ и !~ — с регулярным выражением
+//   - ext   — расширение без точки (без учета регистра): =, != и in
+//   - size  — размер файла с суффиксами K, M, G, T, KiB, MB...: =, !=, <, <=, >, >=
+//   - mtime — время изменения: возраст (30m, 12h, 7d, 2w, 1y), дата YYYY-MM-DD, today или yesterday
+//   - type  — тип записи: f (file), d (dir), l (link)
+//   - hidden — скрытый файл; логические поля можно использовать без оператора
+//
+// Выражения объединяются операторами and, or, not и скобками.
+type Query struct {
+	source string
+	root   queryNode
+}
+
+// QueryError описывает синтаксическую ошибку выражения с указанием позиции
+type QueryError struct {
+	Expr string
+	Pos  int // Смещение в байтах от начала выражения
+	Msg  string
+}
+
+// Error возвращает текст ошибки с номером символа (с единицы)
+func (e *QueryError) Error() string {
+	return fmt.Sprintf(i18n.T("query_error"), e.Column(), e.Msg)
+}
+
+// Column возвращает номер символа (с единицы), в котором обнаружена ошибка
+func (e *QueryError) Column() int {
+	pos := e.Pos
+	if pos > len(e.Expr) {
+		pos = len(e.Expr)
+	}
+	return utf8.RuneCountInString(e.Expr[:pos]) + 1
+}
+
+// Caret возвращает выражение и строку с указателем ^ на место ошибки
+func (e *QueryError) Caret() string {
+	return e.Expr + "\n" + strings.Repeat(" ", e.Column()-1) + "^"
+}
+
+// ParseQuery разбирает выражение фильтра
+func ParseQuery(expr string) (*Query, error) {
+	tokens, err := tokenizeQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{expr: expr, tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorf(tok.pos, i18n.T("query_unexpected_token"), tok.text)
+	}
+	return &Query{source: expr, root: root}, nil
+}
+
+// String возвращает исходный текст выражения
+func (q *Query) String() string {
+	return q.source
+}
+
+// References проверяет, используется ли поле в выражении
+func (q *Query) References(field string) bool {
+	return referencesField(q.root, field)
+}
+
+// referencesField рекурсивно ищет сравнение с указанным полем
+func referencesField(node queryNode, field string) bool {
+	switch n := node.(type) {
+	case *andNode:
+		return referencesField(n.left, field) || referencesField(n.right, field)
+	case *orNode:
+		return referencesField(n.left, field) || referencesField(n.right, field)
+	case *notNode:
+		return referencesField(n.operand, field)
+	case *compareNode:
+		return n.field == field
+	}
+	return false
+}
+
+// MatchEntry проверяет, удовлетворяет ли запись директории выражению
+func (q *Query) MatchEntry(entry fs.DirEntry) bool {
+	return q.root.eval(&queryTarget{
+		name:   entry.Name(),
+		isDir:  entry.IsDir(),
+		typ:    entry.Type(),
+		infoFn: entry.Info,
+	})
+}
+
+// MatchInfo проверяет, удовлетворяет ли информация о файле выражению
+func (q *Query) MatchInfo(info fs.FileInfo) bool {
+	return q.root.eval(&queryTarget{
+		name:   info.Name(),
+		isDir:  info.IsDir(),
+		typ:    info.Mode().Type(),
+		infoFn: func() (fs.FileInfo, error) { return info, nil },
+	})
+}
+
+// queryTarget — проверяемая запись; информация о файле загружается только при необходимости
+type queryTarget struct {
+	name   string
+	isDir  bool
+	typ    fs.FileMode
+	infoFn func() (fs.FileInfo, error)
+	info   fs.FileInfo
+	loaded bool
+}
+
+// fileInfo возвращает информацию о файле или nil, если она недоступна
+func (t *queryTarget) fileInfo() fs.FileInfo {
+	if !t.loaded {
+		t.loaded = true
+		info, err := t.infoFn()
+		if err == nil {
+			t.info = info
+		}
+	}
+	return t.info
+}
+
+// --- AST ---
+
+type queryNode interface {
+	eval(t *queryTarget) bool
+}
+
+type andNode struct{ left, right queryNode }
+
+func (n *andNode) eval(t *queryTarget) bool { return n.left.eval(t) && n.right.eval(t) }
+
+type orNode struct{ left, right queryNode }
+
+func (n *orNode) eval(t *queryTarget) bool { return n.left.eval(t) || n.right.eval(t) }
+
+type notNode struct{ operand queryNode }
+
+func (n *notNode) eval(t *queryTarget) bool { return !n.operand.eval(t) }
+
+// compareNode — сравнение поля с одним или несколькими значениями
+type compareNode struct {
+	field  string
+	op     string
+	values []string
+	match  func(t *queryTarget) bool
+}
+
+func (n *compareNode) eval(t *queryTarget) bool { return n.match(t) }
+
+// --- Лексический анализ ---
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type queryToken struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// tokenizeQuery разбивает выражение на лексемы
+func tokenizeQuery(expr string) ([]queryToken, error) {
+	var tokens []queryToken
+	i := 0
+	for i < len(expr) {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(':
+			tokens = append(tokens, queryToken{tokLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, queryToken{tokRParen, ")", i})
+			i++
+		case c == ',':
+			tokens = append(tokens, queryToken{tokComma, ",", i})
+			i++
+		case c == '"' || c == '\'':
+			start := i
+			var sb strings.Builder
+			i++
+			for i < len(expr) && expr[i] != c {
+				if expr[i] == '\\' && i+1 < len(expr) {
+					i++
+				}
+				sb.WriteByte(expr[i])
+				i++
+			}
+			if i >= len(expr) {
+				return nil, &QueryError{Expr: expr, Pos: start, Msg: i18n.T("query_unclosed_string")}
+			}
+			i++
+			tokens = append(tokens, queryToken{tokString, sb.String(), start})
+		case strings.ContainsRune("=!<>~", rune(c)):
+			start := i
+			op := string(c)
+			if i+1 < len(expr) && (expr[i+1] == '=' || c == '!' && expr[i+1] == '~') {
+				op += string(expr[i+1])
+			}
+			if op == "!" {
+				return nil, &QueryError{Expr: expr, Pos: start, Msg: fmt.Sprintf(i18n.T("query_unexpected_token"), op)}
+			}
+			i += len(op)
+			tokens = append(tokens, queryToken{tokOp, op, start})
+		default:
+			start := i
+			for i < len(expr) && !strings.ContainsRune(" \t(),\"'=!<>~", rune(expr[i])) {
+				i++
+			}
+			tokens = append(tokens, queryToken{tokWord, expr[start:i], start})
+		}
+	}
+	tokens = append(tokens, queryToken{tokEOF, "", len(expr)})
+	return tokens, nil
+}
+
+// --- Синтаксический анализ ---
+
+type queryParser struct {
+	expr   string
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() queryToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// isKeyword проверяет, является ли лексема ключевым словом (без учета регистра)
+func isKeyword(tok queryToken, keyword string) bool {
+	return tok.kind == tokWord && strings.EqualFold(tok.text, keyword)
+}
+
+func (p *queryParser) errorf(pos int, format string, args ...interface{}) *QueryError {
+	return &QueryError{Expr: p.expr, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// unexpected формирует ошибку для неожиданной лексемы или конца выражения
+func (p *queryParser) unexpected(tok queryToken) *QueryError {
+	if tok.kind == tokEOF {
+		return p.errorf(tok.pos, "%s", i18n.T("query_unexpected_end"))
+	}
+	return p.errorf(tok.pos, i18n.T("query_unexpected_token"), tok.text)
+}
+
+// parseOr: and_expr { "or" and_expr }
+func (p *queryParser) parseOr() (queryNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for isKeyword(p.peek(), "or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left, right}
+	}
+	return left, nil
+}
+
+// parseAnd: not_expr { "and" not_expr }
+func (p *queryParser) parseAnd() (queryNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for isKeyword(p.peek(), "and") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left, right}
+	}
+	return left, nil
+}
+
+// parseNot: "not" not_expr | primary
+func (p *queryParser) parseNot() (queryNode, error) {
+	if isKeyword(p.peek(), "not") {
+		p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary: "(" expr ")" | comparison
+func (p *queryParser) parsePrimary() (queryNode, error) {
+	tok := p.peek()
+	switch {
+	case tok.kind == tokLParen:
+		p.next()
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, p.errorf(closing.pos, i18n.T("query_expected"), ")")
+		}
+		return node, nil
+	case tok.kind == tokWord && !isKeyword(tok, "and") && !isKeyword(tok, "or") && !isKeyword(tok, "in"):
+		return p.parseComparison()
+	default:
+		return nil, p.unexpected(tok)
+	}
+}
+
+// parseComparison: field op value | field "in" "(" value { "," value } ")" | bool_field
+func (p *queryParser) parseComparison() (queryNode, error) {
+	fieldTok := p.next()
+	field := strings.ToLower(fieldTok.text)
+	spec, ok := queryFields[field]
+	if !ok {
+		return nil, p.errorf(fieldTok.pos, i18n.T("query_unknown_field"), fieldTok.text)
+	}
+
+	opTok := p.peek()
+	node := &compareNode{field: field}
+	var valueToks []queryToken
+
+	switch {
+	case opTok.kind == tokOp:
+		p.next()
+		node.op = opTok.text
+		valueTok := p.next()
+		if valueTok.kind != tokWord && valueTok.kind != tokString {
+			return nil, p.unexpected(valueTok)
+		}
+		valueToks = append(valueToks, valueTok)
+	case isKeyword(opTok, "in"):
+		p.next()
+		node.op = "in"
+		if open := p.next(); open.kind != tokLParen {
+			return nil, p.errorf(open.pos, i18n.T("query_expected"), "(")
+		}
+		for {
+			valueTok := p.next()
+			if valueTok.kind != tokWord && valueTok.kind != tokString {
+				return nil, p.unexpected(valueTok)
+			}
+			valueToks = append(valueToks, valueTok)
+			sep := p.next()
+			if sep.kind == tokRParen {
+				break
+			}
+			if sep.kind != tokComma {
+				return nil, p.errorf(sep.pos, i18n.T("query_expected"), ", )")
+			}
+		}
+	case spec.kind == fieldBool:
+		node.op = "="
+		valueToks = append(valueToks, queryToken{tokWord, "true", opTok.pos})
+	default:
+		return nil, p.errorf(opTok.pos, i18n.T("query_expected_operator"), fieldTok.text)
+	}
+
+	if !spec.allows(node.op) {
+		return nil, p.errorf(opTok.pos, i18n.T("query_invalid_operator"), node.op, field)
+	}
+	for _, tok := range valueToks {
+		node.values = append(node.values, tok.text)
+	}
+
+	match, badIndex, err := spec.compile(node.op, node.values)
+	if err != nil {
+		return nil, p.errorf(valueToks[badIndex].pos, i18n.T("query_invalid_value"), field, err)
+	}
+	node.match = match
+	return node, nil
+}
+
+// --- Поля ---
+
+type fieldKind int
+
+const (
+	fieldString fieldKind = iota
+	fieldSize
+	fieldTime
+	fieldType
+	fieldBool
+)
+
+// queryField описывает поле выражения: допустимые операторы и способ сравнения
+type queryField struct {
+	kind    fieldKind
+	ops     string
+	compile func(op string, values []string) (func(t *queryTarget) bool, int, error)
+}
+
+func (f queryField) allows(op string) bool {
+	for _, allowed := range strings.Fields(f.ops) {
+		if allowed == op {
+			return true
+		}
+	}
+	return false
+}
+
+var queryFields map[string]queryField
+
+func init() {
+	queryFields = map[string]queryField{
+		"name":   {kind: fieldString, ops: "= != ~ !~ in", compile: compileName},
+		"ext":    {kind: fieldString, ops: "= != in", compile: compileExt},
+		"size":   {kind: fieldSize, ops: "= != < <= > >=", compile: compileSize},
+		"mtime":  {kind: fieldTime, ops: "= != < <= > >=", compile: compileMtime},
+		"type":   {kind: fieldType, ops: "= != in", compile: compileType},
+		"hidden": {kind: fieldBool, ops: "= !=", compile: compileBool(func(t *queryTarget) bool { return isHidden(t.name) })},
+	}
+}
+
+// negateIf инвертирует результат для операторов != и !~
+func negateIf(op string, match func(t *queryTarget) bool) func(t *queryTarget) bool {
+	if op == "!=" || op == "!~" {
+		return func(t *queryTarget) bool { return !match(t) }
+	}
+	return match
+}
+
+func compileName(op string, values []string) (func(t *queryTarget) bool, int, error) {
+	if op == "~" || op == "!~" {
+		re, err := regexp.Compile(values[0])
+		if err != nil {
+			return nil, 0, err
+		}
+		return negateIf(op, func(t *queryTarget) bool { return re.MatchString(t.name) }), 0, nil
+	}
+	patterns := make([]*glob.Pattern, len(values))
+	for i, value := range values {
+		pattern, err := glob.Compile(value)
+		if err != nil {
+			return nil, i, err
+		}
+		patterns[i] = pattern
+	}
+	return negateIf(op, func(t *queryTarget) bool {
+		for _, pattern := range patterns {
+			if pattern.Match(t.name) {
+				return true
+			}
+		}
+		return false
+	}), 0, nil
+}
+
+func compileExt(op string, values []string) (func(t *queryTarget) bool, int, error) {
+	exts := make([]string, len(values))
+	for i, value := range values {
+		exts[i] = strings.ToLower(strings.TrimPrefix(value, "."))
+	}
+	return negateIf(op, func(t *queryTarget) bool {
+		if t.isDir {
+			return false
+		}
+		ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(t.name), "."))
+		for _, allowed := range exts {
+			if ext == allowed {
+				return true
+			}
+		}
+		return false
+	}), 0, nil
+}
+
+// compareOrdered сравнивает два значения согласно оператору
+func compareOrdered[T int64 | time.Duration](a T, op string, b T) bool {
+	switch op {
+	case "=":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
+
+func compileSize(op string, values []string) (func(t *queryTarget) bool, int, error) {
+	size, err := ParseSize(values[0])
+	if err != nil {
+		return nil, 0, err
+	}
+	return func(t *queryTarget) bool {
+		if t.isDir {
+			return false
+		}
+		info := t.fileInfo()
+		return info != nil && compareOrdered(info.Size(), op, size)
+	}, 0, nil
+}
+
+func compileMtime(op string, values []string) (func(t *queryTarget) bool, int, error) {
+	if age, err := ParseAge(values[0]); err == nil {
+		if op == "=" || op == "!=" {
+			return nil, 0, fmt.Errorf(i18n.T("query_age_equality"), values[0])
+		}
+		return func(t *queryTarget) bool {
+			info := t.fileInfo()
+			return info != nil && compareOrdered(time.Since(info.ModTime()), op, age)
+		}, 0, nil
+	}
+
+	day, ok := ParseDay(values[0], time.Now())
+	if !ok {
+		return nil, 0, fmt.Errorf(i18n.T("query_invalid_time"), values[0])
+	}
+	start, end := day, day.AddDate(0, 0, 1)
+	return func(t *queryTarget) bool {
+		info := t.fileInfo()
+		if info == nil {
+			return false
+		}
+		mt := info.ModTime()
+		switch op {
+		case "<":
+			return mt.Before(start)
+		case "<=":
+			return mt.Before(end)
+		case ">":
+			return !mt.Before(end)
+		case ">=":
+			return !mt.Before(start)
+		case "=":
+			return !mt.Before(start) && mt.Before(end)
+		default:
+			return mt.Before(start) || !mt.Before(end)
+		}
+	}, 0, nil
+}
+
+func compileType(op string, values []string) (func(t *queryTarget) bool, int, error) {
+	var checks []func(t *queryTarget) bool
+	for i, value := range values {
+		switch strings.ToLower(value) {
+		case "f", "file":
+			checks = append(checks, func(t *queryTarget) bool { return t.typ.IsRegular() })
+		case "d", "dir":
+			checks = append(checks, func(t *queryTarget) bool { return t.isDir })
+		case "l", "link":
+			checks = append(checks, func(t *queryTarget) bool { return t.typ&fs.ModeSymlink != 0 })
+		default:
+			return nil, i, fmt.Errorf(i18n.T("query_invalid_type"), value)
+		}
+	}
+	return negateIf(op, func(t *queryTarget) bool {
+		for _, check := range checks {
+			if check(t) {
+				return true
+			}
+		}
+		return false
+	}), 0, nil
+}
+
+// compileBool создает компилятор для логического поля
+func compileBool(predicate func(t *queryTarget) bool) func(op string, values []string) (func(t *queryTarget) bool, int, error) {
+	return func(op string, values []string) (func(t *queryTarget) bool, int, error) {
+		var want bool
+		switch strings.ToLower(values[0]) {
+		case "true", "yes", "1":
+			want = true
+		case "false", "no", "0":
+			want = false
+		default:
+			return nil, 0, fmt.Errorf(i18n.T("query_invalid_bool"), values[0])
+		}
+		if op == "!=" {
+			want = !want
+		}
+		return func(t *queryTarget) bool { return predicate(t) == want }, 0, nil
+	}
+}
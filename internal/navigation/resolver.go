@@ -0,0 +1,183 @@
+package navigation
+
+import (
+	"file-manager/internal/i18n"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// BookmarkLookup возвращает путь закладки по ее имени
+type BookmarkLookup interface {
+	GetBookmarkPath(name string) (string, error)
+}
+
+// ExpandPath разворачивает сокращения в пути и возвращает абсолютный путь.
+// Поддерживаются:
+//   - ~ и ~/путь — домашняя директория текущего пользователя
+//   - ~user и ~user/путь — домашняя директория пользователя user
+//   - @закладка и @закладка/путь — путь относительно закладки
+//   - $VAR и ${VAR} — переменные окружения
+//
+// Относительные пути разрешаются относительно cwd, цепочки ".." сворачиваются.
+// Если сокращение не удалось развернуть (нет такой закладки, переменной или пользователя),
+// но путь существует буквально, например файл @types, возвращается буквальный путь.
+func ExpandPath(path, cwd string, bookmarks BookmarkLookup) (string, error) {
+	expanded, err := expandShorthands(path, cwd, bookmarks)
+	if err != nil {
+		literal := path
+		if !filepath.IsAbs(literal) {
+			literal = filepath.Join(cwd, literal)
+		}
+		if _, statErr := os.Lstat(literal); statErr == nil {
+			return filepath.Clean(literal), nil
+		}
+		return "", err
+	}
+	return expanded, nil
+}
+
+// expandShorthands разворачивает сокращения в пути без учета буквальных имен
+func expandShorthands(path, cwd string, bookmarks BookmarkLookup) (string, error) {
+	if path == "" {
+		return filepath.Clean(cwd), nil
+	}
+
+	base := ""
+	rest := path
+	switch path[0] {
+	case '~':
+		name, tail := splitFirstComponent(path[1:])
+		home, err := homeDir(name)
+		if err != nil {
+			return "", err
+		}
+		base, rest = home, tail
+	case '@':
+		name, tail := splitFirstComponent(path[1:])
+		if name == "" {
+			return "", fmt.Errorf(i18n.T("path_bookmark_empty"), path)
+		}
+		if bookmarks == nil {
+			return "", fmt.Errorf(i18n.T("bm_not_found"), name)
+		}
+		bookmarkPath, err := bookmarks.GetBookmarkPath(name)
+		if err != nil {
+			return "", err
+		}
+		base, rest = bookmarkPath, tail
+	}
+
+	expanded, err := expandEnv(rest)
+	if err != nil {
+		return "", err
+	}
+
+	if base != "" {
+		return filepath.Join(base, expanded), nil
+	}
+	if filepath.IsAbs(expanded) {
+		return filepath.Clean(expanded), nil
+	}
+	return filepath.Join(cwd, expanded), nil
+}
+
+// splitFirstComponent отделяет первый компонент пути от остатка
+func splitFirstComponent(path string) (string, string) {
+	index := strings.IndexAny(path, `/`+string(filepath.Separator))
+	if index < 0 {
+		return path, ""
+	}
+	return path[:index], path[index+1:]
+}
+
+// homeDir возвращает домашнюю директорию пользователя (текущего, если имя пустое)
+func homeDir(name string) (string, error) {
+	if name == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf(i18n.T("bm_home"), err)
+		}
+		return home, nil
+	}
+	u, err := user.Lookup(name)
+	if err != nil {
+		return "", fmt.Errorf(i18n.T("path_user_not_found"), name, err)
+	}
+	return u.HomeDir, nil
+}
+
+// expandEnv подставляет значения переменных окружения $VAR и ${VAR}.
+// Символ $, за которым не следует имя переменной, остается как есть.
+func expandEnv(path string) (string, error) {
+	if !strings.Contains(path, "$") {
+		return path, nil
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] != '$' || i+1 >= len(path) {
+			sb.WriteByte(path[i])
+			continue
+		}
+
+		var name string
+		next := i + 1
+		if path[next] == '{' {
+			end := strings.IndexByte(path[next:], '}')
+			if end < 0 {
+				return "", fmt.Errorf(i18n.T("path_env_unclosed"), path)
+			}
+			name = path[next+1 : next+end]
+			if !isEnvName(name) {
+				return "", fmt.Errorf(i18n.T("path_env_invalid"), name)
+			}
+			i = next + end
+		} else {
+			end := next
+			for end < len(path) && isEnvNameChar(path[end], end == next) {
+				end++
+			}
+			if end == next {
+				sb.WriteByte('$')
+				continue
+			}
+			name = path[next:end]
+			i = end - 1
+		}
+
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf(i18n.T("path_env_undefined"), name)
+		}
+		sb.WriteString(value)
+	}
+	return sb.String(), nil
+}
+
+// isEnvName проверяет, является ли строка допустимым именем переменной окружения
+func isEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !isEnvNameChar(name[i], i == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// isEnvNameChar проверяет, может ли символ входить в имя переменной окружения
+func isEnvNameChar(c byte, first bool) bool {
+	switch {
+	case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		return true
+	case c >= '0' && c <= '9':
+		return !first
+	default:
+		return false
+	}
+}
@@ -0,0 +1,134 @@
+package navigation
+
+import (
+	"errors"
+	"file-manager/internal/i18n"
+	"fmt"
+)
+
+// Tab представляет вкладку файлового менеджера с собственной текущей директорией,
+// фильтром и историей переходов
+type Tab struct {
+	Navigator *Navigator
+	Filter    *FilterOptions
+
+	// FilterLabel — имя сохраненного фильтра, примененного во вкладке (пусто для заданного вручную)
+	FilterLabel string
+
+	// PinnedDir — директория закрепления, в дереве которой находится вкладка
+	PinnedDir string
+
+	// filterPinned — фильтр вкладки установлен закреплением и снимается при выходе из его дерева
+	filterPinned bool
+}
+
+// SetFilter устанавливает фильтр вкладки
+func (t *Tab) SetFilter(options *FilterOptions, label string) {
+	t.Filter = options
+	t.FilterLabel = label
+	t.filterPinned = false
+}
+
+// UsePin устанавливает во вкладке закрепленный фильтр
+func (t *Tab) UsePin(pin *PinnedFilter) {
+	t.Filter = pin.Options.Clone()
+	t.FilterLabel = i18n.T("filter_pinned_label")
+	t.PinnedDir = pin.Dir
+	t.filterPinned = true
+}
+
+// ApplyPin применяет закрепленный фильтр при входе в его дерево директорий и снимает
+// его при выходе. pin — закрепление, действующее для текущей директории, или nil.
+// Фильтр, заданный вручную внутри дерева, сохраняется до перехода в другое дерево.
+func (t *Tab) ApplyPin(pin *PinnedFilter) {
+	dir := ""
+	if pin != nil {
+		dir = pin.Dir
+	}
+	if dir == t.PinnedDir {
+		return
+	}
+	switch {
+	case pin != nil:
+		t.UsePin(pin)
+	case t.filterPinned:
+		t.SetFilter(NewFilterOptions(), "")
+	}
+	t.PinnedDir = dir
+}
+
+// TabManager управляет набором вкладок и активной вкладкой
+type TabManager struct {
+	tabs   []*Tab
+	active int
+}
+
+// NewTabManager создает менеджер вкладок с одной вкладкой для указанного навигатора
+func NewTabManager(navigator *Navigator) *TabManager {
+	return &TabManager{
+		tabs: []*Tab{{
+			Navigator: navigator,
+			Filter:    NewFilterOptions(),
+		}},
+	}
+}
+
+// Active возвращает активную вкладку
+func (tm *TabManager) Active() *Tab {
+	return tm.tabs[tm.active]
+}
+
+// ActiveIndex возвращает индекс активной вкладки (с нуля)
+func (tm *TabManager) ActiveIndex() int {
+	return tm.active
+}
+
+// Tabs возвращает список всех вкладок
+func (tm *TabManager) Tabs() []*Tab {
+	return tm.tabs
+}
+
+// Count возвращает количество открытых вкладок
+func (tm *TabManager) Count() int {
+	return len(tm.tabs)
+}
+
+// NewTab открывает новую вкладку в указанной директории и делает ее активной
+func (tm *TabManager) NewTab(dir string) (*Tab, error) {
+	navigator, err := NewNavigatorAt(dir)
+	if err != nil {
+		return nil, err
+	}
+	tab := &Tab{
+		Navigator: navigator,
+		Filter:    NewFilterOptions(),
+	}
+	tm.tabs = append(tm.tabs, tab)
+	tm.active = len(tm.tabs) - 1
+	return tab, nil
+}
+
+// Switch делает активной вкладку с указанным индексом (с нуля)
+func (tm *TabManager) Switch(index int) error {
+	if index < 0 || index >= len(tm.tabs) {
+		return fmt.Errorf(i18n.T("tab_not_found"), index+1)
+	}
+	tm.active = index
+	return nil
+}
+
+// Close закрывает вкладку с указанным индексом (с нуля).
+// Последнюю оставшуюся вкладку закрыть нельзя.
+func (tm *TabManager) Close(index int) error {
+	if index < 0 || index >= len(tm.tabs) {
+		return fmt.Errorf(i18n.T("tab_not_found"), index+1)
+	}
+	if len(tm.tabs) == 1 {
+		return errors.New(i18n.T("tab_close_last"))
+	}
+	tm.tabs = append(tm.tabs[:index], tm.tabs[index+1:]...)
+	if tm.active > index || tm.active >= len(tm.tabs) {
+		tm.active--
+	}
+	return nil
+}
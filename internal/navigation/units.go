@@ -0,0 +1,239 @@
+package navigation
+
+import (
+	"file-manager/internal/i18n"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// sizeUnits содержит множители для суффиксов размера (без учета регистра).
+// Короткие суффиксы K, M, G, T и суффиксы KiB, MiB... — двоичные, KB, MB... — десятичные.
+var sizeUnits = map[string]int64{
+	"":    1,
+	"B":   1,
+	"K":   1 << 10,
+	"M":   1 << 20,
+	"G":   1 << 30,
+	"T":   1 << 40,
+	"KIB": 1 << 10,
+	"MIB": 1 << 20,
+	"GIB": 1 << 30,
+	"TIB": 1 << 40,
+	"KB":  1e3,
+	"MB":  1e6,
+	"GB":  1e9,
+	"TB":  1e12,
+}
+
+// ParseSize разбирает размер в байтах с необязательным суффиксом: 512, 10K, 1.5MiB, 2GB
+func ParseSize(s string) (int64, error) {
+	number, unit := splitNumberUnit(s)
+	multiplier, ok := sizeUnits[strings.ToUpper(unit)]
+	if number == "" || !ok {
+		return 0, fmt.Errorf(i18n.T("units_invalid_size"), s)
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil || value*float64(multiplier) > math.MaxInt64 {
+		return 0, fmt.Errorf(i18n.T("units_invalid_size"), s)
+	}
+	return int64(math.Round(value * float64(multiplier))), nil
+}
+
+// ParseSizeRange разбирает диапазон размеров и возвращает границы включительно
+// (-1 — граница не задана). Допустимые формы: >100M, >=1K, <2G, <=10K, 1K..1M, 1K-1M, ..1M, 1K..
+// и точный размер без оператора.
+func ParseSizeRange(s string) (int64, int64, error) {
+	s = strings.TrimSpace(s)
+	for _, op := range []string{">=", "<=", ">", "<"} {
+		if !strings.HasPrefix(s, op) {
+			continue
+		}
+		size, err := ParseSize(s[len(op):])
+		if err != nil {
+			return 0, 0, err
+		}
+		switch op {
+		case ">=":
+			return size, -1, nil
+		case ">":
+			return size + 1, -1, nil
+		case "<=":
+			return -1, size, nil
+		default:
+			if size == 0 {
+				return 0, 0, fmt.Errorf(i18n.T("units_empty_range"), s)
+			}
+			return -1, size - 1, nil
+		}
+	}
+
+	from, to, isRange := strings.Cut(s, "..")
+	if !isRange {
+		from, to, isRange = strings.Cut(s, "-")
+	}
+	if !isRange {
+		size, err := ParseSize(s)
+		if err != nil {
+			return 0, 0, err
+		}
+		return size, size, nil
+	}
+	if from == "" && to == "" {
+		return 0, 0, fmt.Errorf(i18n.T("units_invalid_range"), s)
+	}
+
+	minSize, maxSize := int64(-1), int64(-1)
+	var err error
+	if from != "" {
+		if minSize, err = ParseSize(from); err != nil {
+			return 0, 0, err
+		}
+	}
+	if to != "" {
+		if maxSize, err = ParseSize(to); err != nil {
+			return 0, 0, err
+		}
+	}
+	if minSize >= 0 && maxSize >= 0 && minSize > maxSize {
+		return 0, 0, fmt.Errorf(i18n.T("units_range_order"), s)
+	}
+	return minSize, maxSize, nil
+}
+
+// ageUnits содержит длительности для суффиксов возраста
+var ageUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+	"y": 365 * 24 * time.Hour,
+}
+
+// ParseAge разбирает возраст вида 30m, 12h, 7d, 2w или 1y
+func ParseAge(s string) (time.Duration, error) {
+	number, unit := splitNumberUnit(s)
+	multiplier, ok := ageUnits[unit]
+	if number == "" || !ok {
+		return 0, fmt.Errorf(i18n.T("units_invalid_age"), s)
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf(i18n.T("units_invalid_age"), s)
+	}
+	return time.Duration(value * float64(multiplier)), nil
+}
+
+// dateLayout — формат абсолютной даты в фильтрах
+const dateLayout = "2006-01-02"
+
+// ParseDay разбирает календарный день: YYYY-MM-DD, today или yesterday.
+// Возвращает начало дня в локальном часовом поясе.
+func ParseDay(s string, now time.Time) (time.Time, bool) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(s) {
+	case "today":
+		return today, true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true
+	}
+	day, err := time.ParseInLocation(dateLayout, s, now.Location())
+	if err != nil {
+		return time.Time{}, false
+	}
+	return day, true
+}
+
+// parseDateBound разбирает границу диапазона дат: день (YYYY-MM-DD, today, yesterday)
+// или возраст (7d, 2w). Для верхней границы день означает его конец.
+func parseDateBound(s string, now time.Time, upper bool) (time.Time, error) {
+	if day, ok := ParseDay(s, now); ok {
+		if upper {
+			return day.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+		}
+		return day, nil
+	}
+	if age, err := ParseAge(s); err == nil {
+		return now.Add(-age), nil
+	}
+	return time.Time{}, fmt.Errorf(i18n.T("units_invalid_date"), s)
+}
+
+// ParseDateRange разбирает диапазон дат изменения и возвращает его границы
+// (нулевое время — граница не задана). Допустимые формы:
+// 2024-01-01..2024-02-01, 7d (за последние 7 дней), yesterday (весь вчерашний день),
+// 2024-01-01.., ..2w (старше двух недель) и устаревшая форма 2024-01-01-2024-02-01.
+func ParseDateRange(s string, now time.Time) (time.Time, time.Time, error) {
+	s = strings.TrimSpace(s)
+	from, to, isRange := strings.Cut(s, "..")
+	if !isRange && len(s) == 2*len(dateLayout)+1 && s[len(dateLayout)] == '-' {
+		from, to, isRange = s[:len(dateLayout)], s[len(dateLayout)+1:], true
+	}
+
+	if !isRange {
+		if day, ok := ParseDay(s, now); ok {
+			return day, day.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+		}
+		if age, err := ParseAge(s); err == nil {
+			return now.Add(-age), time.Time{}, nil
+		}
+		return time.Time{}, time.Time{}, fmt.Errorf(i18n.T("units_invalid_date"), s)
+	}
+	if from == "" && to == "" {
+		return time.Time{}, time.Time{}, fmt.Errorf(i18n.T("units_invalid_range"), s)
+	}
+
+	var after, before time.Time
+	var err error
+	if from != "" {
+		if after, err = parseDateBound(from, now, false); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+	if to != "" {
+		if before, err = parseDateBound(to, now, true); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+	if !after.IsZero() && !before.IsZero() && after.After(before) {
+		return time.Time{}, time.Time{}, fmt.Errorf(i18n.T("units_range_order"), s)
+	}
+	return after, before, nil
+}
+
+// splitNumberUnit отделяет числовую часть строки от буквенного суффикса
+func splitNumberUnit(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	i := 0
+	for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.') {
+		i++
+	}
+	return s[:i], strings.TrimSpace(s[i:])
+}
+
+// ParseCountRange разбирает диапазон неотрицательных целых чисел (например, число жестких ссылок)
+// в тех же формах, что и ParseSizeRange: 2, >1, <=3, 2..5, 2.., ..5.
+// Возвращает границы включительно, 0 — граница не задана.
+func ParseCountRange(s string) (int64, int64, error) {
+	// Суффиксы единиц и дробные числа для количества недопустимы
+	if strings.IndexFunc(s, unicode.IsLetter) >= 0 || strings.Contains(strings.ReplaceAll(s, "..", ""), ".") {
+		return 0, 0, fmt.Errorf(i18n.T("units_invalid_count"), s)
+	}
+	minCount, maxCount, err := ParseSizeRange(s)
+	if err != nil {
+		return 0, 0, fmt.Errorf(i18n.T("units_invalid_count"), s)
+	}
+	if minCount < 0 {
+		minCount = 0
+	}
+	if maxCount < 0 {
+		maxCount = 0
+	} else if maxCount == 0 {
+		return 0, 0, fmt.Errorf(i18n.T("units_empty_range"), s)
+	}
+	return minCount, maxCount, nil
+}
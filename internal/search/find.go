@@ -0,0 +1,232 @@
+package search
+
+import (
+	"file-manager/internal/glob"
+	"file-manager/internal/i18n"
+	"file-manager/internal/ignore"
+	"file-manager/internal/sysinfo"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FindOptions управляет обходом дерева при поиске
+type FindOptions struct {
+	// MinDepth — минимальная глубина найденных записей (1 — содержимое корня)
+	MinDepth int
+	// MaxDepth — максимальная глубина обхода (-1 — без ограничения)
+	MaxDepth int
+	// Prune — шаблоны имен (или путей относительно корня) директорий, в которые не нужно заходить
+	Prune []string
+	// Follow — переходить по символическим ссылкам на директории
+	Follow bool
+	// SameFilesystem — не выходить за пределы файловой системы корня
+	SameFilesystem bool
+}
+
+// DefaultFindOptions возвращает параметры обхода без ограничений
+func DefaultFindOptions() FindOptions {
+	return FindOptions{MinDepth: 1, MaxDepth: -1}
+}
+
+// MatchFunc проверяет запись, найденную при обходе; path — полный путь к записи
+type MatchFunc func(path string, entry fs.DirEntry) bool
+
+// Find обходит дерево root и возвращает пути записей, для которых match возвращает true.
+// Корневая директория в результаты не включается, недоступные директории пропускаются.
+// При включенном RespectIgnore записи, исключенные ignore-файлами, не обходятся.
+func (s *Searcher) Find(root string, options FindOptions, match MatchFunc) ([]string, error) {
+	prune := make([]*glob.Pattern, 0, len(options.Prune))
+	for _, pattern := range options.Prune {
+		compiled, err := glob.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		prune = append(prune, compiled)
+	}
+
+	rootInfo, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("search_files"), err)
+	}
+
+	// Корень-ссылку обходим по ее цели, но показываем пути относительно исходного корня
+	target, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("search_files"), err)
+	}
+
+	f := &finder{options: options, match: match, prune: prune, root: root}
+	if stat, ok := sysinfo.Stat(rootInfo); ok {
+		f.rootDev = stat.Dev
+	}
+	if s.RespectIgnore {
+		f.ignore = ignore.NewMatcher(target)
+	}
+	if err := f.walk(target, root, 0); err != nil {
+		return nil, fmt.Errorf(i18n.T("search_files"), err)
+	}
+	return f.matches, nil
+}
+
+// finder хранит состояние одного обхода дерева
+type finder struct {
+	options FindOptions
+	match   MatchFunc
+	prune   []*glob.Pattern
+	root    string
+	matches []string
+	rootDev uint64
+	ignore  *ignore.Matcher
+	// active — директории на текущем пути обхода от корня, включая цели пройденных ссылок;
+	// переход по ссылке на одну из них создал бы цикл
+	active []dirKey
+}
+
+// dirKey идентифицирует директорию по устройству и номеру inode
+type dirKey struct {
+	dev, ino uint64
+	// known — идентификатор доступен (на Windows его нет)
+	known bool
+}
+
+// keyOf возвращает идентификатор директории по ее сведениям
+func keyOf(info fs.FileInfo) dirKey {
+	stat, ok := sysinfo.Stat(info)
+	return dirKey{dev: stat.Dev, ino: stat.Ino, known: ok}
+}
+
+// statKey возвращает идентификатор директории path; если он недоступен, поле known равно false
+func statKey(path string) dirKey {
+	info, err := os.Stat(path)
+	if err != nil {
+		return dirKey{}
+	}
+	return keyOf(info)
+}
+
+// onPath проверяет, находится ли директория key на текущем пути обхода
+func (f *finder) onPath(key dirKey) bool {
+	for _, active := range f.active {
+		if active.known && active == key {
+			return true
+		}
+	}
+	return false
+}
+
+// walk обходит директорию dir, подставляя в найденные пути префикс display вместо dir
+// (они различаются при переходе по символической ссылке). depth — глубина dir относительно корня.
+func (f *finder) walk(dir, display string, depth int) error {
+	// Уровни вложенности этого обхода начинаются с индекса base в f.active
+	base := len(f.active)
+	defer func() { f.active = f.active[:base] }()
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if path == dir {
+			// При ошибке чтения WalkDir вызывает функцию для корня повторно
+			if err == nil {
+				f.active = append(f.active, statKey(dir))
+			} else if depth > 0 {
+				return nil // Недоступную цель ссылки пропускаем, как и другие директории
+			}
+			return err
+		}
+		if err != nil {
+			return nil // Пропускаем файлы, к которым нет доступа
+		}
+
+		rel := strings.TrimPrefix(path, dir+string(filepath.Separator))
+		shown := filepath.Join(display, rel)
+		level := strings.Count(rel, string(filepath.Separator)) + 1
+		entryDepth := depth + level
+		// WalkDir обходит дерево в глубину, поэтому на пути остаются только предки записи
+		if len(f.active) > base+level {
+			f.active = f.active[:base+level]
+		}
+
+		isLink := entry.Type()&fs.ModeSymlink != 0
+		var target fs.FileInfo
+		if f.options.Follow && isLink {
+			if info, err := os.Stat(path); err == nil {
+				target = info
+				entry = fs.FileInfoToDirEntry(info)
+			}
+		}
+
+		if entry.IsDir() && f.pruned(shown) {
+			return filepath.SkipDir
+		}
+		if f.ignore != nil && f.ignore.Ignored(path, entry.IsDir()) {
+			if entry.IsDir() && !isLink {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entryDepth >= f.options.MinDepth && f.match(shown, entry) {
+			f.matches = append(f.matches, shown)
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+
+		descend := f.options.MaxDepth < 0 || entryDepth < f.options.MaxDepth
+		if descend && f.options.SameFilesystem {
+			descend = f.sameFilesystem(path)
+		}
+		if isLink {
+			// WalkDir не заходит в ссылки, поэтому обходим цель отдельно
+			if !descend {
+				return nil
+			}
+			resolved, err := filepath.EvalSymlinks(path)
+			if err != nil || f.onPath(keyOf(target)) || isCycle(path, resolved) {
+				return nil
+			}
+			return f.walk(resolved, shown, entryDepth)
+		}
+		if !descend {
+			return filepath.SkipDir
+		}
+		f.active = append(f.active, statKey(path))
+		return nil
+	})
+}
+
+// pruned проверяет, нужно ли пропустить директорию: шаблон без / сравнивается с ее именем,
+// шаблон с / — с путем относительно корня поиска
+func (f *finder) pruned(shown string) bool {
+	rel, err := filepath.Rel(f.root, shown)
+	if err != nil {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range f.prune {
+		if pattern.MatchPath(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameFilesystem проверяет, находится ли директория на той же файловой системе, что и корень
+func (f *finder) sameFilesystem(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	stat, ok := sysinfo.Stat(info)
+	return !ok || stat.Dev == f.rootDev
+}
+
+// isCycle проверяет, ведет ли ссылка path на одну из директорий, в которых она сама находится.
+// Нужна там, где у директорий нет inode и onPath не срабатывает.
+func isCycle(path, target string) bool {
+	parent, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return true
+	}
+	rel, err := filepath.Rel(target, parent)
+	return err == nil && (rel == "." || rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
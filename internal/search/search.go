@@ -3,8 +3,11 @@ package search
 
 import (
 	"bufio"
+	"file-manager/internal/glob"
 	"file-manager/internal/i18n"
+	"file-manager/internal/ignore"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -12,29 +15,65 @@ import (
 )
 
 // Searcher предоставляет функции для поиска файлов и содержимого
-type Searcher struct{}
+type Searcher struct {
+	// RespectIgnore — пропускать записи, исключенные правилами .gitignore, .ignore, .fmignore
+	// и глобального файла ~/.filemanager/ignore
+	RespectIgnore bool
+}
 
 // NewSearcher создает новый экземпляр Searcher
 func NewSearcher() *Searcher {
 	return &Searcher{}
 }
 
-// SearchByName ищет файлы по шаблону имени
+// WithIgnore возвращает копию Searcher с указанным режимом учета ignore-файлов
+func (s *Searcher) WithIgnore(respect bool) *Searcher {
+	searcher := *s
+	searcher.RespectIgnore = respect
+	return &searcher
+}
+
+// walkDir обходит дерево как filepath.WalkDir, пропуская записи, исключенные ignore-файлами,
+// если включен RespectIgnore
+func (s *Searcher) walkDir(root string, fn fs.WalkDirFunc) error {
+	if !s.RespectIgnore {
+		return filepath.WalkDir(root, fn)
+	}
+	rules := ignore.NewMatcher(root)
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && rules.Ignored(path, entry.IsDir()) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		return fn(path, entry, err)
+	})
+}
+
+// SearchByName ищет файлы по шаблону имени. Шаблон без / сравнивается с именем записи,
+// шаблон с / (например, src/**/*.go) — с путем относительно root.
 func (s *Searcher) SearchByName(root, pattern string) ([]string, error) {
 	var matches []string
 
-	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+	compiled, err := glob.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("search_files"), err)
+	}
 
-		// Проверяем, соответствует ли имя файла шаблону
-		matched, err := filepath.Match(pattern, info.Name())
+	err = s.walkDir(root, func(path string, entry fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
-		if matched {
+		// Проверяем, соответствует ли имя файла (или путь) шаблону
+		rel := entry.Name()
+		if path != root {
+			if rel, err = filepath.Rel(root, path); err != nil {
+				return err
+			}
+		}
+		if compiled.MatchPath(filepath.ToSlash(rel)) {
 			matches = append(matches, path)
 		}
 
@@ -53,16 +92,21 @@ func (s *Searcher) SearchByContent(root, content string) ([]string, error) {
 	var matches []string
 	var processedFilesMap = make(map[string]bool)
 
-	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+	err := s.walkDir(root, func(path string, entry fs.DirEntry, err error) error {
 		if err != nil {
 			return nil // Пропускаем файлы, к которым нет доступа
 		}
 
 		// Пропускаем директории
-		if info.IsDir() {
+		if entry.IsDir() {
 			return nil
 		}
 
+		info, err := entry.Info()
+		if err != nil {
+			return nil // Пропускаем файлы, к которым нет доступа
+		}
+
 		// Пропускаем слишком большие файлы (более 10 МБ)
 		if info.Size() > 10*1024*1024 {
 			return nil
@@ -120,16 +164,21 @@ func (s *Searcher) SearchByRegex(root, regexPattern string) ([]string, error) {
 		return nil, fmt.Errorf(i18n.T("invalid_regex_pattern"), err)
 	}
 
-	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+	err = s.walkDir(root, func(path string, entry fs.DirEntry, err error) error {
 		if err != nil {
 			return nil // Пропускаем файлы, к которым нет доступа
 		}
 
 		// Пропускаем директории
-		if info.IsDir() {
+		if entry.IsDir() {
 			return nil
 		}
 
+		info, err := entry.Info()
+		if err != nil {
+			return nil // Пропускаем файлы, к которым нет доступа
+		}
+
 		// Пропускаем слишком большие файлы (более 10 МБ)
 		if info.Size() > 10*1024*1024 {
 			return nil
@@ -175,3 +224,11 @@ func (s *Searcher) SearchByRegex(root, regexPattern string) ([]string, error) {
 
 	return matches, nil
 }
+
+// SearchByPredicate ищет файлы и директории, для которых функция match возвращает true.
+// Корневая директория в результаты не включается, недоступные директории пропускаются.
+func (s *Searcher) SearchByPredicate(root string, match func(entry fs.DirEntry) bool) ([]string, error) {
+	return s.Find(root, DefaultFindOptions(), func(_ string, entry fs.DirEntry) bool {
+		return match(entry)
+	})
+}
@@ -2,9 +2,11 @@ package search
 
 import (
 	"file-manager/internal/i18n"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -73,6 +75,9 @@ func TestSearchByName(t *testing.T) {
 		{"ПоискНесуществующихФайлов", "*.xyz", 0},
 		{"ПоискПоЧастиИмени", "file[1-3].*", 3},
 		{"ПоискСпециальныхФайлов", "*special*", 1},
+		{"ПоискСАльтернативами", "*.{log,conf}", 2},
+		{"ПоискПоПути", "subdir/*.txt", 1},
+		{"ПоискНаЛюбойГлубине", "**/file[35].txt", 2},
 	}
 
 	for _, tt := range tests {
@@ -241,3 +246,251 @@ func TestSearchByContent_BigFileAndPermission(t *testing.T) {
 		}
 	}
 }
+
+func TestSearchByPredicate(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	searcher := NewSearcher()
+
+	t.Run("Отбор по функции", func(t *testing.T) {
+		results, err := searcher.SearchByPredicate(tempDir, func(entry fs.DirEntry) bool {
+			return !entry.IsDir() && filepath.Ext(entry.Name()) == ".txt"
+		})
+		if err != nil {
+			t.Fatalf("ошибка при поиске: %v", err)
+		}
+		if len(results) != 4 {
+			t.Errorf("ожидалось 4 файла .txt, получено %d: %v", len(results), results)
+		}
+	})
+
+	t.Run("Корневая директория не включается", func(t *testing.T) {
+		results, err := searcher.SearchByPredicate(tempDir, func(entry fs.DirEntry) bool {
+			return entry.IsDir()
+		})
+		if err != nil {
+			t.Fatalf("ошибка при поиске: %v", err)
+		}
+		if len(results) != 2 {
+			t.Errorf("ожидалось 2 поддиректории, получено %d: %v", len(results), results)
+		}
+	})
+
+	t.Run("Несуществующая директория", func(t *testing.T) {
+		_, err := searcher.SearchByPredicate(filepath.Join(tempDir, "not_exists"), func(fs.DirEntry) bool { return true })
+		if err == nil {
+			t.Error("ожидалась ошибка при поиске в несуществующей директории")
+		}
+	})
+}
+
+func TestFind(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	searcher := NewSearcher()
+	all := func(string, fs.DirEntry) bool { return true }
+	relative := func(results []string) string {
+		var names []string
+		for _, r := range results {
+			rel, _ := filepath.Rel(tempDir, r)
+			names = append(names, filepath.ToSlash(rel))
+		}
+		return strings.Join(names, ",")
+	}
+
+	t.Run("Ограничение глубины", func(t *testing.T) {
+		options := DefaultFindOptions()
+		options.MaxDepth = 1
+		results, err := searcher.Find(tempDir, options, func(_ string, e fs.DirEntry) bool { return e.IsDir() })
+		if err != nil {
+			t.Fatalf("ошибка при поиске: %v", err)
+		}
+		if got := relative(results); got != "subdir,subdir2" {
+			t.Errorf("получено %s", got)
+		}
+
+		options = DefaultFindOptions()
+		options.MinDepth = 2
+		results, err = searcher.Find(tempDir, options, all)
+		if err != nil {
+			t.Fatalf("ошибка при поиске: %v", err)
+		}
+		if len(results) != 4 {
+			t.Errorf("ожидалось 4 записи на глубине 2, получено %v", results)
+		}
+	})
+
+	t.Run("Пропуск директорий", func(t *testing.T) {
+		options := DefaultFindOptions()
+		options.Prune = []string{"subdir*"}
+		results, err := searcher.Find(tempDir, options, all)
+		if err != nil {
+			t.Fatalf("ошибка при поиске: %v", err)
+		}
+		if got := relative(results); got != "file1.txt,file2.log,file7_special.txt" {
+			t.Errorf("получено %s", got)
+		}
+
+		options.Prune = []string{`*\`}
+		if _, err := searcher.Find(tempDir, options, all); err == nil {
+			t.Error("ожидалась ошибка для некорректного шаблона")
+		}
+	})
+
+	t.Run("Символические ссылки", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("Символические ссылки требуют прав администратора на Windows")
+		}
+		// Ссылка на родительскую директорию создает цикл
+		if err := os.Symlink("..", filepath.Join(tempDir, "subdir", "loop")); err != nil {
+			t.Fatalf("не удалось создать ссылку: %v", err)
+		}
+		if err := os.Symlink(filepath.Join(tempDir, "subdir2"), filepath.Join(tempDir, "link2")); err != nil {
+			t.Fatalf("не удалось создать ссылку: %v", err)
+		}
+		files := func(_ string, e fs.DirEntry) bool { return e.Type().IsRegular() }
+
+		results, err := searcher.Find(tempDir, DefaultFindOptions(), files)
+		if err != nil {
+			t.Fatalf("ошибка при поиске: %v", err)
+		}
+		if len(results) != 7 {
+			t.Errorf("без --follow ссылки не должны обходиться, получено %v", results)
+		}
+
+		options := DefaultFindOptions()
+		options.Follow = true
+		results, err = searcher.Find(tempDir, options, files)
+		if err != nil {
+			t.Fatalf("ошибка при поиске: %v", err)
+		}
+		if !strings.Contains(relative(results), "link2/file5.txt") {
+			t.Errorf("с --follow должно обходиться содержимое ссылки: %v", results)
+		}
+		if strings.Contains(relative(results), "loop/") {
+			t.Errorf("циклическая ссылка не должна обходиться повторно: %v", results)
+		}
+	})
+
+	t.Run("Взаимные ссылки", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("Символические ссылки требуют прав администратора на Windows")
+		}
+		root := t.TempDir()
+		for _, dir := range []string{"a", "b"} {
+			if err := os.Mkdir(filepath.Join(root, dir), 0755); err != nil {
+				t.Fatalf("не удалось создать директорию: %v", err)
+			}
+		}
+		if err := os.Symlink("../b", filepath.Join(root, "a", "lb")); err != nil {
+			t.Fatalf("не удалось создать ссылку: %v", err)
+		}
+		if err := os.Symlink("../a", filepath.Join(root, "b", "la")); err != nil {
+			t.Fatalf("не удалось создать ссылку: %v", err)
+		}
+		options := DefaultFindOptions()
+		options.Follow = true
+		results, err := searcher.Find(root, options, all)
+		if err != nil {
+			t.Fatalf("ошибка при поиске: %v", err)
+		}
+		// a/lb ведет в b, а b/la внутри нее — обратно в a, которая уже на пути обхода
+		var names []string
+		for _, r := range results {
+			rel, _ := filepath.Rel(root, r)
+			names = append(names, filepath.ToSlash(rel))
+		}
+		if got := strings.Join(names, ","); got != "a,a/lb,a/lb/la,b,b/la,b/la/lb" {
+			t.Errorf("получено %s", got)
+		}
+	})
+
+	t.Run("Недоступная цель ссылки", func(t *testing.T) {
+		if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+			t.Skip("права доступа не ограничивают администратора")
+		}
+		root := t.TempDir()
+		locked := filepath.Join(t.TempDir(), "locked")
+		if err := os.Mkdir(locked, 0755); err != nil {
+			t.Fatalf("не удалось создать директорию: %v", err)
+		}
+		if err := os.Symlink(locked, filepath.Join(root, "link")); err != nil {
+			t.Fatalf("не удалось создать ссылку: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(root, "file.txt"), nil, 0644); err != nil {
+			t.Fatalf("не удалось создать файл: %v", err)
+		}
+		if err := os.Chmod(locked, 0); err != nil {
+			t.Fatalf("не удалось изменить права: %v", err)
+		}
+		defer func() { _ = os.Chmod(locked, 0755) }()
+
+		options := DefaultFindOptions()
+		options.Follow = true
+		results, err := searcher.Find(root, options, all)
+		if err != nil {
+			t.Fatalf("недоступная директория должна пропускаться: %v", err)
+		}
+		if len(results) != 2 {
+			t.Errorf("ожидались file.txt и link, получено %v", results)
+		}
+	})
+}
+
+func TestRespectIgnore(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	// Глобальный ignore-файл берется из временного домашнего каталога
+	t.Setenv("HOME", tempDir)
+	if err := os.WriteFile(filepath.Join(tempDir, ".gitignore"), []byte("subdir/\n*.log\n"), 0644); err != nil {
+		t.Fatalf("не удалось создать .gitignore: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "subdir2", ".fmignore"), []byte("*.bin\n"), 0644); err != nil {
+		t.Fatalf("не удалось создать .fmignore: %v", err)
+	}
+
+	searcher := NewSearcher().WithIgnore(true)
+	t.Run("SearchByName", func(t *testing.T) {
+		results, err := searcher.SearchByName(tempDir, "file*")
+		if err != nil {
+			t.Fatalf("ошибка при поиске: %v", err)
+		}
+		if len(results) != 3 {
+			t.Errorf("ожидалось 3 файла (file1.txt, file5.txt, file7_special.txt), получено %v", results)
+		}
+	})
+
+	t.Run("SearchByContent", func(t *testing.T) {
+		results, err := searcher.SearchByContent(tempDir, "для поиска")
+		if err != nil {
+			t.Fatalf("ошибка при поиске: %v", err)
+		}
+		if len(results) != 1 || filepath.Base(results[0]) != "file1.txt" {
+			t.Errorf("ожидался только file1.txt, получено %v", results)
+		}
+	})
+
+	t.Run("Find", func(t *testing.T) {
+		results, err := searcher.Find(tempDir, DefaultFindOptions(), func(string, fs.DirEntry) bool { return true })
+		if err != nil {
+			t.Fatalf("ошибка при поиске: %v", err)
+		}
+		for _, r := range results {
+			if strings.Contains(r, "file3") || strings.HasSuffix(r, ".bin") || strings.HasSuffix(r, ".log") {
+				t.Errorf("исключенная запись %s не должна находиться", r)
+			}
+		}
+	})
+
+	t.Run("Без учета ignore-файлов", func(t *testing.T) {
+		results, err := NewSearcher().SearchByName(tempDir, "*.log")
+		if err != nil {
+			t.Fatalf("ошибка при поиске: %v", err)
+		}
+		if len(results) != 1 {
+			t.Errorf("без RespectIgnore должен находиться file2.log, получено %v", results)
+		}
+	})
+}
@@ -0,0 +1,69 @@
+// Package sysinfo предоставляет платформенно-зависимые сведения о файлах:
+// владельца, группу, число жестких ссылок и устройство.
+package sysinfo
+
+import (
+	"file-manager/internal/i18n"
+	"fmt"
+	"os/user"
+	"strconv"
+)
+
+// FileStat содержит сведения о файле, которых нет в fs.FileInfo
+type FileStat struct {
+	UID   uint32
+	GID   uint32
+	Nlink uint64
+	Dev   uint64
+	Ino   uint64
+	// Blocks — число занятых блоков по 512 байт
+	Blocks int64
+}
+
+// LookupUser возвращает идентификатор пользователя по имени или числовому идентификатору
+func LookupUser(nameOrID string) (uint32, error) {
+	if id, err := strconv.ParseUint(nameOrID, 10, 32); err == nil {
+		return uint32(id), nil
+	}
+	u, err := user.Lookup(nameOrID)
+	if err != nil {
+		return 0, fmt.Errorf(i18n.T("sysinfo_unknown_user"), nameOrID)
+	}
+	id, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf(i18n.T("sysinfo_unknown_user"), nameOrID)
+	}
+	return uint32(id), nil
+}
+
+// LookupGroup возвращает идентификатор группы по имени или числовому идентификатору
+func LookupGroup(nameOrID string) (uint32, error) {
+	if id, err := strconv.ParseUint(nameOrID, 10, 32); err == nil {
+		return uint32(id), nil
+	}
+	g, err := user.LookupGroup(nameOrID)
+	if err != nil {
+		return 0, fmt.Errorf(i18n.T("sysinfo_unknown_group"), nameOrID)
+	}
+	id, err := strconv.ParseUint(g.Gid, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf(i18n.T("sysinfo_unknown_group"), nameOrID)
+	}
+	return uint32(id), nil
+}
+
+// LookupPrimaryGroup возвращает идентификатор основной группы пользователя по имени или числовому идентификатору
+func LookupPrimaryGroup(nameOrID string) (uint32, error) {
+	u, err := user.Lookup(nameOrID)
+	if err != nil {
+		u, err = user.LookupId(nameOrID)
+	}
+	if err != nil {
+		return 0, fmt.Errorf(i18n.T("sysinfo_unknown_user"), nameOrID)
+	}
+	id, err := strconv.ParseUint(u.Gid, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf(i18n.T("sysinfo_unknown_user"), nameOrID)
+	}
+	return uint32(id), nil
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package sysinfo
+
+import "io/fs"
+
+// Supported сообщает, доступны ли сведения о владельце и ссылках на этой платформе
+const Supported = false
+
+// Stat на этой платформе сведений не предоставляет
+func Stat(_ fs.FileInfo) (FileStat, bool) {
+	return FileStat{}, false
+}
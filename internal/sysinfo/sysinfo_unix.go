@@ -0,0 +1,27 @@
+//go:build unix
+
+package sysinfo
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// Supported сообщает, доступны ли сведения о владельце и ссылках на этой платформе
+const Supported = true
+
+// Stat извлекает сведения о владельце, ссылках и устройстве из fs.FileInfo
+func Stat(info fs.FileInfo) (FileStat, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return FileStat{}, false
+	}
+	return FileStat{
+		UID:    st.Uid,
+		GID:    st.Gid,
+		Nlink:  uint64(st.Nlink),
+		Dev:    uint64(st.Dev),
+		Ino:    uint64(st.Ino),
+		Blocks: int64(st.Blocks),
+	}, true
+}